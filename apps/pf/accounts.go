@@ -0,0 +1,265 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	domain "personal-finance/pkgs/domains"
+	"strconv"
+
+	"github.com/urfave/cli/v3"
+)
+
+func NewAccountsCommand(app *App) *cli.Command {
+	return &cli.Command{
+		Name:  "accounts",
+		Usage: "manages the chart of accounts",
+		Commands: []*cli.Command{
+			{
+				Name:   "list",
+				Usage:  "lists every account",
+				Action: app.listAccounts,
+			},
+			{
+				Name:  "add",
+				Usage: "adds an account",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "name", Usage: "`NAME` of the account, without its parent's path (e.g. Coffee)", Required: true},
+					&cli.StringFlag{Name: "parent", Usage: "`ACCOUNT` to nest the account under - an id or path (e.g. Expense:DiningOut)"},
+					&cli.StringFlag{Name: "type", Usage: "account `TYPE` - one of: asset, liability, income, expense, equity (defaults to the parent's)"},
+					&cli.StringFlag{Name: "description", Usage: "what the account is for"},
+				},
+				Action: app.addAccount,
+			},
+			{
+				Name:      "update",
+				Usage:     "renames, re-describes or moves an account",
+				ArgsUsage: "ACCOUNT",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "name", Usage: "new `NAME` of the account"},
+					&cli.StringFlag{Name: "parent", Usage: "`ACCOUNT` to move the account under - an id or path, or \"\" to make it top-level"},
+					&cli.StringFlag{Name: "description", Usage: "new description of the account"},
+				},
+				Action: app.updateAccount,
+			},
+			{
+				Name:      "delete",
+				Usage:     "deletes an account that has no postings or sub-accounts",
+				ArgsUsage: "ACCOUNT",
+				Action:    app.deleteAccount,
+			},
+		},
+	}
+}
+
+// An account as shown to the user.
+type AccountView struct {
+	ID          int64              `json:"id"`
+	Path        string             `json:"path"`
+	Type        domain.AccountType `json:"type"`
+	Description string             `json:"description"`
+}
+
+func (app *App) listAccounts(ctx context.Context, c *cli.Command) error {
+	app.slogger.InfoContext(ctx, "running accounts list command")
+
+	repo, err := app.Repository(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	accounts, err := repo.ListAccounts(ctx)
+	if err != nil {
+		return fmt.Errorf("error listing accounts: %+v", err)
+	}
+
+	views := make([]AccountView, 0, len(accounts))
+	for _, account := range accounts {
+		path, err := domain.AccountPath(accounts, account.ID)
+		if err != nil {
+			return err
+		}
+
+		views = append(views, AccountView{ID: account.ID, Path: path, Type: account.Type, Description: account.Description})
+	}
+
+	return app.render(c, views, func(w io.Writer) error {
+		rows := make([][]string, 0, len(views))
+		for _, view := range views {
+			rows = append(rows, []string{strconv.FormatInt(view.ID, 10), view.Path, string(view.Type), view.Description})
+		}
+
+		return writeTable(w, []string{"ID", "ACCOUNT", "TYPE", "DESCRIPTION"}, rows)
+	})
+}
+
+func (app *App) addAccount(ctx context.Context, c *cli.Command) error {
+	app.slogger.InfoContext(ctx,
+		"running accounts add command",
+		slog.String("args.name", c.String("name")),
+		slog.String("args.parent", c.String("parent")),
+		slog.String("args.type", c.String("type")),
+	)
+
+	repo, err := app.Repository(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	param := domain.CreateAccountParams{
+		Name:        c.String("name"),
+		Description: c.String("description"),
+	}
+
+	if c.String("type") != "" {
+		if param.Type, err = domain.ParseAccountType(c.String("type")); err != nil {
+			return err
+		}
+	}
+
+	if c.String("parent") != "" {
+		parent, err := findAccount(ctx, repo, c.String("parent"))
+		if err != nil {
+			return err
+		}
+
+		param.ParentID = parent.ID
+	}
+
+	accountID, err := repo.CreateAccount(ctx, param)
+	if err != nil {
+		return fmt.Errorf("error creating account: %w", err)
+	}
+
+	return app.renderAccount(ctx, c, repo, accountID, "added")
+}
+
+func (app *App) updateAccount(ctx context.Context, c *cli.Command) error {
+	app.slogger.InfoContext(ctx,
+		"running accounts update command",
+		slog.String("args.account", c.Args().First()),
+		slog.String("args.name", c.String("name")),
+		slog.String("args.parent", c.String("parent")),
+	)
+
+	repo, err := app.Repository(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	account, err := findAccount(ctx, repo, c.Args().First())
+	if err != nil {
+		return err
+	}
+
+	// only change what was asked for
+	param := domain.UpdateAccountParams{
+		ID:          account.ID,
+		Name:        account.Name,
+		Description: account.Description,
+		ParentID:    account.ParentID,
+	}
+	if c.IsSet("name") {
+		param.Name = c.String("name")
+	}
+	if c.IsSet("description") {
+		param.Description = c.String("description")
+	}
+	if c.IsSet("parent") {
+		param.ParentID = 0
+		if c.String("parent") != "" {
+			parent, err := findAccount(ctx, repo, c.String("parent"))
+			if err != nil {
+				return err
+			}
+
+			param.ParentID = parent.ID
+		}
+	}
+
+	if err := repo.UpdateAccount(ctx, param); err != nil {
+		return fmt.Errorf("error updating account: %w", err)
+	}
+
+	return app.renderAccount(ctx, c, repo, account.ID, "updated")
+}
+
+func (app *App) deleteAccount(ctx context.Context, c *cli.Command) error {
+	app.slogger.InfoContext(ctx, "running accounts delete command", slog.String("args.account", c.Args().First()))
+
+	repo, err := app.Repository(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	account, err := findAccount(ctx, repo, c.Args().First())
+	if err != nil {
+		return err
+	}
+
+	path, err := accountPath(ctx, repo, account.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := repo.DeleteAccount(ctx, account.ID); err != nil {
+		return fmt.Errorf("error deleting account: %w", err)
+	}
+
+	view := AccountView{ID: account.ID, Path: path, Type: account.Type, Description: account.Description}
+	return app.render(c, view, func(w io.Writer) error {
+		_, err := fmt.Fprintf(w, "deleted account %d %s\n", view.ID, view.Path)
+		return err
+	})
+}
+
+func (app *App) renderAccount(ctx context.Context, c *cli.Command, repo domain.AccountingRepository, accountID int64, verb string) error {
+	account, err := repo.GetAccount(ctx, accountID)
+	if err != nil {
+		return fmt.Errorf("error getting account %d: %w", accountID, err)
+	}
+
+	path, err := accountPath(ctx, repo, accountID)
+	if err != nil {
+		return err
+	}
+
+	view := AccountView{ID: account.ID, Path: path, Type: account.Type, Description: account.Description}
+	return app.render(c, view, func(w io.Writer) error {
+		_, err := fmt.Fprintf(w, "%s account %d %s\n", verb, view.ID, view.Path)
+		return err
+	})
+}
+
+// Finds an account by its id (e.g. 4003) or path (e.g. Expense:DiningOut).
+func findAccount(ctx context.Context, repo domain.ChartOfAccountsRepository, ref string) (domain.LedgerAccount, error) {
+	if ref == "" {
+		return domain.LedgerAccount{}, fmt.Errorf("%w: no account given", domain.ErrAccountNotFound)
+	}
+
+	if accountID, err := strconv.ParseInt(ref, 10, 64); err == nil {
+		account, err := repo.GetAccount(ctx, accountID)
+		if err != nil {
+			return domain.LedgerAccount{}, fmt.Errorf("error getting account %d: %w", accountID, err)
+		}
+
+		return account, nil
+	}
+
+	accounts, err := repo.ListAccounts(ctx)
+	if err != nil {
+		return domain.LedgerAccount{}, fmt.Errorf("error listing accounts: %+v", err)
+	}
+
+	return domain.FindAccountByPath(accounts, ref)
+}
+
+func accountPath(ctx context.Context, repo domain.ChartOfAccountsRepository, accountID int64) (string, error) {
+	accounts, err := repo.ListAccounts(ctx)
+	if err != nil {
+		return "", fmt.Errorf("error listing accounts: %+v", err)
+	}
+
+	return domain.AccountPath(accounts, accountID)
+}
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	domain "personal-finance/pkgs/domains"
+	"personal-finance/pkgs/period"
+	"personal-finance/pkgs/report"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+)
+
+func NewCounterpartiesCommand(app *App) *cli.Command {
+	return &cli.Command{
+		Name:  "counterparties",
+		Usage: "the people and businesses you pay and get paid by, as named on bank statements (e.g. PayNow and FAST transfers)",
+		Commands: []*cli.Command{
+			{
+				Name:   "list",
+				Usage:  "lists every counterparty in the ledger, with their alias and default account",
+				Action: app.listCounterparties,
+			},
+			{
+				Name:      "set",
+				Usage:     "gives a counterparty an alias, or a default account that their transactions are booked to when imported",
+				ArgsUsage: "NAME",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "alias", Usage: "what to call the counterparty, e.g. Landlord - \"\" to go back to the bank's name"},
+					&cli.StringFlag{Name: "account", Usage: "default `ACCOUNT` - an id or path (e.g. Expense:Housing), or \"\" for none"},
+				},
+				Action: app.setCounterparty,
+			},
+			{
+				Name:  "report",
+				Usage: "shows the money paid to and received from each counterparty per month",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "month",
+						Aliases: []string{"m"},
+						Usage:   fmt.Sprintf("report on a single month in `yyyy-mm` (e.g. %s)", GetLastMonthYYYYMM(DefaultNower)),
+					},
+					&cli.StringFlag{
+						Name:  "from",
+						Usage: "first month to report on in `yyyy-mm` (inclusive) - can't be combined with --month",
+					},
+					&cli.StringFlag{
+						Name:  "to",
+						Usage: "last month to report on in `yyyy-mm` (inclusive) - can't be combined with --month",
+					},
+					&cli.BoolFlag{
+						Name:  "include-pending",
+						Usage: "include card transactions that haven't settled yet",
+					},
+				},
+				Action: app.counterpartyReport,
+			},
+		},
+	}
+}
+
+// A counterparty as shown to the user.
+type CounterpartyView struct {
+	Name    string `json:"name"` // as the bank writes it
+	Alias   string `json:"alias"`
+	Account string `json:"account"` // default account's full path - blank if there's none
+	Entries int    `json:"entries"` // journal entries with them
+}
+
+func (app *App) listCounterparties(ctx context.Context, c *cli.Command) error {
+	app.slogger.InfoContext(ctx, "running counterparties list command")
+
+	repo, err := app.Repository(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	counterparties, err := repo.ListCounterparties(ctx)
+	if err != nil {
+		return fmt.Errorf("error listing counterparties: %+v", err)
+	}
+
+	entries, err := repo.ListJournalEntries(ctx)
+	if err != nil {
+		return fmt.Errorf("error listing journal entries: %+v", err)
+	}
+
+	accounts, err := repo.ListAccounts(ctx)
+	if err != nil {
+		return fmt.Errorf("error listing accounts: %+v", err)
+	}
+
+	// key: lower cased name - the bank doesn't always use the same case
+	views := map[string]*CounterpartyView{}
+	for _, counterparty := range counterparties {
+		view := &CounterpartyView{Name: counterparty.Name, Alias: counterparty.Alias}
+		if counterparty.AccountID != 0 {
+			if view.Account, err = domain.AccountPath(accounts, counterparty.AccountID); err != nil {
+				return err
+			}
+		}
+
+		views[strings.ToLower(counterparty.Name)] = view
+	}
+
+	for _, entry := range entries {
+		if entry.Counterparty == "" || entry.Reverses != 0 {
+			continue
+		}
+
+		view, ok := views[strings.ToLower(entry.Counterparty)]
+		if !ok {
+			view = &CounterpartyView{Name: entry.Counterparty}
+			views[strings.ToLower(entry.Counterparty)] = view
+		}
+
+		view.Entries++
+	}
+
+	list := make([]CounterpartyView, 0, len(views))
+	for _, view := range views {
+		list = append(list, *view)
+	}
+	slices.SortFunc(list, func(a, b CounterpartyView) int { return cmp.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name)) })
+
+	return app.render(c, list, func(w io.Writer) error {
+		rows := make([][]string, 0, len(list))
+		for _, v := range list {
+			rows = append(rows, []string{v.Name, v.Alias, v.Account, strconv.Itoa(v.Entries)})
+		}
+
+		return writeTable(w, []string{"NAME", "ALIAS", "ACCOUNT", "ENTRIES"}, rows)
+	})
+}
+
+func (app *App) setCounterparty(ctx context.Context, c *cli.Command) error {
+	name := strings.Join(c.Args().Slice(), " ")
+	app.slogger.InfoContext(ctx,
+		"running counterparties set command",
+		slog.String("args.name", name),
+		slog.String("args.alias", c.String("alias")),
+		slog.String("args.account", c.String("account")),
+	)
+
+	if name == "" {
+		return fmt.Errorf("expected the counterparty's NAME, as listed by `pf counterparties list`")
+	}
+
+	repo, err := app.Repository(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	counterparties, err := repo.ListCounterparties(ctx)
+	if err != nil {
+		return fmt.Errorf("error listing counterparties: %+v", err)
+	}
+
+	// only change what was asked for
+	existing, _ := domain.FindCounterparty(counterparties, name)
+	param := domain.SaveCounterpartyParams{Name: name, Alias: existing.Alias, AccountID: existing.AccountID}
+	if existing.Name != "" {
+		param.Name = existing.Name
+	}
+	if c.IsSet("alias") {
+		param.Alias = c.String("alias")
+	}
+	if c.IsSet("account") {
+		param.AccountID = 0
+		if c.String("account") != "" {
+			account, err := findAccount(ctx, repo, c.String("account"))
+			if err != nil {
+				return err
+			}
+
+			param.AccountID = account.ID
+		}
+	}
+
+	if _, err := repo.SaveCounterparty(ctx, param); err != nil {
+		return fmt.Errorf("error saving counterparty '%s': %w", name, err)
+	}
+
+	view := CounterpartyView{Name: param.Name, Alias: param.Alias}
+	if param.AccountID != 0 {
+		if view.Account, err = accountPath(ctx, repo, param.AccountID); err != nil {
+			return err
+		}
+	}
+
+	return app.render(c, view, func(w io.Writer) error {
+		_, err := fmt.Fprintf(w, "saved counterparty %s (alias: '%s', default account: '%s')\n", view.Name, view.Alias, view.Account)
+		return err
+	})
+}
+
+func (app *App) counterpartyReport(ctx context.Context, c *cli.Command) error {
+	app.slogger.InfoContext(ctx,
+		"running counterparties report command",
+		slog.String("args.month", c.String("month")),
+		slog.String("args.from", c.String("from")),
+		slog.String("args.to", c.String("to")),
+		slog.Bool("args.include-pending", c.Bool("include-pending")),
+	)
+
+	dateRange, err := period.NewRange(c.String("month"), c.String("from"), c.String("to"))
+	if err != nil {
+		return fmt.Errorf("error parsing month filter: %+v", err)
+	}
+
+	repo, err := app.Repository(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	entries, err := repo.ListJournalEntries(ctx)
+	if err != nil {
+		return fmt.Errorf("error listing journal entries: %+v", err)
+	}
+
+	if !c.Bool("include-pending") {
+		entries = domain.SettledEntries(entries)
+	}
+
+	imports, err := repo.ListImports(ctx)
+	if err != nil {
+		return fmt.Errorf("error listing imports: %+v", err)
+	}
+
+	counterparties, err := repo.ListCounterparties(ctx)
+	if err != nil {
+		return fmt.Errorf("error listing counterparties: %+v", err)
+	}
+
+	flows, err := report.NewCounterpartyReport(entries, imports, counterparties, dateRange)
+	if err != nil {
+		return fmt.Errorf("error building report: %w", err)
+	}
+
+	return app.render(c, flows, func(w io.Writer) error {
+		if _, err := fmt.Fprintf(w, "counterparties for %s\n\n", flows.Period); err != nil {
+			return err
+		}
+
+		rows := make([][]string, 0, len(flows.Flows))
+		for _, flow := range flows.Flows {
+			rows = append(rows, []string{flow.Month, flow.Counterparty, strconv.Itoa(flow.Transactions), flow.In.Number(), flow.Out.Number(), flow.Net.Number()})
+		}
+
+		return writeTable(w, []string{"MONTH", "COUNTERPARTY", "TRANSACTIONS", "IN", "OUT", "NET"}, rows)
+	})
+}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	domain "personal-finance/pkgs/domains"
+	"personal-finance/pkgs/ingest"
+	"personal-finance/pkgs/money"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v3"
+)
+
+func NewImportsCommand(app *App) *cli.Command {
+	return &cli.Command{
+		Name:  "imports",
+		Usage: "inspects and undoes the batches of transactions imported by `pf ingest`",
+		Commands: []*cli.Command{
+			{
+				Name:   "list",
+				Usage:  "lists every import",
+				Action: app.listImports,
+			},
+			{
+				Name:      "show",
+				Usage:     "shows an import and the journal entries it created, with the statement rows they came from",
+				ArgsUsage: "IMPORT_ID",
+				Action:    app.showImport,
+			},
+			{
+				Name:      "revert",
+				Usage:     "undoes an import by posting a reversing journal entry for every entry it created - nothing is deleted",
+				ArgsUsage: "IMPORT_ID",
+				Action:    app.revertImport,
+			},
+		},
+	}
+}
+
+// An import as shown to the user.
+type ImportView struct {
+	ID         int64     `json:"id"`
+	Parser     string    `json:"parser"`
+	Account    string    `json:"account"` // full path, e.g. "Asset:BankAccount"
+	AccountRef string    `json:"account_ref"`
+	FileSHA256 string    `json:"file_sha256"`
+	ImportedAt time.Time `json:"imported_at"`
+	Entries    int       `json:"entries"`  // journal entries the import created
+	Reversed   int       `json:"reversed"` // entries since reversed by `pf imports revert`
+}
+
+type ImportDetailView struct {
+	ImportView
+	JournalEntries []ImportedEntryView `json:"journal_entries"`
+}
+
+// A journal entry created by an import.
+type ImportedEntryView struct {
+	ID     int64        `json:"id"`
+	Row    int          `json:"row"` // csv record of the statement file
+	Date   time.Time    `json:"date"`
+	Name   string       `json:"name"`
+	Amount money.Amount `json:"amount"` // change to the balance of the import's account
+	Tags   []string     `json:"tags"`
+	RawRow string       `json:"raw_row"`
+
+	Pending    bool  `json:"pending"`               // not settled yet (see `pf report --include-pending`)
+	ReversedBy int64 `json:"reversed_by,omitempty"` // ID of the entry that reversed this one
+}
+
+func (app *App) listImports(ctx context.Context, c *cli.Command) error {
+	app.slogger.InfoContext(ctx, "running imports list command")
+
+	repo, err := app.Repository(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	imports, err := repo.ListImports(ctx)
+	if err != nil {
+		return fmt.Errorf("error listing imports: %+v", err)
+	}
+
+	accounts, err := repo.ListAccounts(ctx)
+	if err != nil {
+		return fmt.Errorf("error listing accounts: %+v", err)
+	}
+
+	entries, err := repo.ListJournalEntries(ctx)
+	if err != nil {
+		return fmt.Errorf("error listing journal entries: %+v", err)
+	}
+
+	// key: import id
+	counts, reversedCounts := map[int64]int{}, map[int64]int{}
+	reversals := domain.Reversals(entries)
+	for _, entry := range entries {
+		counts[entry.Provenance.ImportID]++
+		if _, ok := reversals[entry.ID]; ok {
+			reversedCounts[entry.Provenance.ImportID]++
+		}
+	}
+
+	views := make([]ImportView, 0, len(imports))
+	for _, imp := range imports {
+		view, err := newImportView(imp, accounts, counts[imp.ID], reversedCounts[imp.ID])
+		if err != nil {
+			return err
+		}
+
+		views = append(views, view)
+	}
+
+	return app.render(c, views, func(w io.Writer) error {
+		rows := make([][]string, 0, len(views))
+		for _, v := range views {
+			rows = append(rows, []string{
+				strconv.FormatInt(v.ID, 10), v.Parser, v.Account, v.AccountRef, v.ImportedAt.Local().Format(time.DateTime),
+				strconv.Itoa(v.Entries), strconv.Itoa(v.Reversed), shortHash(v.FileSHA256),
+			})
+		}
+
+		return writeTable(w, []string{"ID", "PARSER", "ACCOUNT", "REF", "IMPORTED AT", "ENTRIES", "REVERSED", "FILE SHA256"}, rows)
+	})
+}
+
+func (app *App) showImport(ctx context.Context, c *cli.Command) error {
+	app.slogger.InfoContext(ctx, "running imports show command", slog.String("args.import", c.Args().First()))
+
+	importID, err := importIDArg(c)
+	if err != nil {
+		return err
+	}
+
+	repo, err := app.Repository(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	imp, err := repo.GetImport(ctx, importID)
+	if err != nil {
+		return fmt.Errorf("error getting import %d: %w", importID, err)
+	}
+
+	account, err := repo.GetAccount(ctx, imp.AccountID)
+	if err != nil {
+		return fmt.Errorf("error getting account %d: %w", imp.AccountID, err)
+	}
+
+	accounts, err := repo.ListAccounts(ctx)
+	if err != nil {
+		return fmt.Errorf("error listing accounts: %+v", err)
+	}
+
+	entries, err := repo.ListJournalEntries(ctx)
+	if err != nil {
+		return fmt.Errorf("error listing journal entries: %+v", err)
+	}
+
+	reversals := domain.Reversals(entries)
+	detail := ImportDetailView{JournalEntries: []ImportedEntryView{}}
+	reversed := 0
+	for _, entry := range entries {
+		if entry.Provenance.ImportID != imp.ID {
+			continue
+		}
+
+		view := ImportedEntryView{ID: entry.ID, Row: entry.Provenance.Row, Date: entry.Date, Name: entry.Name, Tags: append([]string{}, entry.Tags...), RawRow: entry.Provenance.RawRow, Pending: entry.Pending, ReversedBy: reversals[entry.ID]}
+		for _, posting := range entry.Postings {
+			if posting.AccountID != account.ID {
+				continue
+			}
+
+			change, err := account.NormalBalanceChange(posting)
+			if err != nil {
+				return err
+			}
+
+			if view.Amount, err = view.Amount.Add(change); err != nil {
+				return fmt.Errorf("error summing postings of journal entry %d: %w", entry.ID, err)
+			}
+		}
+
+		detail.JournalEntries = append(detail.JournalEntries, view)
+		if view.ReversedBy != 0 {
+			reversed++
+		}
+	}
+
+	if detail.ImportView, err = newImportView(imp, accounts, len(detail.JournalEntries), reversed); err != nil {
+		return err
+	}
+
+	return app.render(c, detail, func(w io.Writer) error {
+		_, err := fmt.Fprintf(w, "import #%d: %d entries (%d reversed) in %s (%s) from %s, imported %s - file sha256 %s\n\n",
+			detail.ID, detail.Entries, detail.Reversed, detail.Account, detail.AccountRef, detail.Parser, detail.ImportedAt.Local().Format(time.DateTime), detail.FileSHA256)
+		if err != nil {
+			return err
+		}
+
+		rows := make([][]string, 0, len(detail.JournalEntries))
+		for _, v := range detail.JournalEntries {
+			reversedBy := ""
+			if v.ReversedBy != 0 {
+				reversedBy = strconv.FormatInt(v.ReversedBy, 10)
+			}
+
+			status := "settled"
+			if v.Pending {
+				status = "pending"
+			}
+
+			rows = append(rows, []string{strconv.FormatInt(v.ID, 10), strconv.Itoa(v.Row), v.Date.Format(time.DateOnly), v.Name, v.Amount.Number(), strings.Join(v.Tags, ","), status, reversedBy, v.RawRow})
+		}
+
+		return writeTable(w, []string{"ENTRY", "ROW", "DATE", "DESCRIPTION", "AMOUNT", "TAGS", "STATUS", "REVERSED BY", "RAW ROW"}, rows)
+	})
+}
+
+func (app *App) revertImport(ctx context.Context, c *cli.Command) error {
+	app.slogger.InfoContext(ctx, "running imports revert command", slog.String("args.import", c.Args().First()))
+
+	importID, err := importIDArg(c)
+	if err != nil {
+		return err
+	}
+
+	repo, err := app.Repository(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	summary, err := ingest.Revert(ctx, repo, importID)
+	if err != nil {
+		return fmt.Errorf("error reverting import %d: %w", importID, err)
+	}
+
+	return app.render(c, summary, func(w io.Writer) error {
+		_, err := fmt.Fprintf(w, "import #%d: reversed %d journal entries (%d already reversed) - %d transfers undone\n", summary.ImportID, summary.Reversed, summary.AlreadyReversed, summary.Transfers)
+		return err
+	})
+}
+
+func importIDArg(c *cli.Command) (int64, error) {
+	importID, err := strconv.ParseInt(c.Args().First(), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("expected an import ID (see `pf imports list`), got '%s'", c.Args().First())
+	}
+
+	return importID, nil
+}
+
+func newImportView(imp domain.Import, accounts []domain.LedgerAccount, entries int, reversed int) (ImportView, error) {
+	path, err := domain.AccountPath(accounts, imp.AccountID)
+	if err != nil {
+		return ImportView{}, err
+	}
+
+	return ImportView{
+		ID:         imp.ID,
+		Parser:     imp.Parser,
+		Account:    path,
+		AccountRef: imp.Metadata.AccountRef,
+		FileSHA256: imp.FileSHA256,
+		ImportedAt: imp.ImportedAt,
+		Entries:    entries,
+		Reversed:   reversed,
+	}, nil
+}
+
+// First 12 characters of a hex hash - enough to tell files apart at a glance.
+func shortHash(hash string) string {
+	return hash[:min(len(hash), 12)]
+}
@@ -0,0 +1,361 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"personal-finance/pkgs/classify"
+	"personal-finance/pkgs/dbs"
+	domain "personal-finance/pkgs/domains"
+	"personal-finance/pkgs/ingest"
+	"personal-finance/pkgs/money"
+	"personal-finance/pkgs/period"
+	"personal-finance/pkgs/reconcile"
+	"personal-finance/pkgs/rules"
+	"strconv"
+	"strings"
+	"time"
+
+	// statement parsers register themselves with pkgs/ingest
+	_ "personal-finance/pkgs/ocbc"
+
+	"github.com/urfave/cli/v3"
+)
+
+func NewIngestCommand(app *App) *cli.Command {
+	return &cli.Command{
+		Name:  "ingest",
+		Usage: "imports a bank statement csv into the ledger - the bank is detected from the file unless given as a subcommand",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:      "file",
+				Aliases:   []string{"f"},
+				Usage:     "path to csv `FILE`, not directory (e.g. path/to/statement.csv)",
+				TakesFile: true,
+				Required:  true,
+			},
+			&cli.StringFlag{
+				Name:    "month",
+				Aliases: []string{"m"},
+				Usage: fmt.Sprintf("month filter in `yyyy-mm` - take only rows that are in the defined month (e.g. %s)",
+					GetLastMonthYYYYMM(DefaultNower),
+				),
+			},
+			&cli.StringFlag{
+				Name:  "from",
+				Usage: "start of month range filter in `yyyy-mm` (inclusive) - can't be combined with --month",
+			},
+			&cli.StringFlag{
+				Name:  "to",
+				Usage: "end of month range filter in `yyyy-mm` (inclusive) - can't be combined with --month",
+			},
+			&cli.StringFlag{
+				Name:  "account",
+				Usage: "`ACCOUNT` the statement is for - an id or path (e.g. Liability:CreditCard), defaults to the bank's usual account",
+			},
+			&cli.StringFlag{
+				Name:  "date-field",
+				Value: ingest.DateField_Transaction,
+				Usage: "date the month filters apply to - `FIELD` is one of: transaction, posting (a.k.a. value)",
+			},
+			&cli.StringFlag{
+				Name:      "merchant-aliases",
+				Usage:     "csv `FILE` of merchant aliases, with a \"pattern,name\" header - transactions are named after the first alias whose pattern (\"*\" matches anything) matches the merchant",
+				TakesFile: true,
+				Sources:   cli.EnvVars("PF_MERCHANT_ALIASES"),
+			},
+			&cli.StringFlag{
+				Name:      "rules",
+				Usage:     "yaml `FILE` of rules that pick the account, tags and name of the transactions they match",
+				TakesFile: true,
+				Sources:   cli.EnvVars("PF_RULES"),
+			},
+			&cli.FloatFlag{
+				Name:    "auto-categorize",
+				Usage:   "book uncategorised transactions to the account `pf suggest` would, when it's at least `CONFIDENT` (between 0 and 1) - 0 to leave them uncategorised",
+				Sources: cli.EnvVars("PF_AUTO_CATEGORIZE"),
+			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "show which rule each transaction matches and how it would be booked, without importing anything",
+			},
+			&cli.BoolFlag{
+				Name:  "review",
+				Usage: "after importing, go through the uncategorised transactions one at a time and pick their accounts - answers can be added to --rules",
+			},
+		},
+		Action: func(ctx context.Context, c *cli.Command) error {
+			return app.ingest(ctx, c, "")
+		},
+		Commands: newIngestParserCommands(app),
+	}
+}
+
+// One subcommand per registered statement parser, e.g. `pf ingest ocbc`.
+func newIngestParserCommands(app *App) []*cli.Command {
+	commands := []*cli.Command{}
+	for _, parser := range ingest.Parsers() {
+		commands = append(commands, &cli.Command{
+			Name:  parser.Name(),
+			Usage: fmt.Sprintf("imports a %s csv", parser.Description()),
+			Action: func(ctx context.Context, c *cli.Command) error {
+				return app.ingest(ctx, c, parser.Name())
+			},
+		})
+	}
+
+	return commands
+}
+
+// Imports --file with the parser named parserName, or whichever parser recognises the file if parserName is empty.
+func (app *App) ingest(ctx context.Context, c *cli.Command, parserName string) error {
+	app.slogger.InfoContext(ctx,
+		"running ingest command",
+		slog.String("parser", parserName),
+		slog.String("args.file", c.String("file")),
+		slog.String("args.month", c.String("month")),
+		slog.String("args.from", c.String("from")),
+		slog.String("args.to", c.String("to")),
+		slog.String("args.date-field", c.String("date-field")),
+		slog.String("args.account", c.String("account")),
+		slog.String("args.merchant-aliases", c.String("merchant-aliases")),
+		slog.String("args.rules", c.String("rules")),
+		slog.Float64("args.auto-categorize", c.Float("auto-categorize")),
+		slog.Bool("args.dry-run", c.Bool("dry-run")),
+		slog.Bool("args.review", c.Bool("review")),
+	)
+
+	if c.Bool("review") && c.Bool("dry-run") {
+		return fmt.Errorf("--review can't be combined with --dry-run")
+	}
+	if c.Bool("review") && app.isJSON(c) {
+		return fmt.Errorf("--review needs a terminal - it can't be combined with --output %s", OutputFormat_JSON)
+	}
+
+	dateRange, err := period.NewRange(c.String("month"), c.String("from"), c.String("to"))
+	if err != nil {
+		return fmt.Errorf("error parsing month filter: %+v", err)
+	}
+
+	dateField, err := ingest.ParseDateField(c.String("date-field"))
+	if err != nil {
+		return err
+	}
+
+	if err := validateConfidence(c.Float("auto-categorize")); err != nil {
+		return err
+	}
+
+	repo, err := app.Repository(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	app.slogger.InfoContext(ctx, "reading file")
+	table, fileSHA256, err := ingest.ReadStatementFile(c.String("file"))
+	if err != nil {
+		return err
+	}
+
+	parser, err := app.statementParser(ctx, c, table, parserName)
+	if err != nil {
+		return err
+	}
+
+	statement, err := parser.Parse(table)
+	if err != nil {
+		return fmt.Errorf("error parsing %s: %w", parser.Description(), err)
+	}
+
+	if c.String("merchant-aliases") != "" {
+		aliases, err := dbs.ReadMerchantAliases(c.String("merchant-aliases"))
+		if err != nil {
+			return err
+		}
+
+		dbs.NewMerchantNormalizer(aliases).Rename(statement.Transactions)
+	}
+
+	var engine *rules.Engine
+	if c.String("rules") != "" {
+		if engine, err = readRules(ctx, repo, c.String("rules")); err != nil {
+			return err
+		}
+	}
+
+	var fallback ingest.Categorizer
+	if c.Float("auto-categorize") > 0 {
+		classifier, _, _, err := trainClassifier(ctx, repo)
+		if err != nil {
+			return err
+		}
+
+		fallback = classify.AutoCategorizer{Classifier: classifier, Threshold: c.Float("auto-categorize")}
+	}
+
+	if c.Bool("dry-run") {
+		txs := ingest.FilterByDate(statement.Transactions, dateRange, dateField)
+		return app.renderDryRun(ctx, c, repo, parser.Name(), txs, engine, fallback)
+	}
+
+	accountID := parser.AccountID()
+	if c.String("account") != "" {
+		account, err := findAccount(ctx, repo, c.String("account"))
+		if err != nil {
+			return err
+		}
+
+		accountID = account.ID
+	}
+
+	accountRef, err := accountPath(ctx, repo, accountID)
+	if err != nil {
+		return err
+	}
+
+	app.slogger.InfoContext(ctx, "processing data...")
+	opts := ingest.Options{
+		Parser:     parser.Name(),
+		FileSHA256: fileSHA256,
+		AccountID:  accountID,
+		DateRange:  dateRange,
+		DateField:  dateField,
+		Transfers:  &ingest.DefaultTransferOptions,
+		Fallback:   fallback,
+	}
+	if engine != nil {
+		opts.Categorizer = engine
+	}
+
+	summary, err := ingest.Import(ctx, app.slogger, repo, statement, opts)
+	if err != nil {
+		return fmt.Errorf("error importing %s: %w", parser.Description(), err)
+	}
+
+	// compare the ledger against the statement's balance, if it has one
+	result := IngestResult{Summary: summary}
+	check, err := reconcile.CheckImport(ctx, repo, summary.ImportID)
+	switch {
+	case errors.Is(err, reconcile.ErrNoStatementBalance):
+		app.slogger.InfoContext(ctx, "skipping balance check", slog.String("reason", err.Error()))
+	case err != nil:
+		return fmt.Errorf("error checking balance of import %d: %w", summary.ImportID, err)
+	default:
+		result.BalanceCheck = &check
+	}
+
+	err = app.render(c, result, func(w io.Writer) error {
+		_, err := fmt.Fprintf(w, "import #%d: imported %d of %d transactions from %s into %s (%d pending, %d settling earlier pending ones, %d already imported, %d revised by the bank since, %d outside of %s) - %d transfers matched\n",
+			summary.ImportID, summary.Imported, summary.Rows, parser.Description(), accountRef, summary.Pending, summary.Settled, summary.Duplicates, summary.Changed, summary.Excluded, dateRange, summary.Transfers)
+		if err != nil || result.BalanceCheck == nil {
+			return err
+		}
+
+		return writeCheck(w, *result.BalanceCheck)
+	})
+	if err != nil || !c.Bool("review") {
+		return err
+	}
+
+	return app.review(ctx, c, repo, summary.ImportID, c.String("rules"))
+}
+
+type IngestResult struct {
+	ingest.Summary
+	BalanceCheck *reconcile.Check `json:"balance_check,omitempty"` // nil if the statement has no balance
+}
+
+func (app *App) statementParser(ctx context.Context, c *cli.Command, table [][]string, parserName string) (ingest.StatementParser, error) {
+	if parserName != "" {
+		return ingest.LookupParser(parserName)
+	}
+
+	parser, err := ingest.DetectParser(table)
+	if err != nil {
+		return nil, fmt.Errorf("error detecting statement format of '%s' - name the bank instead, e.g. `pf ingest <bank> --file ...`: %w", c.String("file"), err)
+	}
+
+	app.slogger.InfoContext(ctx, "detected statement format", slog.String("parser", parser.Name()))
+	return parser, nil
+}
+
+// Reads a rules file and resolves the accounts its rules book to.
+func readRules(ctx context.Context, repo domain.AccountingRepository, path string) (*rules.Engine, error) {
+	file, err := rules.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts, err := repo.ListAccounts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error listing accounts: %+v", err)
+	}
+
+	engine, err := rules.NewEngine(file, accounts)
+	if err != nil {
+		return nil, fmt.Errorf("error in rules '%s': %w", path, err)
+	}
+
+	return &engine, nil
+}
+
+// How a statement row would be booked, as shown by `pf ingest --dry-run`.
+type DryRunView struct {
+	Row         int          `json:"row"`
+	Date        time.Time    `json:"date"`
+	Description string       `json:"description"`
+	Amount      money.Amount `json:"amount"`
+	Rule        string       `json:"rule"`    // blank if no rule matched - "auto-categorize" if the classifier picked the account
+	Account     string       `json:"account"` // full path of the account the rule (or classifier) books to - blank to leave it to the defaults
+	Tags        []string     `json:"tags"`
+	Name        string       `json:"name"` // what the journal entry would be called
+}
+
+func (app *App) renderDryRun(ctx context.Context, c *cli.Command, repo domain.AccountingRepository, source string, txs []ingest.Transaction, engine *rules.Engine, fallback ingest.Categorizer) error {
+	accounts, err := repo.ListAccounts(ctx)
+	if err != nil {
+		return fmt.Errorf("error listing accounts: %+v", err)
+	}
+
+	views := make([]DryRunView, 0, len(txs))
+	for _, t := range txs {
+		view := DryRunView{Row: t.Source.Row, Date: t.Date, Description: t.Description, Amount: t.Amount, Tags: []string{}, Name: t.EntryName()}
+		if engine != nil {
+			if hit, ok := engine.Match(t, source); ok {
+				view.Rule = hit.Rule.Name
+				if hit.Category.AccountID != 0 {
+					if view.Account, err = domain.AccountPath(accounts, hit.Category.AccountID); err != nil {
+						return err
+					}
+				}
+				if hit.Category.Name != "" {
+					view.Name = hit.Category.Name
+				}
+				view.Tags = append(view.Tags, hit.Category.Tags...)
+			}
+		}
+
+		if view.Account == "" && fallback != nil {
+			if category, ok := fallback.Categorize(t, source); ok {
+				view.Rule = cmp.Or(view.Rule, "auto-categorize")
+				if view.Account, err = domain.AccountPath(accounts, category.AccountID); err != nil {
+					return err
+				}
+			}
+		}
+
+		views = append(views, view)
+	}
+
+	return app.render(c, views, func(w io.Writer) error {
+		rows := make([][]string, 0, len(views))
+		for _, v := range views {
+			rows = append(rows, []string{strconv.Itoa(v.Row), v.Date.Format(time.DateOnly), v.Name, v.Amount.Number(), v.Rule, v.Account, strings.Join(v.Tags, ",")})
+		}
+
+		return writeTable(w, []string{"ROW", "DATE", "NAME", "AMOUNT", "RULE", "ACCOUNT", "TAGS"}, rows)
+	})
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	domain "personal-finance/pkgs/domains"
+	"personal-finance/pkgs/sqlite"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v3"
+)
+
+func main() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cmd := NewApp(os.Stdout, os.Stderr)
+	if err := cmd.Run(ctx, os.Args); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %+v\n", err)
+		os.Exit(1)
+	}
+}
+
+// State shared by every subcommand - built from the global flags.
+type App struct {
+	stdout  io.Writer
+	stderr  io.Writer
+	slogger *slog.Logger
+
+	repo      domain.AccountingRepository
+	closeRepo func() error
+}
+
+// Output formats (see --output)
+const (
+	OutputFormat_Text = "text"
+	OutputFormat_JSON = "json"
+)
+
+func NewApp(stdout io.Writer, stderr io.Writer) *cli.Command {
+	app := &App{
+		stdout:  stdout,
+		stderr:  stderr,
+		slogger: slog.New(slog.NewTextHandler(stderr, &slog.HandlerOptions{AddSource: true, Level: slog.LevelInfo})),
+	}
+
+	return &cli.Command{
+		Name:      "pf",
+		Usage:     "personal finance tracker",
+		Writer:    stdout,
+		ErrWriter: stderr,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:      "db",
+				Usage:     "path to sqlite database `FILE` to store the ledger in - the ledger is kept in memory (and discarded) if not set",
+				Sources:   cli.EnvVars("PF_DB"),
+				TakesFile: true,
+			},
+			&cli.StringFlag{
+				Name:  "log-level",
+				Value: "info",
+				Usage: "minimum `LEVEL` of logs written to stderr - one of: debug, info, warn, error",
+			},
+			&cli.StringFlag{
+				Name:    "output",
+				Aliases: []string{"o"},
+				Value:   OutputFormat_Text,
+				Usage:   "`FORMAT` of command output - one of: text, json",
+			},
+		},
+		Before: func(ctx context.Context, c *cli.Command) (context.Context, error) {
+			var level slog.Level
+			if err := level.UnmarshalText([]byte(c.String("log-level"))); err != nil {
+				return ctx, fmt.Errorf("error parsing log level: %+v", err)
+			}
+			app.slogger = slog.New(slog.NewTextHandler(stderr, &slog.HandlerOptions{AddSource: true, Level: level}))
+
+			switch c.String("output") {
+			case OutputFormat_Text, OutputFormat_JSON:
+			default:
+				return ctx, fmt.Errorf("unknown output format '%s' - expected %s or %s", c.String("output"), OutputFormat_Text, OutputFormat_JSON)
+			}
+
+			return ctx, nil
+		},
+		After: func(ctx context.Context, c *cli.Command) error {
+			if app.closeRepo == nil {
+				return nil
+			}
+
+			if err := app.closeRepo(); err != nil {
+				return fmt.Errorf("error closing accounting repository: %+v", err)
+			}
+
+			return nil
+		},
+		Commands: []*cli.Command{
+			NewIngestCommand(app),
+			NewReportCommand(app),
+			NewAccountsCommand(app),
+			NewReconcileCommand(app),
+			NewImportsCommand(app),
+			NewTransfersCommand(app),
+			NewCounterpartiesCommand(app),
+			NewSuggestCommand(app),
+		},
+	}
+}
+
+// Opens the ledger named by --db the first time it's needed.
+func (app *App) Repository(ctx context.Context, c *cli.Command) (domain.AccountingRepository, error) {
+	if app.repo != nil {
+		return app.repo, nil
+	}
+
+	repo, closeRepo, err := OpenAccountingRepository(ctx, c.String("db"))
+	if err != nil {
+		return nil, fmt.Errorf("error opening accounting repository: %+v", err)
+	}
+
+	app.repo, app.closeRepo = repo, closeRepo
+	return repo, nil
+}
+
+// Opens the sqlite ledger at dbPath, or an in-memory one if dbPath is empty.
+// The returned func must be called to release the database.
+func OpenAccountingRepository(ctx context.Context, dbPath string) (domain.AccountingRepository, func() error, error) {
+	if dbPath == "" {
+		return domain.NewInMemoryAccountingRepository(), func() error { return nil }, nil
+	}
+
+	db, err := sqlite.Open(ctx, dbPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	repo, err := sqlite.NewAccountingRepository(ctx, db)
+	if err != nil {
+		_ = db.Close()
+		return nil, nil, err
+	}
+
+	return repo, db.Close, nil
+}
+
+func (app *App) isJSON(c *cli.Command) bool {
+	return strings.EqualFold(c.String("output"), OutputFormat_JSON)
+}
+
+var DefaultNower = TimeNower{}
+
+type TimeNower struct{}
+
+func (n TimeNower) Now() time.Time {
+	return time.Now()
+}
+
+// returns last month as string in format yyyy-mm
+// e.g. if its 2025-12-13, return 2025-11 (M - 1)
+func GetLastMonthYYYYMM(nower Nower) string {
+	now := nower.Now()
+	lastMonthTime := time.Date(now.Year(), now.Month()-1, 1, 0, 0, 0, 0, now.Location())
+
+	return lastMonthTime.Format("2006-01")
+}
+
+type Nower interface {
+	Now() time.Time
+}
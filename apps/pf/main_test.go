@@ -0,0 +1,651 @@
+package main_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	main "personal-finance/apps/pf"
+	domain "personal-finance/pkgs/domains"
+	"personal-finance/pkgs/ingest"
+	"personal-finance/pkgs/money"
+	"personal-finance/pkgs/reconcile"
+	"personal-finance/pkgs/report"
+	"personal-finance/pkgs/rules"
+	"personal-finance/pkgs/sqlite"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Runs pf with args and returns what it wrote to stdout.
+func run(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+
+	return runWithInput(t, "", args...)
+}
+
+// Runs pf with args, typing input into stdin, and returns what it wrote to stdout.
+func runWithInput(t *testing.T, input string, args ...string) (string, error) {
+	t.Helper()
+
+	stdout, stderr := new(strings.Builder), new(strings.Builder)
+	cmd := main.NewApp(stdout, stderr)
+	cmd.Reader = strings.NewReader(input)
+	err := cmd.Run(t.Context(), append([]string{"pf"}, args...))
+
+	return stdout.String(), err
+}
+
+func countJournalEntries(t *testing.T, dbPath string) int {
+	t.Helper()
+
+	ctx := t.Context()
+
+	db, err := sqlite.Open(ctx, dbPath)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	repo, err := sqlite.NewAccountingRepository(ctx, db)
+	require.NoError(t, err)
+
+	entries, err := repo.ListJournalEntries(ctx)
+	require.NoError(t, err)
+
+	return len(entries)
+}
+
+func TestMain(t *testing.T) {
+	t.Parallel()
+
+	_, err := run(t, "ingest", "ocbc", "--file", "../../tests/testdata/ocbc.csv")
+	require.NoError(t, err)
+}
+
+func TestMainWithDB(t *testing.T) {
+	t.Parallel()
+
+	dbPath := filepath.Join(t.TempDir(), "ledger.db")
+
+	_, err := run(t, "--db", dbPath, "ingest", "ocbc", "--file", "../../tests/testdata/ocbc.csv")
+	require.NoError(t, err)
+	require.Equal(t, 8, countJournalEntries(t, dbPath))
+}
+
+func TestMainIngestDBS(t *testing.T) {
+	t.Parallel()
+
+	dbPath := filepath.Join(t.TempDir(), "ledger.db")
+
+	out, err := run(t, "--db", dbPath, "--output", "json", "ingest", "dbs", "--file", "../../tests/testdata/dbs.csv")
+	require.NoError(t, err)
+	require.JSONEq(t, `{"import_id": 1, "rows": 97, "excluded": 0, "imported": 97, "duplicates": 0, "changed": 0, "pending": 0, "settled": 0, "transfers": 0}`, out)
+	require.Equal(t, 97, countJournalEntries(t, dbPath))
+
+	// card purchases, refunds and bill payments are booked against the card, never the bank account
+	ctx := t.Context()
+	db, err := sqlite.Open(ctx, dbPath)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	repo, err := sqlite.NewAccountingRepository(ctx, db)
+	require.NoError(t, err)
+	entries, err := repo.ListJournalEntries(ctx)
+	require.NoError(t, err)
+
+	for _, entry := range entries {
+		accountIDs := []int64{}
+		for _, posting := range entry.Postings {
+			accountIDs = append(accountIDs, posting.AccountID)
+		}
+
+		require.Contains(t, accountIDs, int64(domain.AccountID_Liability_CreditCard), entry.Name)
+		require.NotContains(t, accountIDs, int64(domain.AccountID_Asset_BankAccount), entry.Name)
+	}
+}
+
+func TestMainIngestTwiceSkipsDuplicates(t *testing.T) {
+	t.Parallel()
+
+	dbPath := filepath.Join(t.TempDir(), "ledger.db")
+
+	_, err := run(t, "--db", dbPath, "ingest", "dbs", "--file", "../../tests/testdata/dbs.csv", "--month", "2025-10")
+	require.NoError(t, err)
+	first := countJournalEntries(t, dbPath)
+	require.Positive(t, first)
+
+	// DBS exports are a rolling window, so the next export overlaps the last one
+	out, err := run(t, "--db", dbPath, "--output", "json", "ingest", "dbs", "--file", "../../tests/testdata/dbs.csv")
+	require.NoError(t, err)
+
+	var result main.IngestResult
+	require.NoError(t, json.Unmarshal([]byte(out), &result))
+	require.Equal(t, first, result.Duplicates)
+	require.Equal(t, 97-first, result.Imported)
+	require.Zero(t, result.Changed)
+	require.Equal(t, 97, countJournalEntries(t, dbPath))
+}
+
+func TestMainIngestDetectsFormat(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		file string
+		want int
+	}{
+		"ocbc": {file: "../../tests/testdata/ocbc.csv", want: 8},
+		"dbs":  {file: "../../tests/testdata/dbs.csv", want: 97},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dbPath := filepath.Join(t.TempDir(), "ledger.db")
+
+			_, err := run(t, "--db", dbPath, "ingest", "--file", tt.file)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, countJournalEntries(t, dbPath))
+		})
+	}
+}
+
+func TestMainIngestUnknownFormat(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "TransactionHistory.csv")
+	require.NoError(t, os.WriteFile(path, []byte("Date,Amount\n2025-12-01,1.00\n"), 0o600))
+
+	_, err := run(t, "ingest", "--file", path)
+	require.ErrorIs(t, err, ingest.ErrUnknownFormat)
+}
+
+func TestMainMonthFilter(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		args []string
+		want int
+	}{
+		"month with rows":       {args: []string{"--month", "2025-12"}, want: 8},
+		"month without rows":    {args: []string{"--month", "2025-11"}, want: 0},
+		"range":                 {args: []string{"--from", "2025-10", "--to", "2025-12"}, want: 8},
+		"range by value date":   {args: []string{"--from", "2026-01", "--date-field", "value"}, want: 0},
+		"unbounded range start": {args: []string{"--to", "2025-12"}, want: 8},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			dbPath := filepath.Join(t.TempDir(), "ledger.db")
+			args := append([]string{"--db", dbPath, "ingest", "ocbc", "--file", "../../tests/testdata/ocbc.csv"}, tt.args...)
+
+			_, err := run(t, args...)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, countJournalEntries(t, dbPath))
+		})
+	}
+}
+
+func TestMainMonthFilterError(t *testing.T) {
+	t.Parallel()
+
+	_, err := run(t, "ingest", "ocbc", "--file", "../../tests/testdata/ocbc.csv", "--month", "2025-12", "--from", "2025-10")
+	require.Error(t, err)
+}
+
+func TestMainUnknownOutputFormat(t *testing.T) {
+	t.Parallel()
+
+	_, err := run(t, "--output", "xml", "accounts", "list")
+	require.Error(t, err)
+}
+
+func TestMainReport(t *testing.T) {
+	t.Parallel()
+
+	dbPath := filepath.Join(t.TempDir(), "ledger.db")
+
+	_, err := run(t, "--db", dbPath, "ingest", "ocbc", "--file", "../../tests/testdata/ocbc.csv")
+	require.NoError(t, err)
+
+	out, err := run(t, "--db", dbPath, "--output", "json", "report", "--month", "2025-12")
+	require.NoError(t, err)
+
+	var statement report.IncomeStatement
+	require.NoError(t, json.Unmarshal([]byte(out), &statement))
+	require.Equal(t, "2025-12 to 2025-12", statement.Period)
+	require.NotEmpty(t, statement.Accounts)
+
+	net, err := statement.Income.Sub(statement.Expenses)
+	require.NoError(t, err)
+	require.Equal(t, net, statement.Net)
+
+	out, err = run(t, "--db", dbPath, "--output", "json", "report", "--month", "2025-11")
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal([]byte(out), &statement))
+	require.Empty(t, statement.Accounts)
+	require.Equal(t, money.Amount{}.Micros(), statement.Net.Micros())
+}
+
+func TestMainIngestChecksBalance(t *testing.T) {
+	t.Parallel()
+
+	dbPath := filepath.Join(t.TempDir(), "ledger.db")
+
+	out, err := run(t, "--db", dbPath, "--output", "json", "ingest", "--file", "../../tests/testdata/ocbc.csv")
+	require.NoError(t, err)
+
+	var result main.IngestResult
+	require.NoError(t, json.Unmarshal([]byte(out), &result))
+	require.NotNil(t, result.BalanceCheck)
+	require.Equal(t, money.MustParse("18,477.16", money.SGD), result.BalanceCheck.StatementBalance)
+	require.Equal(t, money.MustParse("6,710.45", money.SGD), result.BalanceCheck.LedgerBalance)
+	require.Len(t, result.BalanceCheck.Unreconciled, 8)
+
+	// without an opening balance the ledger can't agree with the bank
+	_, err = run(t, "--db", dbPath, "reconcile")
+	require.ErrorIs(t, err, reconcile.ErrDiscrepancy)
+
+	out, err = run(t, "--db", dbPath, "reconcile", "list")
+	require.NoError(t, err)
+	require.NotContains(t, out, "Asset:BankAccount")
+}
+
+func TestMainAccounts(t *testing.T) {
+	t.Parallel()
+
+	dbPath := filepath.Join(t.TempDir(), "ledger.db")
+
+	_, err := run(t, "--db", dbPath, "accounts", "add", "--name", "Coffee", "--parent", "Expense:DiningOut")
+	require.NoError(t, err)
+
+	_, err = run(t, "--db", dbPath, "accounts", "update", "Expense:DiningOut:Coffee", "--name", "Kopi")
+	require.NoError(t, err)
+
+	out, err := run(t, "--db", dbPath, "accounts", "list")
+	require.NoError(t, err)
+	require.Contains(t, out, "Expense:DiningOut:Kopi")
+	require.NotContains(t, out, "Expense:DiningOut:Coffee")
+
+	_, err = run(t, "--db", dbPath, "accounts", "delete", "Expense:DiningOut:Kopi")
+	require.NoError(t, err)
+
+	out, err = run(t, "--db", dbPath, "--output", "json", "accounts", "list")
+	require.NoError(t, err)
+
+	var accounts []main.AccountView
+	require.NoError(t, json.Unmarshal([]byte(out), &accounts))
+	for _, account := range accounts {
+		require.NotEqual(t, "Expense:DiningOut:Kopi", account.Path)
+	}
+}
+
+func TestMainImports(t *testing.T) {
+	t.Parallel()
+
+	dbPath := filepath.Join(t.TempDir(), "ledger.db")
+
+	_, err := run(t, "--db", dbPath, "ingest", "ocbc", "--file", "../../tests/testdata/ocbc.csv")
+	require.NoError(t, err)
+
+	out, err := run(t, "--db", dbPath, "--output", "json", "imports", "list")
+	require.NoError(t, err)
+
+	var imports []main.ImportView
+	require.NoError(t, json.Unmarshal([]byte(out), &imports))
+	require.Len(t, imports, 1)
+	require.Equal(t, "ocbc", imports[0].Parser)
+	require.Equal(t, 8, imports[0].Entries)
+	require.Len(t, imports[0].FileSHA256, 64)
+
+	out, err = run(t, "--db", dbPath, "--output", "json", "imports", "show", "1")
+	require.NoError(t, err)
+
+	var detail main.ImportDetailView
+	require.NoError(t, json.Unmarshal([]byte(out), &detail))
+	require.Len(t, detail.JournalEntries, 8)
+
+	// the first transaction is on the line after the header, and its description spans two lines
+	first := detail.JournalEntries[0]
+	require.Equal(t, 7, first.Row)
+	require.Equal(t, money.MustParse("-6,000.00", money.SGD), first.Amount)
+	require.True(t, strings.HasPrefix(first.RawRow, `16/12/2025,16/12/2025,"FAST PAYMENT`))
+
+	_, err = run(t, "--db", dbPath, "imports", "show", "2")
+	require.ErrorIs(t, err, domain.ErrImportNotFound)
+}
+
+func TestMainImportsRevert(t *testing.T) {
+	t.Parallel()
+
+	dbPath := filepath.Join(t.TempDir(), "ledger.db")
+
+	_, err := run(t, "--db", dbPath, "ingest", "ocbc", "--file", "../../tests/testdata/ocbc.csv")
+	require.NoError(t, err)
+
+	out, err := run(t, "--db", dbPath, "--output", "json", "imports", "revert", "1")
+	require.NoError(t, err)
+	require.JSONEq(t, `{"import_id": 1, "reversed": 8, "already_reversed": 0, "transfers": 0}`, out)
+	require.Equal(t, 16, countJournalEntries(t, dbPath))
+
+	out, err = run(t, "--db", dbPath, "--output", "json", "report", "--month", "2025-12")
+	require.NoError(t, err)
+
+	var statement report.IncomeStatement
+	require.NoError(t, json.Unmarshal([]byte(out), &statement))
+	require.True(t, statement.Net.IsZero())
+
+	// the reverted transactions can be imported again
+	out, err = run(t, "--db", dbPath, "--output", "json", "ingest", "ocbc", "--file", "../../tests/testdata/ocbc.csv")
+	require.NoError(t, err)
+
+	var result main.IngestResult
+	require.NoError(t, json.Unmarshal([]byte(out), &result))
+	require.Equal(t, 8, result.Imported)
+	require.Zero(t, result.Duplicates)
+}
+
+func TestMainIngestAccount(t *testing.T) {
+	t.Parallel()
+
+	dbPath := filepath.Join(t.TempDir(), "ledger.db")
+
+	_, err := run(t, "--db", dbPath, "ingest", "--file", "../../tests/testdata/dbs.csv")
+	require.NoError(t, err)
+
+	_, err = run(t, "--db", dbPath, "ingest", "--file", "../../tests/testdata/ocbc.csv", "--account", "Asset:CashOnHand")
+	require.NoError(t, err)
+
+	_, err = run(t, "--db", dbPath, "ingest", "--file", "../../tests/testdata/ocbc.csv", "--account", "Expense:Groceries")
+	require.ErrorIs(t, err, domain.ErrInvalidImport)
+
+	out, err := run(t, "--db", dbPath, "--output", "json", "imports", "list")
+	require.NoError(t, err)
+
+	var imports []main.ImportView
+	require.NoError(t, json.Unmarshal([]byte(out), &imports))
+	require.Len(t, imports, 2)
+	require.Equal(t, "Liability:CreditCard", imports[0].Account)
+	require.Equal(t, "Asset:CashOnHand", imports[1].Account)
+}
+
+func TestMainCounterparties(t *testing.T) {
+	t.Parallel()
+
+	dbPath := filepath.Join(t.TempDir(), "ledger.db")
+
+	_, err := run(t, "--db", dbPath, "ingest", "ocbc", "--file", "../../tests/testdata/ocbc.csv")
+	require.NoError(t, err)
+
+	_, err = run(t, "--db", dbPath, "counterparties", "set", "PERSON_A PAYNOW_ID_001", "--alias", "Landlord", "--account", "Expense:Housing")
+	require.NoError(t, err)
+
+	out, err := run(t, "--db", dbPath, "--output", "json", "counterparties", "list")
+	require.NoError(t, err)
+
+	var counterparties []main.CounterpartyView
+	require.NoError(t, json.Unmarshal([]byte(out), &counterparties))
+	require.Len(t, counterparties, 7)
+	require.Contains(t, counterparties, main.CounterpartyView{Name: "PERSON_A PAYNOW_ID_001", Alias: "Landlord", Account: "Expense:Housing", Entries: 1})
+
+	out, err = run(t, "--db", dbPath, "--output", "json", "counterparties", "report", "--month", "2025-12")
+	require.NoError(t, err)
+
+	var flows report.CounterpartyReport
+	require.NoError(t, json.Unmarshal([]byte(out), &flows))
+	require.Contains(t, flows.Flows, report.CounterpartyFlow{
+		Counterparty: "Landlord",
+		Month:        "2025-12",
+		In:           money.MustParse("0", money.SGD),
+		Out:          money.MustParse("6,000.00", money.SGD),
+		Net:          money.MustParse("-6,000.00", money.SGD),
+		Transactions: 1,
+	})
+}
+
+func TestMainIngestMerchantAliases(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	dbPath, aliasesPath := filepath.Join(dir, "ledger.db"), filepath.Join(dir, "aliases.csv")
+	require.NoError(t, os.WriteFile(aliasesPath, []byte("pattern,name\nTRANSIT_PROVIDER_*,Public Transport\n"), 0o600))
+
+	_, err := run(t, "--db", dbPath, "ingest", "--file", "../../tests/testdata/dbs.csv", "--merchant-aliases", aliasesPath)
+	require.NoError(t, err)
+
+	out, err := run(t, "--db", dbPath, "--output", "json", "imports", "show", "1")
+	require.NoError(t, err)
+
+	var detail main.ImportDetailView
+	require.NoError(t, json.Unmarshal([]byte(out), &detail))
+
+	names := make([]string, 0, len(detail.JournalEntries))
+	for _, entry := range detail.JournalEntries {
+		names = append(names, entry.Name)
+	}
+	require.Contains(t, names, "MERCHANT_A")
+	require.Contains(t, names, "Public Transport")
+	require.NotContains(t, names, "TRANSIT_PROVIDER_A")
+
+	_, err = run(t, "--db", dbPath, "ingest", "--file", "../../tests/testdata/dbs.csv", "--merchant-aliases", filepath.Join(dir, "missing.csv"))
+	require.Error(t, err)
+}
+
+func TestMainIngestRules(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	dbPath, rulesPath := filepath.Join(dir, "ledger.db"), filepath.Join(dir, "rules.yaml")
+	require.NoError(t, os.WriteFile(rulesPath, []byte(`
+rules:
+  - name: groceries
+    match: {description: GROCERY_CHAIN_, direction: out}
+    account: Expense:Groceries
+    tags: [food]
+    rename: Groceries
+`), 0o600))
+
+	out, err := run(t, "--db", dbPath, "--output", "json", "ingest", "--file", "../../tests/testdata/dbs.csv", "--rules", rulesPath, "--dry-run")
+	require.NoError(t, err)
+	require.Zero(t, countJournalEntries(t, dbPath))
+
+	var rows []main.DryRunView
+	require.NoError(t, json.Unmarshal([]byte(out), &rows))
+	hits := 0
+	for _, row := range rows {
+		if row.Rule == "" {
+			require.Empty(t, row.Account, row)
+			continue
+		}
+
+		require.Equal(t, main.DryRunView{Row: row.Row, Date: row.Date, Description: row.Description, Amount: row.Amount, Rule: "groceries", Account: "Expense:Groceries", Tags: []string{"food"}, Name: "Groceries"}, row)
+		hits++
+	}
+	require.Equal(t, 13, hits)
+
+	_, err = run(t, "--db", dbPath, "ingest", "--file", "../../tests/testdata/dbs.csv", "--rules", rulesPath)
+	require.NoError(t, err)
+
+	out, err = run(t, "--db", dbPath, "--output", "json", "imports", "show", "1")
+	require.NoError(t, err)
+
+	var detail main.ImportDetailView
+	require.NoError(t, json.Unmarshal([]byte(out), &detail))
+
+	groceries := 0
+	for _, entry := range detail.JournalEntries {
+		if entry.Name == "Groceries" {
+			require.Equal(t, []string{"food"}, entry.Tags)
+			groceries++
+		}
+	}
+	require.Equal(t, 13, groceries)
+
+	require.NoError(t, os.WriteFile(rulesPath, []byte("rules:\n  - account: Expense:Nope\n"), 0o600))
+	_, err = run(t, "--db", dbPath, "ingest", "--file", "../../tests/testdata/dbs.csv", "--rules", rulesPath)
+	require.ErrorIs(t, err, rules.ErrInvalidRule)
+}
+
+// Suggestions for entries named name, which must all be for account.
+func countSuggestions(t *testing.T, suggestions []main.SuggestionView, name string, account string) int {
+	t.Helper()
+
+	count := 0
+	for _, suggestion := range suggestions {
+		if suggestion.Name == name {
+			require.Equal(t, account, suggestion.Account)
+			require.True(t, suggestion.Amount.IsNegative())
+			count++
+		}
+	}
+
+	return count
+}
+
+func TestMainSuggest(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	dbPath, rulesPath := filepath.Join(dir, "ledger.db"), filepath.Join(dir, "rules.yaml")
+	require.NoError(t, os.WriteFile(rulesPath, []byte(`
+rules:
+  - match: {description: GROCERY_CHAIN_A}
+    account: Expense:Groceries
+  - match: {description: TRANSIT_PROVIDER_}
+    account: Expense:Transportation
+`), 0o600))
+
+	_, err := run(t, "--db", dbPath, "ingest", "--file", "../../tests/testdata/dbs.csv", "--rules", rulesPath)
+	require.NoError(t, err)
+
+	// GROCERY_CHAIN_B wasn't covered by the rules, but looks like GROCERY_CHAIN_A
+	out, err := run(t, "--db", dbPath, "--output", "json", "suggest", "--min-confidence", "0.5")
+	require.NoError(t, err)
+
+	var suggestions []main.SuggestionView
+	require.NoError(t, json.Unmarshal([]byte(out), &suggestions))
+	require.Equal(t, 3, countSuggestions(t, suggestions, "GROCERY_CHAIN_B", "Expense:Groceries"))
+
+	out, err = run(t, "--db", dbPath, "--output", "json", "ingest", "--file", "../../tests/testdata/dbs.csv", "--account", "Asset:CashOnHand", "--auto-categorize", "0.5", "--dry-run")
+	require.NoError(t, err)
+
+	var rows []main.DryRunView
+	require.NoError(t, json.Unmarshal([]byte(out), &rows))
+	autoCategorized := 0
+	for _, row := range rows {
+		if row.Name == "GROCERY_CHAIN_B" {
+			require.Equal(t, "auto-categorize", row.Rule)
+			require.Equal(t, "Expense:Groceries", row.Account)
+			autoCategorized++
+		}
+	}
+	require.Equal(t, 3, autoCategorized)
+
+	// the same statement again, for another account - the new GROCERY_CHAIN_B entries are booked to groceries
+	_, err = run(t, "--db", dbPath, "ingest", "--file", "../../tests/testdata/dbs.csv", "--account", "Asset:CashOnHand", "--auto-categorize", "0.5")
+	require.NoError(t, err)
+
+	out, err = run(t, "--db", dbPath, "--output", "json", "suggest", "--min-confidence", "0.5")
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal([]byte(out), &suggestions))
+	require.Equal(t, 3, countSuggestions(t, suggestions, "GROCERY_CHAIN_B", "Expense:Groceries"))
+
+	_, err = run(t, "--db", dbPath, "suggest", "--min-confidence", "2")
+	require.Error(t, err)
+}
+
+func TestMainIngestReview(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	dbPath, rulesPath := filepath.Join(dir, "ledger.db"), filepath.Join(dir, "rules.yaml")
+	require.NoError(t, os.WriteFile(rulesPath, []byte(`
+rules:
+  - match: {description: GROCERY_CHAIN_A}
+    account: Expense:Groceries
+  - match: {description: TRANSIT_PROVIDER_}
+    account: Expense:Transportation
+`), 0o600))
+
+	_, err := run(t, "--db", dbPath, "--output", "json", "ingest", "--file", "../../tests/testdata/dbs.csv", "--review")
+	require.Error(t, err)
+	_, err = run(t, "--db", dbPath, "ingest", "--file", "../../tests/testdata/dbs.csv", "--review", "--dry-run")
+	require.Error(t, err)
+
+	answers := strings.Join([]string{
+		"", "a", "Expense:Nope", "Expense:DiningOut", // MERCHANT_A: nothing to accept yet, then an unknown account
+		"a", "Income:SalaryWages", "s", // MERCHANT_B: spending can't be income, so skip it
+		"r", "Expense:DiningOut", // MERCHANT_C, with a rule
+		"", // MERCHANT_D: the suggestion learnt from the first two
+		"q",
+	}, "\n")
+	out, err := runWithInput(t, answers, "--db", dbPath, "ingest", "--file", "../../tests/testdata/dbs.csv", "--rules", rulesPath, "--review")
+	require.NoError(t, err)
+	require.Contains(t, out, "[1/63] 2025-10-22  MERCHANT_A  -12.40  suggested: none")
+	require.Contains(t, out, "is booked to an expense account, but account 3100 is an income account")
+	require.Contains(t, out, "[4/63] 2025-10-20  MERCHANT_D  -14.70  suggested: Expense:DiningOut")
+	require.Contains(t, out, "reviewed 63 uncategorised transactions: 3 booked, 1 rules added, 60 skipped")
+
+	ctx := t.Context()
+	db, err := sqlite.Open(ctx, dbPath)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	repo, err := sqlite.NewAccountingRepository(ctx, db)
+	require.NoError(t, err)
+	entries, err := repo.ListJournalEntries(ctx)
+	require.NoError(t, err)
+	accounts, err := repo.ListAccounts(ctx)
+	require.NoError(t, err)
+
+	booked := map[string]int{}
+	reversals := domain.Reversals(entries)
+	for _, entry := range entries {
+		if _, reversed := reversals[entry.ID]; reversed || entry.Reverses != 0 {
+			continue
+		}
+
+		if posting, ok := domain.CategoryPosting(entry, accounts); ok && posting.AccountID == domain.AccountID_Expense_DiningOut {
+			booked[entry.Name]++
+		}
+	}
+	require.Equal(t, map[string]int{"MERCHANT_A": 1, "MERCHANT_C": 1, "MERCHANT_D": 1}, booked)
+
+	file, err := rules.ReadFile(rulesPath)
+	require.NoError(t, err)
+	require.Len(t, file.Rules, 3)
+	require.Equal(t, rules.Rule{Name: "MERCHANT_C", Match: rules.Match{Description: "^MERCHANT_C           SINGAPORE     SG$", Direction: rules.DirectionOut}, Account: "Expense:DiningOut"}, file.Rules[2])
+}
+
+func TestMainIngestReviewRuleAliasedMerchant(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	dbPath, rulesPath, aliasesPath := filepath.Join(dir, "ledger.db"), filepath.Join(dir, "rules.yaml"), filepath.Join(dir, "aliases.csv")
+	require.NoError(t, os.WriteFile(rulesPath, []byte("rules: []\n"), 0o600))
+	require.NoError(t, os.WriteFile(aliasesPath, []byte("pattern,name\nMERCHANT_A,Cafe\n"), 0o600))
+
+	answers := strings.Join([]string{"r", "Expense:DiningOut", "q"}, "\n")
+	out, err := runWithInput(t, answers, "--db", dbPath, "ingest", "--file", "../../tests/testdata/dbs.csv", "--rules", rulesPath, "--merchant-aliases", aliasesPath, "--review")
+	require.NoError(t, err)
+	require.Contains(t, out, "[1/97] 2025-10-22  Cafe  -12.40  suggested: none")
+	require.Contains(t, out, "1 rules added")
+
+	// the rule matches what the bank wrote, not the alias, so it books the merchant when it's imported again
+	out, err = run(t, "--db", filepath.Join(dir, "again.db"), "--output", "json", "ingest", "--file", "../../tests/testdata/dbs.csv", "--rules", rulesPath, "--merchant-aliases", aliasesPath, "--dry-run")
+	require.NoError(t, err)
+
+	var rows []main.DryRunView
+	require.NoError(t, json.Unmarshal([]byte(out), &rows))
+	hits := 0
+	for _, row := range rows {
+		if row.Rule != "" {
+			require.Equal(t, "Cafe", row.Rule, row)
+			require.Equal(t, "Expense:DiningOut", row.Account, row)
+			hits++
+		}
+	}
+	require.Equal(t, 1, hits)
+}
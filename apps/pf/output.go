@@ -0,0 +1,50 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/urfave/cli/v3"
+)
+
+// Writes v to stdout as json if --output=json, or with writeText otherwise.
+func (app *App) render(c *cli.Command, v any, writeText func(w io.Writer) error) error {
+	if app.isJSON(c) {
+		enc := json.NewEncoder(app.stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(v); err != nil {
+			return fmt.Errorf("error encoding output as json: %+v", err)
+		}
+
+		return nil
+	}
+
+	return writeText(app.stdout)
+}
+
+// Writes rows as tab-aligned columns. Runs of whitespace (including newlines) in cells are collapsed so rows stay on one line.
+func writeTable(w io.Writer, header []string, rows [][]string) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	for _, row := range append([][]string{header}, rows...) {
+		for idx, cell := range row {
+			sep := "\t"
+			if idx == len(row)-1 {
+				sep = "\n"
+			}
+
+			if _, err := fmt.Fprint(tw, strings.Join(strings.Fields(cell), " "), sep); err != nil {
+				return fmt.Errorf("error writing table: %+v", err)
+			}
+		}
+	}
+
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("error writing table: %+v", err)
+	}
+
+	return nil
+}
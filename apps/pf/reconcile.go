@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	domain "personal-finance/pkgs/domains"
+	"personal-finance/pkgs/money"
+	"personal-finance/pkgs/reconcile"
+	"strconv"
+	"time"
+
+	"github.com/urfave/cli/v3"
+)
+
+func NewReconcileCommand(app *App) *cli.Command {
+	return &cli.Command{
+		Name:  "reconcile",
+		Usage: "checks the ledger against an imported statement's balance, and marks the statement period as reconciled if they agree",
+		Flags: []cli.Flag{
+			&cli.Int64Flag{
+				Name:  "import",
+				Usage: "`ID` of the import whose statement to reconcile against (defaults to the latest import)",
+			},
+		},
+		Action: app.reconcile,
+		Commands: []*cli.Command{
+			{
+				Name:   "list",
+				Usage:  "lists past reconciliations",
+				Action: app.listReconciliations,
+			},
+		},
+	}
+}
+
+func (app *App) reconcile(ctx context.Context, c *cli.Command) error {
+	app.slogger.InfoContext(ctx, "running reconcile command", slog.Int64("args.import", c.Int64("import")))
+
+	repo, err := app.Repository(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	importID := c.Int64("import")
+	if importID == 0 {
+		imports, err := repo.ListImports(ctx)
+		if err != nil {
+			return fmt.Errorf("error listing imports: %+v", err)
+		}
+		if len(imports) == 0 {
+			return fmt.Errorf("%w: nothing has been imported yet", domain.ErrImportNotFound)
+		}
+
+		importID = imports[len(imports)-1].ID
+	}
+
+	check, err := reconcile.ReconcileImport(ctx, repo, importID, time.Now())
+	if err != nil && !errors.Is(err, reconcile.ErrDiscrepancy) {
+		return fmt.Errorf("error reconciling import %d: %w", importID, err)
+	}
+
+	if renderErr := app.render(c, check, func(w io.Writer) error { return writeCheck(w, check) }); renderErr != nil {
+		return renderErr
+	}
+
+	return err
+}
+
+func (app *App) listReconciliations(ctx context.Context, c *cli.Command) error {
+	app.slogger.InfoContext(ctx, "running reconcile list command")
+
+	repo, err := app.Repository(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	reconciliations, err := repo.ListReconciliations(ctx)
+	if err != nil {
+		return fmt.Errorf("error listing reconciliations: %+v", err)
+	}
+
+	accounts, err := repo.ListAccounts(ctx)
+	if err != nil {
+		return fmt.Errorf("error listing accounts: %+v", err)
+	}
+
+	type view struct {
+		ID           int64        `json:"id"`
+		Account      string       `json:"account"`
+		ImportID     int64        `json:"import_id"`
+		AsOf         time.Time    `json:"as_of"`
+		Balance      money.Amount `json:"balance"`
+		ReconciledAt time.Time    `json:"reconciled_at"`
+	}
+
+	views := make([]view, 0, len(reconciliations))
+	for _, r := range reconciliations {
+		path, err := domain.AccountPath(accounts, r.AccountID)
+		if err != nil {
+			return err
+		}
+
+		views = append(views, view{ID: r.ID, Account: path, ImportID: r.ImportID, AsOf: r.AsOf, Balance: r.Balance, ReconciledAt: r.ReconciledAt})
+	}
+
+	return app.render(c, views, func(w io.Writer) error {
+		rows := make([][]string, 0, len(views))
+		for _, v := range views {
+			rows = append(rows, []string{strconv.FormatInt(v.ID, 10), v.Account, strconv.FormatInt(v.ImportID, 10), v.AsOf.Format(time.DateOnly), v.Balance.Number()})
+		}
+
+		return writeTable(w, []string{"ID", "ACCOUNT", "IMPORT", "AS OF", "BALANCE"}, rows)
+	})
+}
+
+// Writes a balance check, listing the unreconciled entries if the balances disagree.
+func writeCheck(w io.Writer, check reconcile.Check) error {
+	status := "reconciled"
+	if !check.Reconciled() {
+		status = "DISCREPANCY"
+	}
+
+	_, err := fmt.Fprintf(w, "%s: %s as of %s - statement %s, ledger %s, difference %s\n",
+		status, check.Account, check.AsOf.Format(time.DateOnly), check.StatementBalance.Number(), check.LedgerBalance.Number(), check.Difference.Number())
+	if err != nil || check.Reconciled() {
+		return err
+	}
+
+	since := "the beginning (is the opening balance missing?)"
+	if !check.From.IsZero() {
+		since = "the last reconciliation on " + check.From.Format(time.DateOnly)
+	}
+	if _, err := fmt.Fprintf(w, "\n%d transactions since %s:\n", len(check.Unreconciled), since); err != nil {
+		return err
+	}
+
+	rows := make([][]string, 0, len(check.Unreconciled))
+	for _, line := range check.Unreconciled {
+		rows = append(rows, []string{strconv.FormatInt(line.JournalEntryID, 10), line.Date.Format(time.DateOnly), line.Name, line.Amount.Number()})
+	}
+
+	return writeTable(w, []string{"ENTRY", "DATE", "DESCRIPTION", "AMOUNT"}, rows)
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	domain "personal-finance/pkgs/domains"
+	"personal-finance/pkgs/period"
+	"personal-finance/pkgs/report"
+
+	"github.com/urfave/cli/v3"
+)
+
+func NewReportCommand(app *App) *cli.Command {
+	return &cli.Command{
+		Name:  "report",
+		Usage: "shows income and expenses per account",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "month",
+				Aliases: []string{"m"},
+				Usage:   fmt.Sprintf("report on a single month in `yyyy-mm` (e.g. %s)", GetLastMonthYYYYMM(DefaultNower)),
+			},
+			&cli.StringFlag{
+				Name:  "from",
+				Usage: "first month to report on in `yyyy-mm` (inclusive) - can't be combined with --month",
+			},
+			&cli.StringFlag{
+				Name:  "to",
+				Usage: "last month to report on in `yyyy-mm` (inclusive) - can't be combined with --month",
+			},
+			&cli.BoolFlag{
+				Name:  "include-pending",
+				Usage: "include card transactions that haven't settled yet",
+			},
+		},
+		Action: app.report,
+	}
+}
+
+func (app *App) report(ctx context.Context, c *cli.Command) error {
+	app.slogger.InfoContext(ctx,
+		"running report command",
+		slog.String("args.month", c.String("month")),
+		slog.String("args.from", c.String("from")),
+		slog.String("args.to", c.String("to")),
+		slog.Bool("args.include-pending", c.Bool("include-pending")),
+	)
+
+	dateRange, err := period.NewRange(c.String("month"), c.String("from"), c.String("to"))
+	if err != nil {
+		return fmt.Errorf("error parsing month filter: %+v", err)
+	}
+
+	repo, err := app.Repository(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	entries, err := repo.ListJournalEntries(ctx)
+	if err != nil {
+		return fmt.Errorf("error listing journal entries: %+v", err)
+	}
+
+	if !c.Bool("include-pending") {
+		entries = domain.SettledEntries(entries)
+	}
+
+	accounts, err := repo.ListAccounts(ctx)
+	if err != nil {
+		return fmt.Errorf("error listing accounts: %+v", err)
+	}
+
+	statement, err := report.NewIncomeStatement(entries, accounts, dateRange)
+	if err != nil {
+		return fmt.Errorf("error building report: %w", err)
+	}
+
+	return app.render(c, statement, func(w io.Writer) error {
+		if _, err := fmt.Fprintf(w, "income statement for %s\n\n", statement.Period); err != nil {
+			return err
+		}
+
+		rows := [][]string{}
+		for _, account := range statement.Accounts {
+			rows = append(rows, []string{account.Account, account.Total.Number()})
+		}
+		rows = append(rows,
+			[]string{"", ""},
+			[]string{"Total Income", statement.Income.Number()},
+			[]string{"Total Expenses", statement.Expenses.Number()},
+			[]string{"Net", statement.Net.Number()},
+		)
+
+		return writeTable(w, []string{"ACCOUNT", "AMOUNT"}, rows)
+	})
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"personal-finance/pkgs/classify"
+	domain "personal-finance/pkgs/domains"
+	"personal-finance/pkgs/ingest"
+	"personal-finance/pkgs/rules"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v3"
+)
+
+// Answers to the review prompt, typed as a single key and enter
+const (
+	ReviewAnswer_Accept  = ""  // book to the suggested account
+	ReviewAnswer_Account = "a" // book to another account
+	ReviewAnswer_Rule    = "r" // book to another account, and add a rule that does the same for future imports
+	ReviewAnswer_Skip    = "s" // leave it uncategorised
+	ReviewAnswer_Quit    = "q" // leave it and the rest uncategorised
+)
+
+const reviewHelp = "[enter] books it to the suggested account, [a] to another account, [r] to another account and adds a rule for it, [s] skips it, [q] stops reviewing"
+
+type reviewSummary struct {
+	Uncategorized int // entries there were to review
+	Booked        int
+	Rules         int // rules added
+	Skipped       int // including those left when the review was stopped
+}
+
+// Asks the user which account each uncategorised entry of import importID belongs to, one at a time,
+// suggesting the account the classifier thinks is most likely (see `pf suggest`), and rebooks the entry to their answer.
+// Answers can also be saved as rules in the rules file at rulesPath, if it's given.
+func (app *App) review(ctx context.Context, c *cli.Command, repo domain.AccountingRepository, importID int64, rulesPath string) error {
+	app.slogger.InfoContext(ctx, "reviewing uncategorised transactions", slog.Int64("import", importID), slog.String("rules", rulesPath))
+
+	classifier, entries, accounts, err := trainClassifier(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	queue := []classify.UncategorizedEntry{}
+	for _, u := range classify.Uncategorized(entries, accounts) {
+		if u.Entry.Provenance.ImportID == importID {
+			queue = append(queue, u)
+		}
+	}
+
+	summary := reviewSummary{Uncategorized: len(queue)}
+	if len(queue) == 0 {
+		return nil
+	}
+
+	prompt := &reviewPrompt{in: bufio.NewScanner(c.Root().Reader), out: app.stdout}
+	if err := prompt.say("\n%d uncategorised transactions to review - %s\n", len(queue), reviewHelp); err != nil {
+		return err
+	}
+
+	for idx, u := range queue {
+		amount, err := u.Posting.Credit.Sub(u.Posting.Debit)
+		if err != nil {
+			return fmt.Errorf("error computing amount of journal entry %d: %w", u.Entry.ID, err)
+		}
+
+		suggestion, suggested := classifier.PredictEntry(u.Entry, u.Posting)
+		suggestedPath := "none"
+		if suggested {
+			path, err := domain.AccountPath(accounts, suggestion.AccountID)
+			if err != nil {
+				return err
+			}
+
+			suggestedPath = fmt.Sprintf("%s (%s)", path, formatConfidence(suggestion.Confidence))
+		}
+
+		err = prompt.say("\n[%d/%d] %s  %s  %s  suggested: %s\n", idx+1, len(queue), u.Entry.Date.Format(time.DateOnly), u.Entry.Name, amount.Number(), suggestedPath)
+		if err != nil {
+			return err
+		}
+
+		account, addRule, ok, err := app.book(ctx, repo, prompt, u.Entry.ID, suggestion.AccountID, rulesPath != "")
+		if err != nil {
+			return err
+		}
+		if !ok {
+			summary.Skipped++
+			if prompt.quit {
+				summary.Skipped += len(queue) - idx - 1
+				break
+			}
+
+			continue
+		}
+		summary.Booked++
+
+		if addRule {
+			path, err := accountPath(ctx, repo, account.ID)
+			if err != nil {
+				return err
+			}
+
+			if err := rules.AppendRule(rulesPath, newReviewRule(u, path)); err != nil {
+				return err
+			}
+			summary.Rules++
+		}
+
+		// learn from the answer before suggesting the next account
+		if classifier, _, _, err = trainClassifier(ctx, repo); err != nil {
+			return err
+		}
+	}
+
+	return prompt.say("\nreviewed %d uncategorised transactions: %d booked, %d rules added, %d skipped\n", summary.Uncategorized, summary.Booked, summary.Rules, summary.Skipped)
+}
+
+// Reads answers to the review prompt.
+type reviewPrompt struct {
+	in   *bufio.Scanner
+	out  io.Writer
+	quit bool // the user stopped reviewing, or there's no more input
+}
+
+func (p *reviewPrompt) say(format string, args ...any) error {
+	_, err := fmt.Fprintf(p.out, format, args...)
+	return err
+}
+
+// The next line of input, trimmed - false once input runs out.
+func (p *reviewPrompt) ask(prompt string) (string, bool, error) {
+	if err := p.say("%s> ", prompt); err != nil {
+		return "", false, err
+	}
+
+	if !p.in.Scan() {
+		p.quit = true
+		if err := p.in.Err(); err != nil {
+			return "", false, fmt.Errorf("error reading answer: %+v", err)
+		}
+
+		return "", false, nil
+	}
+
+	return strings.TrimSpace(p.in.Text()), true, nil
+}
+
+// Asks for the account the entry with entryID belongs to and rebooks it there (see askAccount),
+// asking again if the account is the wrong type, e.g. an income account for spending.
+func (app *App) book(ctx context.Context, repo domain.AccountingRepository, prompt *reviewPrompt, entryID int64, suggestedAccountID int64, canAddRule bool) (account domain.LedgerAccount, addRule bool, ok bool, err error) {
+	for {
+		account, addRule, ok, err := app.askAccount(ctx, repo, prompt, suggestedAccountID, canAddRule)
+		if err != nil || !ok {
+			return domain.LedgerAccount{}, false, false, err
+		}
+
+		_, err = ingest.Recategorize(ctx, repo, entryID, account.ID)
+		if errors.Is(err, ingest.ErrWrongAccountType) {
+			if err := prompt.say("%+v\n", err); err != nil {
+				return domain.LedgerAccount{}, false, false, err
+			}
+			continue
+		}
+		if err != nil {
+			return domain.LedgerAccount{}, false, false, fmt.Errorf("error booking journal entry %d to account %d: %w", entryID, account.ID, err)
+		}
+
+		return account, addRule, true, nil
+	}
+}
+
+// Asks for the account an entry belongs to, until the user answers with one (ok), skips or quits (!ok).
+// suggestedAccountID is 0 if there's no suggestion.
+func (app *App) askAccount(ctx context.Context, repo domain.AccountingRepository, prompt *reviewPrompt, suggestedAccountID int64, canAddRule bool) (account domain.LedgerAccount, addRule bool, ok bool, err error) {
+	for {
+		answer, ok, err := prompt.ask("")
+		if err != nil || !ok {
+			return domain.LedgerAccount{}, false, false, err
+		}
+
+		switch strings.ToLower(answer) {
+		case ReviewAnswer_Accept:
+			if suggestedAccountID == 0 {
+				if err := prompt.say("there's no suggestion - %s\n", reviewHelp); err != nil {
+					return domain.LedgerAccount{}, false, false, err
+				}
+				continue
+			}
+
+			account, err := findAccount(ctx, repo, fmt.Sprint(suggestedAccountID))
+			return account, false, err == nil, err
+		case ReviewAnswer_Account, ReviewAnswer_Rule:
+			addRule = strings.EqualFold(answer, ReviewAnswer_Rule)
+			if addRule && !canAddRule {
+				if err := prompt.say("there's no rules file to add the rule to - give one with --rules\n"); err != nil {
+					return domain.LedgerAccount{}, false, false, err
+				}
+				continue
+			}
+
+			for {
+				ref, ok, err := prompt.ask("account (an id or path, e.g. Expense:Groceries) ")
+				if err != nil || !ok {
+					return domain.LedgerAccount{}, false, false, err
+				}
+				if ref == "" {
+					break // back to the first question
+				}
+
+				account, err := findAccount(ctx, repo, ref)
+				if err == nil {
+					return account, addRule, true, nil
+				}
+				if err := prompt.say("%+v\n", err); err != nil {
+					return domain.LedgerAccount{}, false, false, err
+				}
+			}
+		case ReviewAnswer_Skip:
+			return domain.LedgerAccount{}, false, false, nil
+		case ReviewAnswer_Quit:
+			prompt.quit = true
+			return domain.LedgerAccount{}, false, false, nil
+		default:
+			if err := prompt.say("unknown answer '%s' - %s\n", answer, reviewHelp); err != nil {
+				return domain.LedgerAccount{}, false, false, err
+			}
+		}
+	}
+}
+
+// A rule booking transactions like u's to the account at accountPath:
+// the same counterparty if it has one, otherwise the same description as the bank wrote it, with money going the same way.
+// Rules match what the bank wrote, not the entry's name, which may be a merchant alias or a rule's rename.
+func newReviewRule(u classify.UncategorizedEntry, accountPath string) rules.Rule {
+	match := rules.Match{Direction: rules.DirectionIn}
+	if u.Posting.Debit.Sign() > 0 {
+		match.Direction = rules.DirectionOut
+	}
+
+	switch {
+	case u.Entry.Counterparty != "":
+		match.Counterparty = "^" + regexp.QuoteMeta(u.Entry.Counterparty) + "$"
+	case u.Entry.Description != "":
+		match.Description = "^" + regexp.QuoteMeta(u.Entry.Description) + "$"
+	default:
+		// imported before entries kept the bank's description - the name is the best there is
+		match.Description = regexp.QuoteMeta(u.Entry.Name)
+	}
+
+	return rules.Rule{Name: u.Entry.Name, Match: match, Account: accountPath}
+}
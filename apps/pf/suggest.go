@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"personal-finance/pkgs/classify"
+	domain "personal-finance/pkgs/domains"
+	"personal-finance/pkgs/money"
+	"strconv"
+	"time"
+
+	"github.com/urfave/cli/v3"
+)
+
+func NewSuggestCommand(app *App) *cli.Command {
+	return &cli.Command{
+		Name:  "suggest",
+		Usage: "suggests accounts for uncategorised transactions, learnt from the ones already categorised",
+		Flags: []cli.Flag{
+			&cli.FloatFlag{
+				Name:  "min-confidence",
+				Usage: "only show suggestions at least this `CONFIDENT` (between 0 and 1)",
+			},
+		},
+		Action: app.suggest,
+	}
+}
+
+// An uncategorised journal entry and the account it probably belongs to, as shown to the user.
+type SuggestionView struct {
+	ID         int64        `json:"id"`
+	Date       time.Time    `json:"date"`
+	Name       string       `json:"name"`
+	Amount     money.Amount `json:"amount"`     // money in (+) or out (-)
+	Account    string       `json:"account"`    // full path of the suggested account
+	Confidence float64      `json:"confidence"` // between 0 and 1
+}
+
+func (app *App) suggest(ctx context.Context, c *cli.Command) error {
+	app.slogger.InfoContext(ctx, "running suggest command", slog.Float64("args.min-confidence", c.Float("min-confidence")))
+
+	minConfidence := c.Float("min-confidence")
+	if err := validateConfidence(minConfidence); err != nil {
+		return err
+	}
+
+	repo, err := app.Repository(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	classifier, entries, accounts, err := trainClassifier(ctx, repo)
+	if err != nil {
+		return err
+	}
+
+	views := []SuggestionView{}
+	for _, suggestion := range classifier.Suggest(entries, accounts) {
+		if suggestion.Prediction.Confidence < minConfidence {
+			continue
+		}
+
+		view := SuggestionView{ID: suggestion.Entry.ID, Date: suggestion.Entry.Date, Name: suggestion.Entry.Name, Confidence: suggestion.Prediction.Confidence}
+		if view.Amount, err = suggestion.Posting.Credit.Sub(suggestion.Posting.Debit); err != nil {
+			return fmt.Errorf("error computing amount of journal entry %d: %w", suggestion.Entry.ID, err)
+		}
+		if view.Account, err = domain.AccountPath(accounts, suggestion.Prediction.AccountID); err != nil {
+			return err
+		}
+
+		views = append(views, view)
+	}
+
+	return app.render(c, views, func(w io.Writer) error {
+		rows := make([][]string, 0, len(views))
+		for _, v := range views {
+			rows = append(rows, []string{strconv.FormatInt(v.ID, 10), v.Date.Format(time.DateOnly), v.Name, v.Amount.Number(), v.Account, formatConfidence(v.Confidence)})
+		}
+
+		return writeTable(w, []string{"ENTRY", "DATE", "NAME", "AMOUNT", "SUGGESTED ACCOUNT", "CONFIDENCE"}, rows)
+	})
+}
+
+// A classifier trained on the ledger, and the entries and accounts it was trained on.
+func trainClassifier(ctx context.Context, repo domain.AccountingRepository) (classify.Classifier, []domain.JournalEntry, []domain.LedgerAccount, error) {
+	entries, err := repo.ListJournalEntries(ctx)
+	if err != nil {
+		return classify.Classifier{}, nil, nil, fmt.Errorf("error listing journal entries: %+v", err)
+	}
+
+	accounts, err := repo.ListAccounts(ctx)
+	if err != nil {
+		return classify.Classifier{}, nil, nil, fmt.Errorf("error listing accounts: %+v", err)
+	}
+
+	return classify.Train(entries, accounts), entries, accounts, nil
+}
+
+func validateConfidence(confidence float64) error {
+	if confidence < 0 || confidence > 1 {
+		return fmt.Errorf("confidence must be between 0 and 1, got %v", confidence)
+	}
+
+	return nil
+}
+
+// e.g. "87%"
+func formatConfidence(confidence float64) string {
+	return strconv.FormatFloat(confidence*100, 'f', 0, 64) + "%"
+}
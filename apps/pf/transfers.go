@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	domain "personal-finance/pkgs/domains"
+	"personal-finance/pkgs/ingest"
+	"personal-finance/pkgs/money"
+	"strconv"
+	"time"
+
+	"github.com/urfave/cli/v3"
+)
+
+func NewTransfersCommand(app *App) *cli.Command {
+	return &cli.Command{
+		Name:  "transfers",
+		Usage: "finds money moved between your own accounts, e.g. a card bill paid from the bank account",
+		Commands: []*cli.Command{
+			{
+				Name:  "match",
+				Usage: "collapses both sides of each transfer into a single entry - `pf ingest` does this after every import",
+				Flags: []cli.Flag{
+					&cli.IntFlag{
+						Name:  "window-days",
+						Value: ingest.DefaultTransferOptions.WindowDays,
+						Usage: "how many `DAYS` apart the two sides of a transfer can be",
+					},
+					&cli.StringFlag{
+						Name:  "tolerance",
+						Value: "0",
+						Usage: "how much the two sides' `AMOUNT`s can differ by, e.g. to allow for a bank fee",
+					},
+					&cli.BoolFlag{
+						Name:  "dry-run",
+						Usage: "only show the transfers that would be collapsed",
+					},
+				},
+				Action: app.matchTransfers,
+			},
+		},
+	}
+}
+
+// A transfer as shown to the user.
+type TransferView struct {
+	OutEntryID int64        `json:"out_entry_id"` // journal entry for the money leaving From
+	InEntryID  int64        `json:"in_entry_id"`  // journal entry for the money arriving in To
+	Date       time.Time    `json:"date"`
+	Name       string       `json:"name"`
+	From       string       `json:"from"` // full path, e.g. "Asset:BankAccount"
+	To         string       `json:"to"`
+	Sent       money.Amount `json:"sent"`
+	Received   money.Amount `json:"received"`
+}
+
+func (app *App) matchTransfers(ctx context.Context, c *cli.Command) error {
+	app.slogger.InfoContext(ctx,
+		"running transfers match command",
+		slog.Int("args.window-days", c.Int("window-days")),
+		slog.String("args.tolerance", c.String("tolerance")),
+		slog.Bool("args.dry-run", c.Bool("dry-run")),
+	)
+
+	tolerance, err := money.Parse(c.String("tolerance"), money.SGD)
+	if err != nil {
+		return fmt.Errorf("error parsing tolerance '%s': %w", c.String("tolerance"), err)
+	}
+
+	repo, err := app.Repository(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	opts := ingest.TransferOptions{WindowDays: c.Int("window-days"), Tolerance: tolerance}
+	matches, err := ingest.CollapseTransfers(ctx, repo, opts, 0, c.Bool("dry-run"))
+	if err != nil {
+		return fmt.Errorf("error matching transfers: %w", err)
+	}
+
+	accounts, err := repo.ListAccounts(ctx)
+	if err != nil {
+		return fmt.Errorf("error listing accounts: %+v", err)
+	}
+
+	views := make([]TransferView, 0, len(matches))
+	for _, match := range matches {
+		from, err := domain.AccountPath(accounts, match.OutAccountID)
+		if err != nil {
+			return err
+		}
+
+		to, err := domain.AccountPath(accounts, match.InAccountID)
+		if err != nil {
+			return err
+		}
+
+		views = append(views, TransferView{
+			OutEntryID: match.Out.ID,
+			InEntryID:  match.In.ID,
+			Date:       match.Out.Date,
+			Name:       match.Out.Name,
+			From:       from,
+			To:         to,
+			Sent:       match.Sent,
+			Received:   match.Received,
+		})
+	}
+
+	return app.render(c, views, func(w io.Writer) error {
+		verb := "collapsed"
+		if c.Bool("dry-run") {
+			verb = "would collapse"
+		}
+
+		if _, err := fmt.Fprintf(w, "%s %d transfers\n\n", verb, len(views)); err != nil {
+			return err
+		}
+
+		rows := make([][]string, 0, len(views))
+		for _, v := range views {
+			rows = append(rows, []string{
+				strconv.FormatInt(v.OutEntryID, 10), strconv.FormatInt(v.InEntryID, 10), v.Date.Format(time.DateOnly),
+				v.Name, v.From, v.To, v.Sent.Number(), v.Received.Number(),
+			})
+		}
+
+		return writeTable(w, []string{"OUT", "IN", "DATE", "DESCRIPTION", "FROM", "TO", "SENT", "RECEIVED"}, rows)
+	})
+}
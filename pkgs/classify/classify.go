@@ -0,0 +1,255 @@
+// Package classify learns which accounts transactions are booked to from the journal entries already categorised,
+// and suggests accounts for the ones that aren't - a naive Bayes classifier over the words in their names,
+// their size and the day of the week.
+package classify
+
+import (
+	"cmp"
+	"math"
+	domain "personal-finance/pkgs/domains"
+	"personal-finance/pkgs/ingest"
+	"personal-finance/pkgs/money"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// What the classifier knows about a transaction.
+type Example struct {
+	Name         string
+	Counterparty string
+	Amount       money.Amount // money into (+) or out of (-) the account, from the account holder's point of view
+	Date         time.Time
+
+	// type of account it's booked to: expense for spending and refunds (which bring money in), income for income
+	AccountType domain.AccountType
+}
+
+func NewExample(t ingest.Transaction) Example {
+	return Example{Name: t.EntryName(), Counterparty: t.Counterparty, Amount: t.Amount, Date: t.Date, AccountType: t.CategoryType()}
+}
+
+// The category account the classifier thinks a transaction belongs to.
+type Prediction struct {
+	AccountID  int64
+	Confidence float64 // probability it's right, between 0 and 1 - only as good as what it learnt from
+}
+
+type Classifier struct {
+	classes      map[int64]*class             // key: category account id
+	vocabulary   map[string]bool              // every feature seen while training
+	accountTypes map[int64]domain.AccountType // key: account id, of every account known while training
+}
+
+type class struct {
+	accountType domain.AccountType // income or expense
+	examples    int
+	features    map[string]int // times each feature was seen
+	total       int            // sum of features
+}
+
+// Learns from entries booked to an income or expense account other than the uncategorised ones.
+// Reversed entries (and the reversals) are left out, since they were mistakes or have been replaced.
+func Train(entries []domain.JournalEntry, accounts []domain.LedgerAccount) Classifier {
+	c := Classifier{classes: map[int64]*class{}, vocabulary: map[string]bool{}, accountTypes: make(map[int64]domain.AccountType, len(accounts))}
+	for _, account := range accounts {
+		c.accountTypes[account.ID] = account.Type
+	}
+
+	reversals := domain.Reversals(entries)
+	for _, entry := range entries {
+		if _, reversed := reversals[entry.ID]; reversed || entry.Reverses != 0 {
+			continue
+		}
+
+		posting, ok := domain.CategoryPosting(entry, accounts)
+		if !ok || isUncategorized(posting.AccountID) {
+			continue
+		}
+
+		c.learn(posting.AccountID, c.entryExample(entry, posting))
+	}
+
+	return c
+}
+
+func (c *Classifier) learn(accountID int64, e Example) {
+	cl, ok := c.classes[accountID]
+	if !ok {
+		cl = &class{accountType: e.AccountType, features: map[string]int{}}
+		c.classes[accountID] = cl
+	}
+
+	cl.examples++
+	for _, feature := range features(e) {
+		cl.features[feature]++
+		cl.total++
+		c.vocabulary[feature] = true
+	}
+}
+
+// The account of e.AccountType that e most likely belongs to, e.g. an expense account for spending or a refund.
+// There's no prediction if the classifier hasn't learnt at least two such accounts to choose between,
+// or has never seen any of the words in e's name or counterparty.
+func (c Classifier) Predict(e Example) (Prediction, bool) {
+	candidates, examples := map[int64]*class{}, 0
+	for accountID, cl := range c.classes {
+		if cl.accountType == e.AccountType {
+			candidates[accountID] = cl
+			examples += cl.examples
+		}
+	}
+	if len(candidates) < 2 {
+		return Prediction{}, false
+	}
+
+	fs := features(e)
+	if !slices.ContainsFunc(fs, func(f string) bool { return c.vocabulary[f] && strings.HasPrefix(f, wordPrefix) }) {
+		return Prediction{}, false
+	}
+
+	// log P(account) + sum of log P(feature | account), with add-one smoothing for features an account hasn't seen
+	type score struct {
+		accountID int64
+		log       float64
+	}
+	scores := make([]score, 0, len(candidates))
+	for accountID, cl := range candidates {
+		s := math.Log(float64(cl.examples) / float64(examples))
+		for _, f := range fs {
+			s += math.Log(float64(cl.features[f]+1) / float64(cl.total+len(c.vocabulary)))
+		}
+
+		scores = append(scores, score{accountID: accountID, log: s})
+	}
+	slices.SortFunc(scores, func(a, b score) int { return cmp.Or(cmp.Compare(b.log, a.log), cmp.Compare(a.accountID, b.accountID)) })
+
+	// normalise the best score against all of them: P(best | features) = 1 / sum of e^(score - best)
+	sum := 0.0
+	for _, s := range scores {
+		sum += math.Exp(s.log - scores[0].log)
+	}
+
+	return Prediction{AccountID: scores[0].accountID, Confidence: 1 / sum}, true
+}
+
+// Books transactions the classifier is at least Threshold confident about (see ingest.Options.Fallback).
+type AutoCategorizer struct {
+	Classifier Classifier
+	Threshold  float64
+}
+
+var _ ingest.Categorizer = AutoCategorizer{}
+
+func (a AutoCategorizer) Categorize(t ingest.Transaction, _ string) (ingest.Category, bool) {
+	prediction, ok := a.Classifier.Predict(NewExample(t))
+	if !ok || prediction.Confidence < a.Threshold {
+		return ingest.Category{}, false
+	}
+
+	return ingest.Category{AccountID: prediction.AccountID}, true
+}
+
+// An uncategorised journal entry and the account it probably belongs to.
+type Suggestion struct {
+	Entry      domain.JournalEntry
+	Posting    domain.Posting // the entry's uncategorised leg
+	Prediction Prediction
+}
+
+// Suggests accounts for uncategorised entries (see Uncategorized). Entries the classifier can't predict are left out.
+func (c Classifier) Suggest(entries []domain.JournalEntry, accounts []domain.LedgerAccount) []Suggestion {
+	suggestions := []Suggestion{}
+	for _, u := range Uncategorized(entries, accounts) {
+		if prediction, ok := c.PredictEntry(u.Entry, u.Posting); ok {
+			suggestions = append(suggestions, Suggestion{Entry: u.Entry, Posting: u.Posting, Prediction: prediction})
+		}
+	}
+
+	return suggestions
+}
+
+// The account an entry's income or expense leg most likely belongs to (see Predict).
+func (c Classifier) PredictEntry(entry domain.JournalEntry, posting domain.Posting) (Prediction, bool) {
+	return c.Predict(c.entryExample(entry, posting))
+}
+
+// A journal entry booked to the uncategorised income or expense account.
+type UncategorizedEntry struct {
+	Entry   domain.JournalEntry
+	Posting domain.Posting // the entry's uncategorised leg
+}
+
+// Entries booked to the uncategorised income or expense account, that haven't been reversed.
+func Uncategorized(entries []domain.JournalEntry, accounts []domain.LedgerAccount) []UncategorizedEntry {
+	uncategorized := []UncategorizedEntry{}
+	reversals := domain.Reversals(entries)
+	for _, entry := range entries {
+		if _, reversed := reversals[entry.ID]; reversed || entry.Reverses != 0 {
+			continue
+		}
+
+		if posting, ok := domain.CategoryPosting(entry, accounts); ok && isUncategorized(posting.AccountID) {
+			uncategorized = append(uncategorized, UncategorizedEntry{Entry: entry, Posting: posting})
+		}
+	}
+
+	return uncategorized
+}
+
+func isUncategorized(accountID int64) bool {
+	return accountID == domain.AccountID_Expense_Uncategorized || accountID == domain.AccountID_Income_Uncategorized
+}
+
+// Debiting the category account is money going out, e.g. spending; crediting it is money coming in, e.g. income or a refund.
+// Which of those it is comes from the type of the account (e.g. Expense:Uncategorized for a refund), not which way the money went.
+func (c Classifier) entryExample(entry domain.JournalEntry, posting domain.Posting) Example {
+	amount, err := posting.Credit.Sub(posting.Debit)
+	if err != nil {
+		amount = money.Zero(posting.Debit.Currency())
+	}
+
+	return Example{Name: entry.Name, Counterparty: entry.Counterparty, Amount: amount, Date: entry.Date, AccountType: c.accountTypes[posting.AccountID]}
+}
+
+const wordPrefix = "word:"
+
+// Words of the name and counterparty, plus which way the money went, roughly how much, and the day of the week.
+func features(e Example) []string {
+	fs := []string{}
+	for _, word := range strings.FieldsFunc(strings.ToLower(e.Name+" "+e.Counterparty), func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+		// single letters and numbers (e.g. receipt numbers) say little about where the money went
+		if len(word) < 2 || !strings.ContainsFunc(word, unicode.IsLetter) {
+			continue
+		}
+
+		fs = append(fs, wordPrefix+word)
+	}
+
+	direction := "direction:in"
+	if e.Amount.IsNegative() {
+		direction = "direction:out"
+	}
+
+	return append(fs, direction, amountBucket(e.Amount), "weekday:"+e.Date.Weekday().String())
+}
+
+// Upper bounds of amount buckets, in whole units of currency.
+var amountBuckets = []int64{5, 10, 20, 50, 100, 200, 500, 1000}
+
+func amountBucket(amount money.Amount) string {
+	units := amount.Micros() / money.MicrosPerUnit
+	if units < 0 {
+		units = -units
+	}
+
+	for _, bound := range amountBuckets {
+		if units < bound {
+			return "amount:<" + strconv.FormatInt(bound, 10)
+		}
+	}
+
+	return "amount:>=" + strconv.FormatInt(amountBuckets[len(amountBuckets)-1], 10)
+}
@@ -0,0 +1,175 @@
+package classify_test
+
+import (
+	"log/slog"
+	"personal-finance/pkgs/classify"
+	domain "personal-finance/pkgs/domains"
+	"personal-finance/pkgs/ingest"
+	"personal-finance/pkgs/money"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// A ledger with a few weeks of groceries and rides booked, and some uncategorised spending.
+func newLedger(t *testing.T) *domain.InMemoryAccountingRepository {
+	t.Helper()
+
+	ctx := t.Context()
+	repo := domain.NewInMemoryAccountingRepository()
+
+	saturday := time.Date(2025, 10, 4, 0, 0, 0, 0, time.UTC)
+	for week := range 4 {
+		date := saturday.AddDate(0, 0, 7*week)
+		for _, param := range []domain.CreateExpenseParams{
+			{Name: "GROCERY_CHAIN_A", TransactedAt: date, Debit: money.MustParse("84.20", money.SGD), CategoryAccountID: domain.AccountID_Expense_Groceries},
+			{Name: "RIDE_HAILING_A", TransactedAt: date.AddDate(0, 0, 2), Debit: money.MustParse("14.70", money.SGD), CategoryAccountID: domain.AccountID_Expense_Transportation},
+			{Name: "HAWKER_STALL_A", TransactedAt: date.AddDate(0, 0, 3), Debit: money.MustParse("5.50", money.SGD), CategoryAccountID: domain.AccountID_Expense_DiningOut},
+		} {
+			require.NoError(t, repo.CreateExpense(ctx, param))
+		}
+	}
+
+	// uncategorised
+	require.NoError(t, repo.CreateExpense(ctx, domain.CreateExpenseParams{Name: "GROCERY_CHAIN_A", TransactedAt: saturday.AddDate(0, 1, 0), Debit: money.MustParse("62.10", money.SGD)}))
+	require.NoError(t, repo.CreateExpense(ctx, domain.CreateExpenseParams{Name: "SOMETHING_NEW", TransactedAt: saturday.AddDate(0, 1, 0), Debit: money.MustParse("1", money.SGD)}))
+
+	return repo
+}
+
+func TestClassifier(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	repo := newLedger(t)
+
+	entries, err := repo.ListJournalEntries(ctx)
+	require.NoError(t, err)
+	accounts, err := repo.ListAccounts(ctx)
+	require.NoError(t, err)
+
+	classifier := classify.Train(entries, accounts)
+
+	prediction, ok := classifier.Predict(classify.Example{Name: "HAWKER_STALL_A", Amount: money.MustParse("-6", money.SGD), Date: time.Date(2025, 11, 11, 0, 0, 0, 0, time.UTC), AccountType: domain.AccountType_Expense})
+	require.True(t, ok)
+	require.EqualValues(t, domain.AccountID_Expense_DiningOut, prediction.AccountID)
+	require.Greater(t, prediction.Confidence, 0.9)
+	require.LessOrEqual(t, prediction.Confidence, 1.0)
+
+	// never seen any of its words
+	_, ok = classifier.Predict(classify.Example{Name: "SOMETHING_NEW", Amount: money.MustParse("-6", money.SGD), AccountType: domain.AccountType_Expense})
+	require.False(t, ok)
+
+	suggestions := classifier.Suggest(entries, accounts)
+	require.Len(t, suggestions, 1)
+	require.Equal(t, "GROCERY_CHAIN_A", suggestions[0].Entry.Name)
+	require.EqualValues(t, domain.AccountID_Expense_Uncategorized, suggestions[0].Posting.AccountID)
+	require.EqualValues(t, domain.AccountID_Expense_Groceries, suggestions[0].Prediction.AccountID)
+}
+
+func TestClassifierNeedsChoices(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	repo := domain.NewInMemoryAccountingRepository()
+	require.NoError(t, repo.CreateExpense(ctx, domain.CreateExpenseParams{Name: "GROCERY_CHAIN_A", Debit: money.MustParse("84.20", money.SGD), CategoryAccountID: domain.AccountID_Expense_Groceries}))
+
+	entries, err := repo.ListJournalEntries(ctx)
+	require.NoError(t, err)
+
+	// everything would be groceries with full confidence
+	classifier := classify.Train(entries, domain.DefaultChartOfAccounts())
+	_, ok := classifier.Predict(classify.Example{Name: "GROCERY_CHAIN_A", AccountType: domain.AccountType_Expense})
+	require.False(t, ok)
+}
+
+func TestAutoCategorizer(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	repo := newLedger(t)
+
+	entries, err := repo.ListJournalEntries(ctx)
+	require.NoError(t, err)
+
+	classifier := classify.Train(entries, domain.DefaultChartOfAccounts())
+	tx := ingest.Transaction{Description: "RIDE_HAILING_A       SINGAPORE     SG", Name: "RIDE_HAILING_A", Amount: money.MustParse("-12.30", money.SGD), Date: time.Date(2025, 11, 3, 0, 0, 0, 0, time.UTC)}
+
+	category, ok := classify.AutoCategorizer{Classifier: classifier, Threshold: 0.8}.Categorize(tx, "dbs")
+	require.True(t, ok)
+	require.Equal(t, ingest.Category{AccountID: domain.AccountID_Expense_Transportation}, category)
+
+	_, ok = classify.AutoCategorizer{Classifier: classifier, Threshold: 1}.Categorize(tx, "dbs")
+	require.False(t, ok)
+}
+
+func TestClassifierKeepsDirection(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	repo := domain.NewInMemoryAccountingRepository()
+
+	date := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+	for month := range 4 {
+		require.NoError(t, repo.CreateIncome(ctx, domain.CreateIncomeParams{Name: "FAST PAYMENT COMPANY_A", TransactedAt: date.AddDate(0, month, 0), Credit: money.MustParse("4,811.73", money.SGD), CategoryAccountID: domain.AccountID_Income_SalaryWages}))
+	}
+	require.NoError(t, repo.CreateIncome(ctx, domain.CreateIncomeParams{Name: "INTEREST CREDIT", TransactedAt: date, Credit: money.MustParse("1.20", money.SGD), CategoryAccountID: domain.AccountID_Income_InterestIncome}))
+	require.NoError(t, repo.CreateExpense(ctx, domain.CreateExpenseParams{Name: "GROCERY_CHAIN_A", TransactedAt: date, Debit: money.MustParse("84.20", money.SGD), CategoryAccountID: domain.AccountID_Expense_Groceries}))
+
+	entries, err := repo.ListJournalEntries(ctx)
+	require.NoError(t, err)
+	classifier := classify.Train(entries, domain.DefaultChartOfAccounts())
+
+	// money coming in is only ever income...
+	prediction, ok := classifier.Predict(classify.Example{Name: "FAST PAYMENT COMPANY_A", Amount: money.MustParse("4,811.73", money.SGD), Date: date, AccountType: domain.AccountType_Income})
+	require.True(t, ok)
+	require.EqualValues(t, domain.AccountID_Income_SalaryWages, prediction.AccountID)
+
+	// ...and money going out only ever spending - there's only groceries to choose from, so no prediction
+	_, ok = classifier.Predict(classify.Example{Name: "FAST PAYMENT COMPANY_A", Amount: money.MustParse("-30", money.SGD), Date: date, AccountType: domain.AccountType_Expense})
+	require.False(t, ok)
+
+	// so it's left uncategorised rather than booked as income
+	slogger := slog.New(slog.NewTextHandler(new(strings.Builder), &slog.HandlerOptions{}))
+	statement := ingest.Statement{Transactions: []ingest.Transaction{{Date: date, Description: "FAST PAYMENT COMPANY_A", Amount: money.MustParse("-30", money.SGD)}}}
+	_, err = ingest.Import(ctx, slogger, repo, statement, ingest.Options{Parser: "ocbc", Fallback: classify.AutoCategorizer{Classifier: classifier, Threshold: 0.6}})
+	require.NoError(t, err)
+
+	entries, err = repo.ListJournalEntries(ctx)
+	require.NoError(t, err)
+	require.EqualValues(t, domain.AccountID_Expense_Uncategorized, entries[len(entries)-1].Postings[0].AccountID)
+}
+
+func TestClassifierRefunds(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	repo := newLedger(t)
+
+	// a refund brings money in, but is booked to an expense account
+	date := time.Date(2025, 11, 10, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, repo.CreateExpense(ctx, domain.CreateExpenseParams{Name: "RIDE_HAILING_A", TransactedAt: date, Credit: money.MustParse("14.70", money.SGD)}))
+
+	entries, err := repo.ListJournalEntries(ctx)
+	require.NoError(t, err)
+	accounts, err := repo.ListAccounts(ctx)
+	require.NoError(t, err)
+
+	classifier := classify.Train(entries, accounts)
+	suggestions := classifier.Suggest(entries, accounts)
+	require.Len(t, suggestions, 2)
+	require.Equal(t, "RIDE_HAILING_A", suggestions[1].Entry.Name)
+	require.EqualValues(t, domain.AccountID_Expense_Transportation, suggestions[1].Prediction.AccountID)
+
+	// so it's booked to one when it's imported
+	slogger := slog.New(slog.NewTextHandler(new(strings.Builder), &slog.HandlerOptions{}))
+	statement := ingest.Statement{Transactions: []ingest.Transaction{{Date: date, Description: "RIDE_HAILING_A", Amount: money.MustParse("9.80", money.SGD), Type: ingest.TransactionType_Refund}}}
+	_, err = ingest.Import(ctx, slogger, repo, statement, ingest.Options{Parser: "dbs", Fallback: classify.AutoCategorizer{Classifier: classifier, Threshold: 0.6}})
+	require.NoError(t, err)
+
+	entries, err = repo.ListJournalEntries(ctx)
+	require.NoError(t, err)
+	require.EqualValues(t, domain.AccountID_Expense_Transportation, entries[len(entries)-1].Postings[0].AccountID)
+}
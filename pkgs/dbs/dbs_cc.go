@@ -2,6 +2,10 @@ package dbs
 
 import (
 	"fmt"
+	domain "personal-finance/pkgs/domains"
+	"personal-finance/pkgs/ingest"
+	"personal-finance/pkgs/money"
+	"strings"
 	"time"
 
 	gocsv "github.com/JoelLau/go-csv"
@@ -10,23 +14,166 @@ import (
 // Represents a single line in DBS's credit card statement (csv)
 // NOTE: Row's DebitAmount OR CreditAmount must be 0. One of them MUST have a value.
 type CreditCardItem struct {
-	TransactionDate        DBSCreditCardDate `csv:"Transaction Date"`         // e.g. "22-Oct-25"
-	TransactionPostingDate DBSCreditCardDate `csv:"Transaction Posting Date"` // e.g. "23-Oct-25"
-	TransactionDescription string            `csv:"Transaction Description"`  // e.g. "SUPER SIMPLE           SINGAPORE     SG"
-	PaymentType            string            `csv:"Payment Type"`             // e.g. ""Contactless", "Online/In-App Payment"
-	TransactionStatus      string            `csv:"Transaction Status"`       // e.g. "Settled"
-	DebitAmount            string            `csv:"Debit Amount"`             // e.g. "2.94"
-	CreditAmount           string            `csv:"Credit Amount"`            // e.g. ""
+	TransactionDate        DBSCreditCardDate   `csv:"Transaction Date"`         // e.g. "22-Oct-25"
+	TransactionPostingDate DBSCreditCardDate   `csv:"Transaction Posting Date"` // e.g. "23-Oct-25"
+	TransactionDescription string              `csv:"Transaction Description"`  // e.g. "SUPER SIMPLE           SINGAPORE     SG"
+	TransactionType        string              `csv:"Transaction Type"`         // e.g. "PURCHASE", "REFUND", "PAYMENT" (see TransactionType)
+	PaymentType            string              `csv:"Payment Type"`             // e.g. ""Contactless", "Online/In-App Payment"
+	TransactionStatus      string              `csv:"Transaction Status"`       // e.g. "Settled", "Pending" (see TransactionPending)
+	DebitAmount            DBSCreditCardAmount `csv:"Debit Amount"`             // e.g. "2.94"
+	CreditAmount           DBSCreditCardAmount `csv:"Credit Amount"`            // e.g. ""
 }
 
 const DBSCreditCardDateLayout = "02 Jan 2006"
 
+func init() {
+	ingest.Register(CreditCardStatementParser{})
+}
+
+// Reads DBS credit card statements - registered with pkgs/ingest as "dbs".
+type CreditCardStatementParser struct{}
+
+var _ ingest.StatementParser = CreditCardStatementParser{}
+
+func (CreditCardStatementParser) Name() string {
+	return "dbs"
+}
+
+func (CreditCardStatementParser) Description() string {
+	return "DBS credit card statement"
+}
+
+func (CreditCardStatementParser) AccountID() int64 {
+	return domain.AccountID_Liability_CreditCard
+}
+
+// First cell of the preamble row naming the card, e.g. "Card Transaction Details For:,CARD_TYPE CARD_ID"
+const creditCardPreambleMarker = "Card Transaction Details For:"
+
+func (CreditCardStatementParser) Detect(table [][]string) bool {
+	headerRow := ingest.FindHeaderRow(table, ingest.Columns[CreditCardItem]())
+	return headerRow >= 0 && ingest.HasPreamble(table, headerRow, creditCardPreambleMarker)
+}
+
+func (CreditCardStatementParser) Parse(table [][]string) (ingest.Statement, error) {
+	txs, err := ParseCreditCardStatement(table)
+	if err != nil {
+		return ingest.Statement{}, err
+	}
+
+	return ingest.Statement{Metadata: ParseCreditCardStatementMetadata(table), Transactions: txs}, nil
+}
+
+// Reads the preamble of a DBS credit card statement, e.g.
+//
+//	Card Transaction Details For:,DBS Altitude Visa Signature Card 4119-1100-0000-1234
+//	Transactions as at:,22 Oct 2025
+//	Credit Limit:,"SGD 12,000.00"
+//	Available Limit:,"SGD 11,748.10"
+func ParseCreditCardStatementMetadata(table [][]string) domain.StatementMetadata {
+	headerRow := ingest.FindHeaderRow(table, ingest.Columns[CreditCardItem]())
+
+	metadata := domain.StatementMetadata{}
+	if card, ok := ingest.PreambleValue(table, headerRow, creditCardPreambleMarker); ok {
+		metadata.AccountRef = ingest.MaskAccountRef(card)
+	}
+	if asOf, ok := ingest.PreambleValue(table, headerRow, "Transactions as at:"); ok {
+		metadata.AsOf = ingest.MetadataDate(asOf, DBSCreditCardDateLayout, DBSCreditCardDateLayoutShortYear)
+	}
+	if limit, ok := ingest.PreambleValue(table, headerRow, "Credit Limit:"); ok {
+		metadata.CreditLimit = ingest.MetadataAmount(limit, money.SGD)
+	}
+	if limit, ok := ingest.PreambleValue(table, headerRow, "Available Limit:"); ok {
+		metadata.AvailableBalance = ingest.MetadataAmount(limit, money.SGD)
+	}
+
+	return metadata
+}
+
+// Parses the table of a DBS credit card statement csv (see ingest.ReadTable) into transactions.
+// The rows above the header contain metadata like the credit card number.
+// this is sensitive information that we want nothing to do with.
+func ParseCreditCardStatement(table [][]string) ([]ingest.Transaction, error) {
+	rows, sources, err := ingest.UnmarshalStatement[CreditCardItem](table)
+	if err != nil {
+		return nil, err
+	}
+
+	txs := make([]ingest.Transaction, len(rows))
+	for idx, row := range rows {
+		if row.DebitAmount.IsZero() == row.CreditAmount.IsZero() {
+			return nil, fmt.Errorf("transaction (%d, %s) must have either a debit (%s) or a credit (%s)", idx, row.TransactionDescription, row.DebitAmount.Amount, row.CreditAmount.Amount)
+		}
+
+		// debits are money out (purchases), credits are money in (refunds and bill payments)
+		amount, err := row.CreditAmount.Sub(row.DebitAmount.Amount)
+		if err != nil {
+			return nil, fmt.Errorf("error computing amount of transaction (%d, %s): %w", idx, row.TransactionDescription, err)
+		}
+
+		txs[idx] = ingest.Transaction{
+			Date:        row.TransactionDate.Time,
+			PostingDate: row.TransactionPostingDate.Time,
+			Description: row.TransactionDescription,
+			Name:        merchants.Normalize(row.TransactionDescription).Name,
+			Amount:      amount,
+			Type:        TransactionType(row.TransactionType),
+			Pending:     TransactionPending(row.TransactionStatus),
+			Source:      sources[idx],
+			PaymentType: row.PaymentType,
+		}
+	}
+
+	return txs, nil
+}
+
+// Names card transactions after their merchants - aliases are up to the caller (see MerchantNormalizer.Rename).
+var merchants = NewMerchantNormalizer(nil)
+
+// Maps DBS's "Transaction Type" column onto how the transaction should be booked.
+// Types we don't know (e.g. fees, interest) are left to be booked by the sign of their amount.
+func TransactionType(s string) ingest.TransactionType {
+	switch strings.ToUpper(strings.TrimSpace(s)) {
+	case "PURCHASE", "INSTALMENT":
+		return ingest.TransactionType_Purchase
+	case "REFUND", "REVERSAL":
+		return ingest.TransactionType_Refund
+	case "PAYMENT", "BILL PAYMENT":
+		return ingest.TransactionType_Payment
+	default:
+		return ingest.TransactionType_Unknown
+	}
+}
+
+// Whether DBS's "Transaction Status" column says the transaction is an authorisation that hasn't settled yet.
+// Anything else (including a blank status) is taken to be settled.
+func TransactionPending(status string) bool {
+	switch strings.ToUpper(strings.TrimSpace(status)) {
+	case "PENDING", "AUTHORISED", "AUTHORIZED", "AUTHORISATION", "AUTHORIZATION":
+		return true
+	default:
+		return false
+	}
+}
+
+// Layout of the date columns in the card's csv export, e.g. "22 Oct 25" or "9 Oct 25"
+const DBSCreditCardDateLayoutShortYear = "2 Jan 06"
+
+// Date column - blank cells (e.g. the posting date of a pending transaction) are the zero time.
 type DBSCreditCardDate struct{ time.Time }
 
 var _ gocsv.CSVUnmarshaller = &DBSCreditCardDate{}
 
 func (d *DBSCreditCardDate) UnmarshalCSV(data []byte) (err error) {
+	if strings.TrimSpace(string(data)) == "" {
+		d.Time = time.Time{}
+		return
+	}
+
 	d.Time, err = time.Parse(DBSCreditCardDateLayout, string(data))
+	if err != nil {
+		d.Time, err = time.Parse(DBSCreditCardDateLayoutShortYear, string(data))
+	}
 	if err != nil {
 		err = fmt.Errorf("failed to parse dbs date: %v", err)
 		return
@@ -34,3 +181,23 @@ func (d *DBSCreditCardDate) UnmarshalCSV(data []byte) (err error) {
 
 	return
 }
+
+// Amount column in SGD - blank cells are zero.
+type DBSCreditCardAmount struct{ money.Amount }
+
+var _ gocsv.CSVUnmarshaller = &DBSCreditCardAmount{}
+
+func (a *DBSCreditCardAmount) UnmarshalCSV(data []byte) (err error) {
+	if strings.TrimSpace(string(data)) == "" {
+		a.Amount = money.Zero(money.SGD)
+		return
+	}
+
+	a.Amount, err = money.Parse(string(data), money.SGD)
+	if err != nil {
+		err = fmt.Errorf("failed to parse dbs amount: %w", err)
+		return
+	}
+
+	return
+}
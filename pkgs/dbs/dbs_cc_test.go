@@ -2,6 +2,8 @@ package dbs_test
 
 import (
 	"personal-finance/pkgs/dbs"
+	"personal-finance/pkgs/ingest"
+	"personal-finance/pkgs/money"
 	"testing"
 	"time"
 
@@ -30,3 +32,147 @@ func TestDBSCreditCardDate_UnmarshalCSVError(t *testing.T) {
 	err := d.UnmarshalCSV([]byte("13/12/2025")) // wrong format
 	require.Error(t, err)
 }
+
+func TestDBSCreditCardAmount_UnmarshalCSV(t *testing.T) {
+	t.Parallel()
+
+	a := dbs.DBSCreditCardAmount{}
+
+	err := a.UnmarshalCSV([]byte("14.7"))
+	require.NoError(t, err)
+	require.Equal(t, money.New(14_700_000, money.SGD), a.Amount)
+
+	err = a.UnmarshalCSV([]byte(""))
+	require.NoError(t, err)
+	require.True(t, a.IsZero())
+
+	err = a.UnmarshalCSV([]byte("SGD14.70")) // wrong format
+	require.Error(t, err)
+}
+
+func TestDBSCreditCardDate_UnmarshalCSVShortYear(t *testing.T) {
+	t.Parallel()
+
+	want := time.Date(2025, 10, 22, 0, 0, 0, 0, time.UTC)
+	d := dbs.DBSCreditCardDate{}
+
+	err := d.UnmarshalCSV([]byte("22 Oct 25"))
+	require.NoError(t, err)
+	require.True(t, want.Equal(d.Time), "want %+v, have %+v", want, d.Time)
+}
+
+func TestDBSCreditCardDate_UnmarshalCSVSingleDigitDay(t *testing.T) {
+	t.Parallel()
+
+	want := time.Date(2025, 10, 9, 0, 0, 0, 0, time.UTC)
+	d := dbs.DBSCreditCardDate{}
+
+	err := d.UnmarshalCSV([]byte("9 Oct 25"))
+	require.NoError(t, err)
+	require.True(t, want.Equal(d.Time), "want %+v, have %+v", want, d.Time)
+}
+
+func TestCreditCardStatementParser(t *testing.T) {
+	t.Parallel()
+
+	table, err := ingest.ReadTable("../../tests/testdata/dbs.csv")
+	require.NoError(t, err)
+
+	parser, err := ingest.LookupParser("dbs")
+	require.NoError(t, err)
+	require.True(t, parser.Detect(table))
+
+	statement, err := parser.Parse(table)
+	require.NoError(t, err)
+	require.Len(t, statement.Transactions, 97)
+	for _, tx := range statement.Transactions {
+		require.Equal(t, ingest.TransactionType_Purchase, tx.Type)
+	}
+
+	ocbcTable, err := ingest.ReadTable("../../tests/testdata/ocbc.csv")
+	require.NoError(t, err)
+	require.False(t, parser.Detect(ocbcTable))
+}
+
+func TestParseCreditCardStatementMetadata(t *testing.T) {
+	t.Parallel()
+
+	metadata := dbs.ParseCreditCardStatementMetadata([][]string{
+		{"Card Transaction Details For:", "DBS Altitude Visa Signature Card 4119-1100-0000-1234"},
+		{"Transactions as at:", "22 Oct 2025"},
+		{"", ""},
+		{"Credit Limit:", "SGD 12,000.00"},
+		{"Available Limit:", "SGD 11,748.10"},
+		{"", ""},
+		{"Transaction Date", "Transaction Posting Date", "Transaction Description", "Transaction Type", "Payment Type", "Transaction Status", "Debit Amount", "Credit Amount"},
+	})
+
+	limit := money.MustParse("12,000.00", money.SGD)
+	available := money.MustParse("11,748.10", money.SGD)
+	require.Equal(t, "****1234", metadata.AccountRef)
+	require.Equal(t, time.Date(2025, 10, 22, 0, 0, 0, 0, time.UTC), metadata.AsOf)
+	require.Equal(t, &limit, metadata.CreditLimit)
+	require.Equal(t, &available, metadata.AvailableBalance)
+	require.Nil(t, metadata.LedgerBalance)
+}
+
+func TestParseCreditCardStatementTransactionTypes(t *testing.T) {
+	t.Parallel()
+
+	txs, err := dbs.ParseCreditCardStatement([][]string{
+		{"Card Transaction Details For:", "CARD_TYPE_A CARD_ID_001"},
+		{"Transaction Date", "Transaction Posting Date", "Transaction Description", "Transaction Type", "Payment Type", "Transaction Status", "Debit Amount", "Credit Amount"},
+		{"22 Oct 25", "23 Oct 25", "MERCHANT_A           SINGAPORE     SG", "PURCHASE", "Contactless", "Settled", "12.4", ""},
+		{"23 Oct 25", "24 Oct 25", "MERCHANT_A           SINGAPORE     SG", "REFUND", "Contactless", "Settled", "", "12.4"},
+		{"24 Oct 25", "24 Oct 25", "PAYMENT - DBS INTERNET/WIRELESS", "PAYMENT", "", "Settled", "", "500"},
+		{"25 Oct 25", "25 Oct 25", "LATE CHARGE", "FEE", "", "Settled", "100", ""},
+	})
+	require.NoError(t, err)
+
+	want := []ingest.TransactionType{
+		ingest.TransactionType_Purchase,
+		ingest.TransactionType_Refund,
+		ingest.TransactionType_Payment,
+		ingest.TransactionType_Unknown,
+	}
+	require.Len(t, txs, len(want))
+	for idx, tx := range txs {
+		require.Equal(t, want[idx], tx.Type, tx.Description)
+	}
+	require.Equal(t, money.MustParse("500", money.SGD), txs[2].Amount)
+}
+
+func TestParseCreditCardStatementNeedsAnAmount(t *testing.T) {
+	t.Parallel()
+
+	for _, amounts := range [][]string{{"", ""}, {"0.00", ""}, {"12.4", "12.4"}} {
+		_, err := dbs.ParseCreditCardStatement([][]string{
+			{"Card Transaction Details For:", "CARD_TYPE_A CARD_ID_001"},
+			{"Transaction Date", "Transaction Posting Date", "Transaction Description", "Transaction Type", "Payment Type", "Transaction Status", "Debit Amount", "Credit Amount"},
+			{"22 Oct 25", "23 Oct 25", "MERCHANT_A           SINGAPORE     SG", "PURCHASE", "Contactless", "Settled", "12.4", ""},
+			{"23 Oct 25", "", "CARD VERIFICATION", "PURCHASE", "Online/In-App Payment", "Pending", amounts[0], amounts[1]},
+		})
+		require.ErrorContains(t, err, "transaction (1, CARD VERIFICATION) must have either a debit", amounts)
+	}
+}
+
+func TestParseCreditCardStatementTransactionStatus(t *testing.T) {
+	t.Parallel()
+
+	txs, err := dbs.ParseCreditCardStatement([][]string{
+		{"Card Transaction Details For:", "CARD_TYPE_A CARD_ID_001"},
+		{"Transaction Date", "Transaction Posting Date", "Transaction Description", "Transaction Type", "Payment Type", "Transaction Status", "Debit Amount", "Credit Amount"},
+		{"22 Oct 25", "23 Oct 25", "MERCHANT_A           SINGAPORE     SG", "PURCHASE", "Contactless", "Settled", "12.4", ""},
+		{"23 Oct 25", "", "MERCHANT_B", "PURCHASE", "Online/In-App Payment", "Pending", "30", ""},
+		{"23 Oct 25", "", "MERCHANT_C", "PURCHASE", "Online/In-App Payment", "authorised", "5", ""},
+		{"24 Oct 25", "24 Oct 25", "MERCHANT_D", "PURCHASE", "Contactless", "", "7", ""},
+	})
+	require.NoError(t, err)
+
+	want := []bool{false, true, true, false}
+	require.Len(t, txs, len(want))
+	for idx, tx := range txs {
+		require.Equal(t, want[idx], tx.Pending, tx.Description)
+	}
+	require.True(t, txs[1].PostingDate.IsZero()) // not posted yet
+}
@@ -0,0 +1,169 @@
+package dbs
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"personal-finance/pkgs/ingest"
+	"regexp"
+	"strings"
+)
+
+// Who a card transaction was with, going by its description, e.g. "MERCHANT_A           SINGAPORE     SG".
+// Nothing here is specific to DBS - card networks format descriptions the same way for every issuer.
+type Merchant struct {
+	Name     string // e.g. "MERCHANT_A"
+	City     string // e.g. "SINGAPORE" - blank if the description doesn't say
+	Country  string // ISO 3166 alpha-2 code, e.g. "SG" - blank if the description doesn't say
+	Currency string // ISO 4217 code the merchant charged in, for descriptions that say, e.g. "MYR"
+}
+
+// Prefixes payment processors put in front of the names of the merchants they take payments for, e.g. "GRAB*MERCHANT_A".
+var DefaultProcessorPrefixes = []string{"GRAB*", "GRAB *", "SQ *", "SQ*", "PAYPAL *", "PAYPAL*", "TST* ", "TST*", "SP *", "SP*", "SUMUP *", "IZ *"}
+
+// Maps merchant names matching Pattern to Name, so the variants of one merchant read the same.
+// Pattern is matched case-insensitively against the whole name, and "*" in it matches anything - e.g. "GRAB RIDE*".
+type MerchantAlias struct {
+	Pattern string
+	Name    string
+}
+
+// Reads merchants out of card descriptions (see Merchant) and gives them the names in an alias table.
+type MerchantNormalizer struct {
+	prefixes []string
+	aliases  []compiledAlias
+}
+
+type compiledAlias struct {
+	pattern *regexp.Regexp
+	name    string
+}
+
+// Strips DefaultProcessorPrefixes and renames merchants by aliases - the first matching alias wins.
+func NewMerchantNormalizer(aliases []MerchantAlias) MerchantNormalizer {
+	n := MerchantNormalizer{prefixes: DefaultProcessorPrefixes, aliases: make([]compiledAlias, 0, len(aliases))}
+	for _, alias := range aliases {
+		pattern := strings.ReplaceAll(regexp.QuoteMeta(strings.TrimSpace(alias.Pattern)), `\*`, `.*`)
+		n.aliases = append(n.aliases, compiledAlias{pattern: regexp.MustCompile(`(?i)^` + pattern + `$`), name: alias.Name})
+	}
+
+	return n
+}
+
+var (
+	// name, city and country are padded with runs of spaces
+	merchantColumnSeparator = regexp.MustCompile(`\s{2,}`)
+
+	// e.g. "... SG" or "... MY MYR"
+	merchantCountry = regexp.MustCompile(`^(.*\S)\s+([A-Z]{2})(?:\s+([A-Z]{3}))?$`)
+)
+
+// Splits description into merchant name, city and country, strips payment processor prefixes from the name,
+// and renames it if it matches an alias.
+func (n MerchantNormalizer) Normalize(description string) Merchant {
+	merchant := ParseMerchant(description)
+
+	upper := strings.ToUpper(merchant.Name)
+	for _, prefix := range n.prefixes {
+		if strings.HasPrefix(upper, prefix) && len(merchant.Name) > len(prefix) {
+			merchant.Name = strings.TrimSpace(merchant.Name[len(prefix):])
+			break
+		}
+	}
+
+	merchant.Name = n.Canonical(merchant.Name)
+	return merchant
+}
+
+// The alias of name, or name if it has none.
+func (n MerchantNormalizer) Canonical(name string) string {
+	for _, alias := range n.aliases {
+		if alias.pattern.MatchString(name) {
+			return alias.name
+		}
+	}
+
+	return name
+}
+
+// Names each transaction after its canonical merchant (see Canonical), e.g. after a statement from any bank is parsed.
+// Transactions that haven't been named are matched by their description.
+func (n MerchantNormalizer) Rename(txs []ingest.Transaction) {
+	for idx, t := range txs {
+		name := t.Name
+		if name == "" {
+			name = t.Description
+		}
+
+		if canonical := n.Canonical(name); canonical != name {
+			txs[idx].Name = canonical
+		}
+	}
+}
+
+// Splits a card description into merchant name, city and country, as card networks lay them out:
+// the name, city and country are padded into columns, sometimes followed by the currency charged in.
+// Descriptions that aren't laid out like that (e.g. bill payments) are all name.
+func ParseMerchant(description string) Merchant {
+	description = strings.TrimSpace(description)
+	if len(merchantColumnSeparator.FindAllStringIndex(description, -1)) == 0 {
+		return Merchant{Name: strings.Join(strings.Fields(description), " ")}
+	}
+
+	merchant := Merchant{}
+	rest := description
+	if match := merchantCountry.FindStringSubmatch(description); match != nil {
+		rest, merchant.Country, merchant.Currency = match[1], match[2], match[3]
+	}
+
+	columns := merchantColumnSeparator.Split(rest, -1)
+	if len(columns) > 1 && merchant.Country != "" {
+		merchant.City = columns[len(columns)-1]
+		columns = columns[:len(columns)-1]
+	}
+
+	merchant.Name = strings.Join(columns, " ")
+	return merchant
+}
+
+// Reads an alias table (see MerchantAlias) from a csv file with a "pattern,name" header, e.g.
+//
+//	pattern,name
+//	GRAB RIDE*,Grab
+//	TRANSIT_PROVIDER_A,Public Transport
+func ReadMerchantAliases(path string) ([]MerchantAlias, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening merchant aliases '%s': %+v", path, err)
+	}
+	defer func() { _ = f.Close() }()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = 2
+	r.TrimLeadingSpace = true
+
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("error reading header of merchant aliases '%s': %+v", path, err)
+	}
+	if !strings.EqualFold(header[0], "pattern") || !strings.EqualFold(header[1], "name") {
+		return nil, fmt.Errorf("%w: merchant aliases '%s' must start with a 'pattern,name' header, got '%s'", ingest.ErrUnexpectedLayout, path, strings.Join(header, ","))
+	}
+
+	aliases := []MerchantAlias{}
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading merchant aliases '%s': %+v", path, err)
+		}
+
+		aliases = append(aliases, MerchantAlias{Pattern: record[0], Name: record[1]})
+	}
+
+	return aliases, nil
+}
@@ -0,0 +1,85 @@
+package dbs_test
+
+import (
+	"os"
+	"path/filepath"
+	"personal-finance/pkgs/dbs"
+	"personal-finance/pkgs/ingest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMerchant(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]dbs.Merchant{
+		"MERCHANT_A           SINGAPORE     SG":     {Name: "MERCHANT_A", City: "SINGAPORE", Country: "SG"},
+		"RETAIL_CHAIN_A       CITY_B        MY MYR": {Name: "RETAIL_CHAIN_A", City: "CITY_B", Country: "MY", Currency: "MYR"},
+		"DIGITAL_SERVICE_A    SERVICE_ID_001 IE":    {Name: "DIGITAL_SERVICE_A", City: "SERVICE_ID_001", Country: "IE"},
+		"MERCHANT_B  SG":                            {Name: "MERCHANT_B", Country: "SG"},
+		"PAYMENT - DBS  INTERNET/WIRELESS":          {Name: "PAYMENT - DBS INTERNET/WIRELESS"},
+		"BILL PAYMENT":                              {Name: "BILL PAYMENT"},
+	}
+
+	for description, want := range tests {
+		require.Equal(t, want, dbs.ParseMerchant(description), description)
+	}
+}
+
+func TestMerchantNormalizer(t *testing.T) {
+	t.Parallel()
+
+	normalizer := dbs.NewMerchantNormalizer([]dbs.MerchantAlias{
+		{Pattern: "ride_hailing_a*", Name: "Ride Hailing"},
+		{Pattern: "MERCHANT_A", Name: "Merchant A"},
+	})
+
+	tests := map[string]dbs.Merchant{
+		"GRAB*RIDE_HAILING_A 1234    SINGAPORE     SG": {Name: "Ride Hailing", City: "SINGAPORE", Country: "SG"},
+		"RIDE_HAILING_A       SINGAPORE     SG":        {Name: "Ride Hailing", City: "SINGAPORE", Country: "SG"},
+		"SQ *MERCHANT_A       SINGAPORE     SG":        {Name: "Merchant A", City: "SINGAPORE", Country: "SG"},
+		"MERCHANT_AB          SINGAPORE     SG":        {Name: "MERCHANT_AB", City: "SINGAPORE", Country: "SG"},
+		"PAYPAL *DIGITAL_SERVICE_A  SERVICE_ID_001 IE": {Name: "DIGITAL_SERVICE_A", City: "SERVICE_ID_001", Country: "IE"},
+	}
+
+	for description, want := range tests {
+		require.Equal(t, want, normalizer.Normalize(description), description)
+	}
+
+	txs := []ingest.Transaction{
+		{Description: "RIDE_HAILING_A       SINGAPORE     SG", Name: "RIDE_HAILING_A"},
+		{Description: "MERCHANT_A"},
+		{Description: "BILL PAYMENT"},
+	}
+	normalizer.Rename(txs)
+	require.Equal(t, []string{"Ride Hailing", "Merchant A", "BILL PAYMENT"}, []string{txs[0].EntryName(), txs[1].EntryName(), txs[2].EntryName()})
+}
+
+func TestParseCreditCardStatementMerchantName(t *testing.T) {
+	t.Parallel()
+
+	txs, err := dbs.ParseCreditCardStatement([][]string{
+		{"Transaction Date", "Transaction Posting Date", "Transaction Description", "Transaction Type", "Payment Type", "Transaction Status", "Debit Amount", "Credit Amount"},
+		{"13 Dec 2025", "15 Dec 2025", "GRAB*RIDE_HAILING_A  SINGAPORE     SG", "PURCHASE", "Contactless", "Settled", "14.70", ""},
+	})
+	require.NoError(t, err)
+	require.Len(t, txs, 1)
+	require.Equal(t, "GRAB*RIDE_HAILING_A  SINGAPORE     SG", txs[0].Description) // fingerprinted as the bank wrote it
+	require.Equal(t, "RIDE_HAILING_A", txs[0].Name)
+}
+
+func TestReadMerchantAliases(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "aliases.csv")
+	require.NoError(t, os.WriteFile(path, []byte("pattern,name\nGRAB RIDE*, Grab\nTRANSIT_PROVIDER_A,Public Transport\n"), 0o600))
+
+	aliases, err := dbs.ReadMerchantAliases(path)
+	require.NoError(t, err)
+	require.Equal(t, []dbs.MerchantAlias{{Pattern: "GRAB RIDE*", Name: "Grab"}, {Pattern: "TRANSIT_PROVIDER_A", Name: "Public Transport"}}, aliases)
+
+	require.NoError(t, os.WriteFile(path, []byte("merchant,alias\nGRAB RIDE*,Grab\n"), 0o600))
+	_, err = dbs.ReadMerchantAliases(path)
+	require.ErrorIs(t, err, ingest.ErrUnexpectedLayout)
+}
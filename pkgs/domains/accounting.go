@@ -2,17 +2,43 @@ package domain
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"personal-finance/pkgs/money"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 )
 
 type AccountingRepository interface {
+	ChartOfAccountsRepository
+	ImportRepository
+	ReconciliationRepository
+	CounterpartyRepository
+
+	// CreateJournalEntry records an entry and all of its postings.
+	// Entries whose debits and credits don't sum to zero are rejected.
+	CreateJournalEntry(context.Context, CreateJournalEntryParams, []CreatePostingParams) (journalEntryID int64, err error)
 	CreateExpense(context.Context, CreateExpenseParams) error
 	CreateIncome(context.Context, CreateIncomeParams) error
+	ListJournalEntries(context.Context) ([]JournalEntry, error)
 	ListTransactions(context.Context) ([]Expense, error)
+
+	// WithTx runs fn as a single unit of work: everything fn writes through tx is committed if it returns nil,
+	// and discarded if it returns an error. fn must only use tx (not the outer repository) while it runs.
+	// Nested calls behave like savepoints - an inner failure only discards the inner writes.
+	WithTx(ctx context.Context, fn func(tx AccountingRepository) error) error
 }
 
+var (
+	ErrUnbalancedJournalEntry = errors.New("journal entry is not balanced")
+	ErrInvalidPosting         = errors.New("invalid posting")
+	ErrJournalEntryNotFound   = errors.New("journal entry not found")
+	ErrAlreadyReversed        = errors.New("journal entry has already been reversed")
+)
+
 const (
 	// ---
 	// Asset Accounts (What you OWN)
@@ -23,6 +49,7 @@ const (
 	AccountID_Asset_CashOnHand         = 1100 // The physical cash in your wallet.
 	AccountID_Asset_Investments        = 1200 // Brokerage accounts, 401k, or stocks.
 	AccountID_Asset_AccountsReceivable = 1300 // Money people owe you (e.g., a friend you lent $20 to)
+	AccountID_Asset_Clearing           = 1900 // Money on its way between your own accounts (e.g., a card bill paid from the bank account).
 
 	// ---
 	// 2. Liability Accounts (What you OWE)
@@ -44,6 +71,7 @@ const (
 	AccountID_Income_GiftsReceived    = 3300 // Money received for birthdays or holidays.
 	AccountID_Income_SideHustleIncome = 3400 // Freelance or gig economy earnings.
 	AccountID_Income_TaxRefunds       = 3500 // Money returned from the government.
+	AccountID_Income_Uncategorized    = 3900 // Income that hasn't been assigned a category yet.
 
 	// ---
 	// 4. Expense Accounts (Where money GOES)
@@ -57,6 +85,7 @@ const (
 	AccountID_Expense_Transportation = 4500 // Gas, public transit, or car maintenance.
 	AccountID_Expense_Subscriptions  = 4600 // Netflix, Spotify, gym memberships.
 	AccountID_Expense_PersonalCare   = 4700 // Haircuts, toiletries, and clothing.
+	AccountID_Expense_Uncategorized  = 4900 // Spending that hasn't been assigned a category yet.
 
 	// ---
 	// 5. Equity Accounts (Your "Net Worth")
@@ -66,80 +95,142 @@ const (
 	AccountID_Equity_RetainedEarnings     = 5200 // (Automated by most software) This represents the total "profit" or savings you’ve accumulated over time.
 )
 
-// NOTE: use slice index as ID field (hidden from public)
+// NOTE: use slice index as ID field (hidden from public) - offset by 1 for journal entries and imports, so 0 can mean "none"
 type InMemoryAccountingRepository struct {
-	accounts       []LedgerAccount
-	journalEntries []CreateJournalEntryParams
-	postings       []CreatePostingParams
+	mu sync.RWMutex // guards the slices below - exported methods lock, unexported ones expect the caller to
+
+	accounts        []LedgerAccount
+	journalEntries  []CreateJournalEntryParams
+	postings        []CreatePostingParams
+	imports         []Import
+	reconciliations []Reconciliation
+	counterparties  []Counterparty
 }
 
 var _ AccountingRepository = &InMemoryAccountingRepository{}
 
 func NewInMemoryAccountingRepository() *InMemoryAccountingRepository {
 	return &InMemoryAccountingRepository{
-		accounts:       []LedgerAccount{},
-		journalEntries: []CreateJournalEntryParams{},
-		postings:       []CreatePostingParams{},
+		accounts:        DefaultChartOfAccounts(),
+		journalEntries:  []CreateJournalEntryParams{},
+		postings:        []CreatePostingParams{},
+		imports:         []Import{},
+		reconciliations: []Reconciliation{},
+		counterparties:  []Counterparty{},
 	}
 }
 
-// WARN: NOT atomic
-func (repo *InMemoryAccountingRepository) CreateIncome(ctx context.Context, param CreateIncomeParams) error {
-	journalEntryID, err := repo.createJournalEntry(ctx, CreateJournalEntryParams{
-		Name:        param.Name,
-		Description: param.Description,
-		Date:        param.TransactedAt,
-	})
-	if err != nil {
-		return fmt.Errorf("error creating journal entry for income creation: %+v", err)
+// Works on a copy of the repository's state and swaps it in once fn succeeds.
+// Other callers are blocked until fn returns, so transactions are serialised.
+func (repo *InMemoryAccountingRepository) WithTx(_ context.Context, fn func(tx AccountingRepository) error) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	tx := &InMemoryAccountingRepository{
+		accounts:        slices.Clone(repo.accounts),
+		journalEntries:  slices.Clone(repo.journalEntries),
+		postings:        slices.Clone(repo.postings),
+		imports:         slices.Clone(repo.imports),
+		reconciliations: slices.Clone(repo.reconciliations),
+		counterparties:  slices.Clone(repo.counterparties),
+	}
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	repo.accounts = tx.accounts
+	repo.journalEntries = tx.journalEntries
+	repo.postings = tx.postings
+	repo.imports = tx.imports
+	repo.reconciliations = tx.reconciliations
+	repo.counterparties = tx.counterparties
+
+	return nil
+}
+
+func (repo *InMemoryAccountingRepository) CreateJournalEntry(ctx context.Context, entry CreateJournalEntryParams, postings []CreatePostingParams) (journalEntryID int64, err error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if err := ValidatePostings(postings); err != nil {
+		return 0, fmt.Errorf("error validating journal entry '%s': %w", entry.Name, err)
+	}
+
+	accounts := accountsByID(repo.accounts)
+	for _, posting := range postings {
+		if _, ok := accounts[posting.AccountID]; !ok {
+			return 0, fmt.Errorf("error validating journal entry '%s': %w: %d", entry.Name, ErrAccountNotFound, posting.AccountID)
+		}
+	}
+
+	// 0 means it wasn't imported
+	if entry.Provenance.ImportID < 0 || entry.Provenance.ImportID > int64(len(repo.imports)) {
+		return 0, fmt.Errorf("error validating journal entry '%s': %w: %d", entry.Name, ErrImportNotFound, entry.Provenance.ImportID)
 	}
 
-	_, err = repo.createPosting(ctx, CreatePostingParams{
-		Name:             param.Name,
-		Description:      param.Description,
-		CreditInMicroSGD: param.CreditInMicroSGD,
-		DebitInMicroSGD:  param.DebitInMicroSGD,
+	if entry.Reverses != 0 {
+		if err := repo.validateReversal(entry.Reverses); err != nil {
+			return 0, fmt.Errorf("error validating journal entry '%s': %w", entry.Name, err)
+		}
+	}
 
-		JournalEntryID: journalEntryID,
-		AccountID:      AccountID_Income_SalaryWages,
-	})
+	if entry.ReplacedBy < 0 || entry.ReplacedBy > int64(len(repo.journalEntries)) {
+		return 0, fmt.Errorf("error validating journal entry '%s': %w: %d", entry.Name, ErrJournalEntryNotFound, entry.ReplacedBy)
+	}
+
+	entry.Tags = UniqueTags(entry.Tags)
+	journalEntryID, err = repo.createJournalEntry(ctx, entry)
 	if err != nil {
-		return fmt.Errorf("error creating posting for income creation: %+v", err)
+		return 0, fmt.Errorf("error creating journal entry: %+v", err)
+	}
+
+	for _, posting := range postings {
+		posting.JournalEntryID = journalEntryID
+		if _, err := repo.createPosting(ctx, posting); err != nil {
+			return 0, fmt.Errorf("error creating posting for journal entry %d: %+v", journalEntryID, err)
+		}
+	}
+
+	return journalEntryID, nil
+}
+
+func (repo *InMemoryAccountingRepository) CreateIncome(ctx context.Context, param CreateIncomeParams) error {
+	entry, postings := NewIncomeJournalEntry(param)
+	if _, err := repo.CreateJournalEntry(ctx, entry, postings); err != nil {
+		return fmt.Errorf("error creating journal entry for income creation: %w", err)
 	}
 
 	return nil
 }
 
-// WARN: NOT atomic
 func (repo *InMemoryAccountingRepository) CreateExpense(ctx context.Context, param CreateExpenseParams) error {
-	journalEntryID, err := repo.createJournalEntry(ctx, CreateJournalEntryParams{
-		Name:        param.Name,
-		Description: param.Description,
-		Date:        param.TransactedAt,
-	})
-	if err != nil {
-		return fmt.Errorf("error creating journal entry for expense creation: %+v", err)
+	entry, postings := NewExpenseJournalEntry(param)
+	if _, err := repo.CreateJournalEntry(ctx, entry, postings); err != nil {
+		return fmt.Errorf("error creating journal entry for expense creation: %w", err)
 	}
 
-	_, err = repo.createPosting(ctx, CreatePostingParams{
-		Name:             param.Name,
-		Description:      param.Description,
-		CreditInMicroSGD: param.CreditInMicroSGD,
-		DebitInMicroSGD:  param.DebitInMicroSGD,
+	return nil
+}
 
-		JournalEntryID: journalEntryID,
-		AccountID:      AccountID_Asset_BankAccount,
-	})
-	if err != nil {
-		return fmt.Errorf("error creating posting for expense creation: %+v", err)
+// Checks that the journal entry with id exists and nothing has reversed it yet.
+func (repo *InMemoryAccountingRepository) validateReversal(id int64) error {
+	if id < 1 || id > int64(len(repo.journalEntries)) {
+		return fmt.Errorf("%w: %d", ErrJournalEntryNotFound, id)
+	}
+
+	for idx, entry := range repo.journalEntries {
+		if entry.Reverses == id {
+			return fmt.Errorf("%w: %d by %d", ErrAlreadyReversed, id, idx+1)
+		}
 	}
 
 	return nil
 }
 
 func (repo *InMemoryAccountingRepository) createJournalEntry(_ context.Context, param CreateJournalEntryParams) (journalEntryID int64, err error) {
-	journalEntryID = int64(len(repo.journalEntries))
 	repo.journalEntries = append(repo.journalEntries, param)
+	journalEntryID = int64(len(repo.journalEntries))
 
 	return journalEntryID, nil
 }
@@ -151,83 +242,362 @@ func (repo *InMemoryAccountingRepository) createPosting(_ context.Context, param
 	return postingID, nil
 }
 
-func (repo *InMemoryAccountingRepository) ListTransactions(context.Context) ([]Expense, error) {
-	expenseMap := make(map[int64]Expense)
+func (repo *InMemoryAccountingRepository) ListJournalEntries(context.Context) ([]JournalEntry, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	return repo.listJournalEntries()
+}
+
+func (repo *InMemoryAccountingRepository) listJournalEntries() ([]JournalEntry, error) {
+	entries := make([]JournalEntry, len(repo.journalEntries))
 	for idx, param := range repo.journalEntries {
-		journalEntryID := int64(idx)
-		expenseMap[journalEntryID] = Expense{
-			ID:               journalEntryID,
-			Name:             param.Name,
-			Description:      param.Description,
-			TransactedAt:     param.Date,
-			CreditInMicroSGD: 0, // will be populated / computed later
-			DebitInMicroSGD:  0, // will be populated / computed later
-			journalEntryID:   journalEntryID,
-			postingIDs:       make(map[int64]bool),
+		entries[idx] = JournalEntry{
+			ID:           int64(idx) + 1,
+			Name:         param.Name,
+			Description:  param.Description,
+			Date:         param.Date,
+			Postings:     []Posting{},
+			Fingerprint:  param.Fingerprint,
+			ContentHash:  param.ContentHash,
+			Provenance:   param.Provenance,
+			Reverses:     param.Reverses,
+			ReplacedBy:   param.ReplacedBy,
+			Pending:      param.Pending,
+			Counterparty: param.Counterparty,
+			Tags:         slices.Clone(param.Tags),
+		}
+
+		if importID := param.Provenance.ImportID; importID != 0 {
+			entries[idx].Provenance.Parser = repo.imports[importID-1].Parser
+			entries[idx].Provenance.FileSHA256 = repo.imports[importID-1].FileSHA256
 		}
 	}
 
 	for idx, param := range repo.postings {
-		postingID := int64(idx)
-		journalEntryID := int64(param.JournalEntryID)
-
-		expense, ok := expenseMap[journalEntryID]
-		if !ok {
-			return nil, fmt.Errorf("no expense with id %d", journalEntryID)
+		if param.JournalEntryID < 1 || param.JournalEntryID > int64(len(entries)) {
+			return nil, fmt.Errorf("no journal entry with id %d", param.JournalEntryID)
 		}
 
-		expense.postingIDs[postingID] = true
-		expense.CreditInMicroSGD += param.CreditInMicroSGD
-		expense.DebitInMicroSGD += param.DebitInMicroSGD
+		entry := &entries[param.JournalEntryID-1]
+		entry.Postings = append(entry.Postings, Posting{
+			ID:             int64(idx),
+			Name:           param.Name,
+			Description:    param.Description,
+			Credit:         param.Credit,
+			Debit:          param.Debit,
+			AccountID:      param.AccountID,
+			JournalEntryID: param.JournalEntryID,
+		})
+	}
+
+	return entries, nil
+}
+
+func (repo *InMemoryAccountingRepository) ListTransactions(context.Context) ([]Expense, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	entries, err := repo.listJournalEntries()
+	if err != nil {
+		return nil, fmt.Errorf("error listing journal entries: %+v", err)
+	}
+
+	return TransactionsFromJournalEntries(entries, repo.accounts)
+}
 
-		expenseMap[journalEntryID] = expense
-		slog.Info("finished addition", slog.Any("updated expense", expenseMap[journalEntryID]))
+// Summarises journal entries as expenses/income.
+// Only postings to income and expense accounts count towards the amounts -
+// the balance sheet leg of an entry is just the other side of the same money.
+func TransactionsFromJournalEntries(entries []JournalEntry, accounts []LedgerAccount) ([]Expense, error) {
+	accountTypes := make(map[int64]AccountType, len(accounts))
+	for _, account := range accounts {
+		accountTypes[account.ID] = account.Type
 	}
 
-	expenses := make([]Expense, len(expenseMap))
-	idx := 0
-	for _, v := range expenseMap {
-		expenses[idx] = v
-		idx++
+	expenses := make([]Expense, len(entries))
+	for idx, entry := range entries {
+		expense := Expense{
+			ID:             entry.ID,
+			Name:           entry.Name,
+			Description:    entry.Description,
+			TransactedAt:   entry.Date,
+			journalEntryID: entry.ID,
+			postingIDs:     make(map[int64]bool),
+		}
+
+		for _, posting := range entry.Postings {
+			expense.postingIDs[posting.ID] = true
+			if !accountTypes[posting.AccountID].IsIncomeStatement() {
+				continue
+			}
+
+			var err error
+			if expense.Credit, err = expense.Credit.Add(posting.Credit); err != nil {
+				return nil, fmt.Errorf("error summing credits of journal entry %d: %w", entry.ID, err)
+			}
+			if expense.Debit, err = expense.Debit.Add(posting.Debit); err != nil {
+				return nil, fmt.Errorf("error summing debits of journal entry %d: %w", entry.ID, err)
+			}
+		}
+
+		slog.Debug("finished addition", slog.Any("updated expense", expense))
+		expenses[idx] = expense
 	}
 
 	return expenses, nil
 }
 
+// Checks that postings form a valid, balanced journal entry:
+// at least 2 legs, each either a debit or a credit (not both, not neither), no negative amounts, a single currency,
+// and total debits equal total credits.
+func ValidatePostings(postings []CreatePostingParams) error {
+	if len(postings) < 2 {
+		return fmt.Errorf("%w: expected at least 2 postings, got %d", ErrUnbalancedJournalEntry, len(postings))
+	}
+
+	balance := money.Amount{}
+	for idx, posting := range postings {
+		if posting.Debit.IsNegative() || posting.Credit.IsNegative() {
+			return fmt.Errorf("%w: posting #%d has a negative amount", ErrInvalidPosting, idx)
+		}
+		if posting.Debit.IsZero() == posting.Credit.IsZero() {
+			return fmt.Errorf("%w: posting #%d must have either a debit or a credit", ErrInvalidPosting, idx)
+		}
+
+		net, err := posting.Debit.Sub(posting.Credit)
+		if err != nil {
+			return fmt.Errorf("%w: posting #%d: %w", ErrInvalidPosting, idx, err)
+		}
+
+		if balance, err = balance.Add(net); err != nil {
+			return fmt.Errorf("%w: posting #%d: %w", ErrInvalidPosting, idx, err)
+		}
+	}
+
+	if !balance.IsZero() {
+		return fmt.Errorf("%w: debits and credits differ by %s", ErrUnbalancedJournalEntry, balance)
+	}
+
+	return nil
+}
+
 type CreateIncomeParams = CreateExpenseParams
 type Income = Expense
 
+// Amounts are from the point of view of the category account:
+// spending debits the expense account, earning credits the income account.
+// The funding account receives the opposite leg.
 type CreateExpenseParams struct {
-	Name             string
-	Description      string
-	TransactedAt     time.Time
-	CreditInMicroSGD int64
-	DebitInMicroSGD  int64
+	Name         string
+	Description  string
+	TransactedAt time.Time
+	Credit       money.Amount
+	Debit        money.Amount
+
+	CategoryAccountID int64 // defaults to the uncategorized income/expense account
+	FundingAccountID  int64 // defaults to AccountID_Asset_BankAccount
+
+	Fingerprint string // see CreateJournalEntryParams
+	ContentHash string
+	Provenance  Provenance
+	Pending     bool
+
+	Counterparty string // see CreateJournalEntryParams
+	Tags         []string
+}
+
+// Builds the two legs of an expense: category account and funding account.
+func NewExpenseJournalEntry(param CreateExpenseParams) (CreateJournalEntryParams, []CreatePostingParams) {
+	if param.CategoryAccountID == 0 {
+		param.CategoryAccountID = AccountID_Expense_Uncategorized
+	}
+
+	return param.journalEntryParams()
+}
+
+// Builds the two legs of an income: category account and funding account.
+func NewIncomeJournalEntry(param CreateIncomeParams) (CreateJournalEntryParams, []CreatePostingParams) {
+	if param.CategoryAccountID == 0 {
+		param.CategoryAccountID = AccountID_Income_Uncategorized
+	}
+
+	return param.journalEntryParams()
+}
+
+func (param CreateExpenseParams) journalEntryParams() (CreateJournalEntryParams, []CreatePostingParams) {
+	fundingAccountID := param.FundingAccountID
+	if fundingAccountID == 0 {
+		fundingAccountID = AccountID_Asset_BankAccount
+	}
+
+	entry := CreateJournalEntryParams{
+		Name:         param.Name,
+		Description:  param.Description,
+		Date:         param.TransactedAt,
+		Fingerprint:  param.Fingerprint,
+		ContentHash:  param.ContentHash,
+		Provenance:   param.Provenance,
+		Pending:      param.Pending,
+		Counterparty: param.Counterparty,
+		Tags:         param.Tags,
+	}
+
+	return entry, []CreatePostingParams{
+		{
+			Name:        param.Name,
+			Description: param.Description,
+			Credit:      param.Credit,
+			Debit:       param.Debit,
+			AccountID:   param.CategoryAccountID,
+		},
+		{
+			Name:        param.Name,
+			Description: param.Description,
+			Credit:      param.Debit,
+			Debit:       param.Credit,
+			AccountID:   fundingAccountID,
+		},
+	}
+}
+
+// Builds an entry that cancels out entry: the same postings with debits and credits swapped.
+// It has the same date, counterparty, tags and pending status as entry, so reports that include entry net to zero.
+func NewReversalJournalEntry(entry JournalEntry) (CreateJournalEntryParams, []CreatePostingParams) {
+	reversal := CreateJournalEntryParams{
+		Name:         "Reversal: " + entry.Name,
+		Description:  fmt.Sprintf("reverses journal entry %d", entry.ID),
+		Date:         entry.Date,
+		Reverses:     entry.ID,
+		Pending:      entry.Pending,
+		Counterparty: entry.Counterparty,
+		Tags:         slices.Clone(entry.Tags),
+	}
+
+	postings := make([]CreatePostingParams, len(entry.Postings))
+	for idx, posting := range entry.Postings {
+		postings[idx] = CreatePostingParams{
+			Name:        posting.Name,
+			Description: posting.Description,
+			Credit:      posting.Debit,
+			Debit:       posting.Credit,
+			AccountID:   posting.AccountID,
+		}
+	}
+
+	return reversal, postings
+}
+
+// Drops pending entries (and the reversals of pending entries, which are pending too).
+func SettledEntries(entries []JournalEntry) []JournalEntry {
+	settled := make([]JournalEntry, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.Pending {
+			settled = append(settled, entry)
+		}
+	}
+
+	return settled
+}
+
+// The entry's only posting to an income or expense account - false if it has none, or more than one.
+func CategoryPosting(entry JournalEntry, accounts []LedgerAccount) (Posting, bool) {
+	byID := accountsByID(accounts)
+
+	found := []Posting{}
+	for _, posting := range entry.Postings {
+		if byID[posting.AccountID].Type.IsIncomeStatement() {
+			found = append(found, posting)
+		}
+	}
+
+	if len(found) != 1 {
+		return Posting{}, false
+	}
+
+	return found[0], true
+}
+
+// Builds a copy of entry with the postings to fromAccountID booked to toAccountID instead, e.g. to categorise it.
+// The copy keeps the entry's fingerprint and provenance, so it can replace the entry once the entry is reversed.
+func NewRebookedJournalEntry(entry JournalEntry, fromAccountID int64, toAccountID int64) (CreateJournalEntryParams, []CreatePostingParams) {
+	rebooked := CreateJournalEntryParams{
+		Name:         entry.Name,
+		Description:  entry.Description,
+		Date:         entry.Date,
+		Fingerprint:  entry.Fingerprint,
+		ContentHash:  entry.ContentHash,
+		Provenance:   entry.Provenance,
+		Pending:      entry.Pending,
+		Counterparty: entry.Counterparty,
+		Tags:         slices.Clone(entry.Tags),
+	}
+
+	postings := make([]CreatePostingParams, len(entry.Postings))
+	for idx, posting := range entry.Postings {
+		postings[idx] = CreatePostingParams{
+			Name:        posting.Name,
+			Description: posting.Description,
+			Credit:      posting.Credit,
+			Debit:       posting.Debit,
+			AccountID:   posting.AccountID,
+		}
+		if posting.AccountID == fromAccountID {
+			postings[idx].AccountID = toAccountID
+		}
+	}
+
+	return rebooked, postings
+}
+
+// IDs of the entries that reverse others, by the ID of the entry they reverse.
+func Reversals(entries []JournalEntry) map[int64]int64 {
+	reversals := map[int64]int64{}
+	for _, entry := range entries {
+		if entry.Reverses != 0 {
+			reversals[entry.Reverses] = entry.ID
+		}
+	}
+
+	return reversals
 }
 
 type Expense struct {
-	ID               int64
-	Name             string
-	Description      string
-	TransactedAt     time.Time
-	CreditInMicroSGD int64
-	DebitInMicroSGD  int64
+	ID           int64
+	Name         string
+	Description  string
+	TransactedAt time.Time
+	Credit       money.Amount
+	Debit        money.Amount
 
 	journalEntryID int64
 	postingIDs     map[int64]bool
 }
 
-type LedgerAccount struct {
-	ID          int64
-	Name        string
-	Description string
-	ParentID    string
-}
-
 type CreateJournalEntryParams struct {
 	Name        string
 	Description string
 	Date        time.Time
+
+	Fingerprint string // identifies the bank transaction the entry was imported from - "" if it wasn't imported
+	ContentHash string // hash of everything the bank said about the transaction - changes if the bank revises it
+
+	// Only ImportID, Row and RawRow are recorded - the rest comes from the import.
+	Provenance Provenance
+
+	Reverses int64 // ID of the journal entry this one cancels out - 0 if it isn't a reversal (see NewReversalJournalEntry)
+
+	// For reversals: ID of the journal entry that took the reversed entry's place,
+	// e.g. the transfer it was collapsed into (see ingest.CollapseTransfers) or, for a pending entry, its settled version - 0 if nothing did.
+	ReplacedBy int64
+
+	// The bank has authorised the transaction but not settled it, so it may still change or disappear.
+	// Pending entries are left out of reports, and replaced once their settled version is imported.
+	Pending bool
+
+	Counterparty string // who the money went to or came from, as the bank named them (see Counterparty) - blank if unknown
+
+	Tags []string // free-form labels, e.g. "travel" - duplicates are dropped (see UniqueTags)
 }
 
 type JournalEntry struct {
@@ -235,24 +605,58 @@ type JournalEntry struct {
 	Name        string
 	Description string
 	Date        time.Time
+	Postings    []Posting
+
+	Fingerprint string
+	ContentHash string
+	Provenance  Provenance
+	Reverses    int64
+	ReplacedBy  int64
+	Pending     bool
+
+	Counterparty string
+	Tags         []string // in the order they were added
+}
+
+// Tags without blanks or duplicates, in the order they first appear - nil if there are none.
+func UniqueTags(tags []string) []string {
+	var unique []string
+	for _, tag := range tags {
+		if tag = strings.TrimSpace(tag); tag != "" && !slices.Contains(unique, tag) {
+			unique = append(unique, tag)
+		}
+	}
+
+	return unique
+}
+
+// Where an imported journal entry came from - zero for entries that weren't imported.
+type Provenance struct {
+	ImportID int64  // import batch that created the entry
+	Row      int    // csv record of the statement file, starting at 1 - the line number unless a cell spans lines
+	RawRow   string // the record as it was in the file, with account and card numbers redacted
+
+	// from the import
+	Parser     string
+	FileSHA256 string
 }
 
 type CreatePostingParams struct {
-	Name             string
-	Description      string
-	CreditInMicroSGD int64
-	DebitInMicroSGD  int64
+	Name        string
+	Description string
+	Credit      money.Amount
+	Debit       money.Amount
 
 	AccountID      int64
 	JournalEntryID int64
 }
 
 type Posting struct {
-	ID               int64
-	Name             string
-	Description      string
-	CreditInMicroSGD int64
-	DebitInMicroSGD  int64
+	ID          int64
+	Name        string
+	Description string
+	Credit      money.Amount
+	Debit       money.Amount
 
 	AccountID      int64
 	JournalEntryID int64
@@ -0,0 +1,15 @@
+package domain_test
+
+import (
+	domain "personal-finance/pkgs/domains"
+	"personal-finance/pkgs/domains/domaintest"
+	"testing"
+)
+
+func TestInMemoryAccountingRepository(t *testing.T) {
+	t.Parallel()
+
+	domaintest.RunAccountingRepositoryTests(t, func(t *testing.T) domain.AccountingRepository {
+		return domain.NewInMemoryAccountingRepository()
+	})
+}
@@ -0,0 +1,419 @@
+package domain
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"personal-finance/pkgs/money"
+	"slices"
+	"strings"
+)
+
+type ChartOfAccountsRepository interface {
+	CreateAccount(context.Context, CreateAccountParams) (accountID int64, err error)
+	GetAccount(context.Context, int64) (LedgerAccount, error)
+	ListAccounts(context.Context) ([]LedgerAccount, error)
+	UpdateAccount(context.Context, UpdateAccountParams) error
+	DeleteAccount(context.Context, int64) error
+}
+
+var (
+	ErrAccountNotFound = errors.New("account not found")
+	ErrInvalidAccount  = errors.New("invalid account")
+	ErrAccountInUse    = errors.New("account in use")
+)
+
+type AccountType string
+
+const (
+	AccountType_Asset     AccountType = "asset"
+	AccountType_Liability AccountType = "liability"
+	AccountType_Income    AccountType = "income"
+	AccountType_Expense   AccountType = "expense"
+	AccountType_Equity    AccountType = "equity"
+)
+
+var AccountTypes = []AccountType{
+	AccountType_Asset,
+	AccountType_Liability,
+	AccountType_Income,
+	AccountType_Expense,
+	AccountType_Equity,
+}
+
+func ParseAccountType(s string) (AccountType, error) {
+	t := AccountType(strings.ToLower(strings.TrimSpace(s)))
+	if !slices.Contains(AccountTypes, t) {
+		return "", fmt.Errorf("%w: unknown account type '%s'", ErrInvalidAccount, s)
+	}
+
+	return t, nil
+}
+
+// Side of the ledger that increases an account of this type.
+func (t AccountType) NormalBalance() NormalBalance {
+	switch t {
+	case AccountType_Asset, AccountType_Expense:
+		return NormalBalance_Debit
+	default:
+		return NormalBalance_Credit
+	}
+}
+
+// Income and expense accounts make up the income statement (i.e. the "P&L").
+// Everything else is on the balance sheet.
+func (t AccountType) IsIncomeStatement() bool {
+	return t == AccountType_Income || t == AccountType_Expense
+}
+
+// Display name used as the first segment of an account path, e.g. "Expense" in "Expense:DiningOut".
+func (t AccountType) Title() string {
+	if t == "" {
+		return ""
+	}
+
+	return strings.ToUpper(string(t[:1])) + string(t[1:])
+}
+
+type NormalBalance string
+
+const (
+	NormalBalance_Debit  NormalBalance = "debit"
+	NormalBalance_Credit NormalBalance = "credit"
+)
+
+// First ID handed out to top-level accounts of each type (see AccountID_ constants).
+var accountIDRangeStart = map[AccountType]int64{
+	AccountType_Asset:     1000,
+	AccountType_Liability: 2000,
+	AccountType_Income:    3000,
+	AccountType_Expense:   4000,
+	AccountType_Equity:    5000,
+}
+
+// Chart of accounts every new repository starts with - one entry per AccountID_ constant.
+func DefaultChartOfAccounts() []LedgerAccount {
+	return []LedgerAccount{
+		{ID: AccountID_Asset_BankAccount, Name: "BankAccount", Type: AccountType_Asset, Description: "Your primary spending account."},
+		{ID: AccountID_Asset_CashOnHand, Name: "CashOnHand", Type: AccountType_Asset, Description: "The physical cash in your wallet."},
+		{ID: AccountID_Asset_Investments, Name: "Investments", Type: AccountType_Asset, Description: "Brokerage accounts, 401k, or stocks."},
+		{ID: AccountID_Asset_AccountsReceivable, Name: "AccountsReceivable", Type: AccountType_Asset, Description: "Money people owe you."},
+		{ID: AccountID_Asset_Clearing, Name: "Clearing", Type: AccountType_Asset, Description: "Money on its way between your own accounts."},
+
+		{ID: AccountID_Liability_CreditCard, Name: "CreditCard", Type: AccountType_Liability, Description: "Your outstanding balance on a specific card."},
+		{ID: AccountID_Liability_StudentLoan, Name: "StudentLoan", Type: AccountType_Liability, Description: "Long-term education debt."},
+		{ID: AccountID_Liability_MortgageCarLoan, Name: "MortgageCarLoan", Type: AccountType_Liability, Description: "Large installment loans."},
+		{ID: AccountID_Liability_PersonalLoans, Name: "PersonalLoans", Type: AccountType_Liability, Description: "Money you owe to friends or family."},
+
+		{ID: AccountID_Income_SalaryWages, Name: "SalaryWages", Type: AccountType_Income, Description: "Your primary paycheck."},
+		{ID: AccountID_Income_InterestIncome, Name: "InterestIncome", Type: AccountType_Income, Description: "Dividends or interest from bank accounts."},
+		{ID: AccountID_Income_GiftsReceived, Name: "GiftsReceived", Type: AccountType_Income, Description: "Money received for birthdays or holidays."},
+		{ID: AccountID_Income_SideHustleIncome, Name: "SideHustleIncome", Type: AccountType_Income, Description: "Freelance or gig economy earnings."},
+		{ID: AccountID_Income_TaxRefunds, Name: "TaxRefunds", Type: AccountType_Income, Description: "Money returned from the government."},
+		{ID: AccountID_Income_Uncategorized, Name: "Uncategorized", Type: AccountType_Income, Description: "Income that hasn't been assigned a category yet."},
+
+		{ID: AccountID_Expense_Housing, Name: "Housing", Type: AccountType_Expense, Description: "Rent or mortgage interest."},
+		{ID: AccountID_Expense_Groceries, Name: "Groceries", Type: AccountType_Expense, Description: "Food for home."},
+		{ID: AccountID_Expense_DiningOut, Name: "DiningOut", Type: AccountType_Expense, Description: "Restaurants, coffee, and takeout."},
+		{ID: AccountID_Expense_Utilities, Name: "Utilities", Type: AccountType_Expense, Description: "Electricity, water, internet, and phone."},
+		{ID: AccountID_Expense_Transportation, Name: "Transportation", Type: AccountType_Expense, Description: "Gas, public transit, or car maintenance."},
+		{ID: AccountID_Expense_Subscriptions, Name: "Subscriptions", Type: AccountType_Expense, Description: "Netflix, Spotify, gym memberships."},
+		{ID: AccountID_Expense_PersonalCare, Name: "PersonalCare", Type: AccountType_Expense, Description: "Haircuts, toiletries, and clothing."},
+		{ID: AccountID_Expense_Uncategorized, Name: "Uncategorized", Type: AccountType_Expense, Description: "Spending that hasn't been assigned a category yet."},
+
+		{ID: AccountID_Equity_OpeningBalanceEquity, Name: "OpeningBalanceEquity", Type: AccountType_Equity, Description: "Initial money in your accounts when you first start your books."},
+		{ID: AccountID_Equity_RetainedEarnings, Name: "RetainedEarnings", Type: AccountType_Equity, Description: "Total savings accumulated over time."},
+	}
+}
+
+type LedgerAccount struct {
+	ID          int64
+	Name        string // single path segment, e.g. "Coffee"
+	Description string
+	Type        AccountType
+	ParentID    int64 // 0 for top-level accounts
+}
+
+func (a LedgerAccount) NormalBalance() NormalBalance {
+	return a.Type.NormalBalance()
+}
+
+// How much a posting moves the account's balance in its normal direction,
+// e.g. a debit increases an expense account but decreases an income account.
+func (a LedgerAccount) NormalBalanceChange(posting Posting) (money.Amount, error) {
+	change, err := posting.Debit.Sub(posting.Credit)
+	if err != nil {
+		return money.Amount{}, fmt.Errorf("error computing net amount of posting %d: %w", posting.ID, err)
+	}
+
+	if a.NormalBalance() == NormalBalance_Credit {
+		return change.Neg()
+	}
+
+	return change, nil
+}
+
+type CreateAccountParams struct {
+	ID          int64 // optional - next free ID under the parent (or account type) is used if 0
+	Name        string
+	Description string
+	Type        AccountType // optional for sub-accounts - inherited from the parent
+	ParentID    int64
+}
+
+type UpdateAccountParams struct {
+	ID          int64
+	Name        string
+	Description string
+	ParentID    int64
+}
+
+// Full, colon-separated path of an account, e.g. "Expense:DiningOut:Coffee".
+func AccountPath(accounts []LedgerAccount, accountID int64) (string, error) {
+	byID := accountsByID(accounts)
+
+	segments := []string{}
+	seen := map[int64]bool{}
+	account, ok := byID[accountID]
+	for {
+		if !ok {
+			return "", fmt.Errorf("%w: %d", ErrAccountNotFound, accountID)
+		}
+		if seen[account.ID] {
+			return "", fmt.Errorf("%w: account %d has a cyclic parent", ErrInvalidAccount, account.ID)
+		}
+		seen[account.ID] = true
+
+		segments = append(segments, account.Name)
+		if account.ParentID == 0 {
+			break
+		}
+
+		account, ok = byID[account.ParentID]
+	}
+
+	segments = append(segments, account.Type.Title())
+	slices.Reverse(segments)
+
+	return strings.Join(segments, ":"), nil
+}
+
+// Finds an account by its full path (see AccountPath). Matching is case-insensitive.
+func FindAccountByPath(accounts []LedgerAccount, path string) (LedgerAccount, error) {
+	for _, account := range accounts {
+		p, err := AccountPath(accounts, account.ID)
+		if err != nil {
+			return LedgerAccount{}, err
+		}
+
+		if strings.EqualFold(p, strings.TrimSpace(path)) {
+			return account, nil
+		}
+	}
+
+	return LedgerAccount{}, fmt.Errorf("%w: '%s'", ErrAccountNotFound, path)
+}
+
+func accountsByID(accounts []LedgerAccount) map[int64]LedgerAccount {
+	m := make(map[int64]LedgerAccount, len(accounts))
+	for _, account := range accounts {
+		m[account.ID] = account
+	}
+
+	return m
+}
+
+// Validates params against the existing chart of accounts and fills in the defaults (ID, Type).
+// Shared by every ChartOfAccountsRepository implementation so they behave the same.
+func PrepareCreateAccount(accounts []LedgerAccount, param CreateAccountParams) (LedgerAccount, error) {
+	byID := accountsByID(accounts)
+
+	account := LedgerAccount{
+		ID:          param.ID,
+		Name:        strings.TrimSpace(param.Name),
+		Description: param.Description,
+		Type:        param.Type,
+		ParentID:    param.ParentID,
+	}
+
+	start := int64(0)
+	if account.ParentID != 0 {
+		parent, ok := byID[account.ParentID]
+		if !ok {
+			return LedgerAccount{}, fmt.Errorf("%w: parent %d", ErrAccountNotFound, account.ParentID)
+		}
+
+		if account.Type == "" {
+			account.Type = parent.Type
+		}
+		if account.Type != parent.Type {
+			return LedgerAccount{}, fmt.Errorf("%w: %s account can't be a child of %s account %d", ErrInvalidAccount, account.Type, parent.Type, parent.ID)
+		}
+
+		start = parent.ID + 1
+	}
+
+	if !slices.Contains(AccountTypes, account.Type) {
+		return LedgerAccount{}, fmt.Errorf("%w: unknown account type '%s'", ErrInvalidAccount, account.Type)
+	}
+
+	if err := validateAccountName(accounts, account); err != nil {
+		return LedgerAccount{}, err
+	}
+
+	if start == 0 {
+		start = accountIDRangeStart[account.Type]
+	}
+
+	if account.ID == 0 {
+		account.ID = start
+		for _, ok := byID[account.ID]; ok; _, ok = byID[account.ID] {
+			account.ID++
+		}
+	}
+
+	if _, ok := byID[account.ID]; ok {
+		return LedgerAccount{}, fmt.Errorf("%w: account id %d already exists", ErrInvalidAccount, account.ID)
+	}
+
+	return account, nil
+}
+
+// Validates an update against the existing chart of accounts and returns the updated account.
+func PrepareUpdateAccount(accounts []LedgerAccount, param UpdateAccountParams) (LedgerAccount, error) {
+	byID := accountsByID(accounts)
+
+	account, ok := byID[param.ID]
+	if !ok {
+		return LedgerAccount{}, fmt.Errorf("%w: %d", ErrAccountNotFound, param.ID)
+	}
+
+	account.Name = strings.TrimSpace(param.Name)
+	account.Description = param.Description
+	account.ParentID = param.ParentID
+
+	if err := validateAccountName(accounts, account); err != nil {
+		return LedgerAccount{}, err
+	}
+
+	// walk up the new parents to make sure the types match and we don't create a cycle
+	for parentID := account.ParentID; parentID != 0; {
+		parent, ok := byID[parentID]
+		if !ok {
+			return LedgerAccount{}, fmt.Errorf("%w: parent %d", ErrAccountNotFound, parentID)
+		}
+		if parent.ID == account.ID {
+			return LedgerAccount{}, fmt.Errorf("%w: account %d can't be its own ancestor", ErrInvalidAccount, account.ID)
+		}
+		if parent.Type != account.Type {
+			return LedgerAccount{}, fmt.Errorf("%w: %s account can't be a child of %s account %d", ErrInvalidAccount, account.Type, parent.Type, parent.ID)
+		}
+
+		parentID = parent.ParentID
+	}
+
+	return account, nil
+}
+
+func validateAccountName(accounts []LedgerAccount, account LedgerAccount) error {
+	if account.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidAccount)
+	}
+	if strings.Contains(account.Name, ":") {
+		return fmt.Errorf("%w: name '%s' can't contain ':'", ErrInvalidAccount, account.Name)
+	}
+
+	for _, sibling := range accounts {
+		if sibling.ID != account.ID && sibling.ParentID == account.ParentID && sibling.Type == account.Type && strings.EqualFold(sibling.Name, account.Name) {
+			return fmt.Errorf("%w: '%s' already exists (id %d)", ErrInvalidAccount, account.Name, sibling.ID)
+		}
+	}
+
+	return nil
+}
+
+var _ ChartOfAccountsRepository = &InMemoryAccountingRepository{}
+
+func (repo *InMemoryAccountingRepository) CreateAccount(_ context.Context, param CreateAccountParams) (accountID int64, err error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	account, err := PrepareCreateAccount(repo.accounts, param)
+	if err != nil {
+		return 0, fmt.Errorf("error creating account: %w", err)
+	}
+
+	repo.accounts = append(repo.accounts, account)
+
+	return account.ID, nil
+}
+
+func (repo *InMemoryAccountingRepository) GetAccount(_ context.Context, accountID int64) (LedgerAccount, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	account, ok := accountsByID(repo.accounts)[accountID]
+	if !ok {
+		return LedgerAccount{}, fmt.Errorf("%w: %d", ErrAccountNotFound, accountID)
+	}
+
+	return account, nil
+}
+
+func (repo *InMemoryAccountingRepository) ListAccounts(context.Context) ([]LedgerAccount, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	accounts := slices.Clone(repo.accounts)
+	slices.SortFunc(accounts, func(a, b LedgerAccount) int { return cmp.Compare(a.ID, b.ID) })
+
+	return accounts, nil
+}
+
+func (repo *InMemoryAccountingRepository) UpdateAccount(_ context.Context, param UpdateAccountParams) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	account, err := PrepareUpdateAccount(repo.accounts, param)
+	if err != nil {
+		return fmt.Errorf("error updating account: %w", err)
+	}
+
+	idx := slices.IndexFunc(repo.accounts, func(a LedgerAccount) bool { return a.ID == account.ID })
+	repo.accounts[idx] = account
+
+	return nil
+}
+
+func (repo *InMemoryAccountingRepository) DeleteAccount(_ context.Context, accountID int64) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	idx := slices.IndexFunc(repo.accounts, func(a LedgerAccount) bool { return a.ID == accountID })
+	if idx < 0 {
+		return fmt.Errorf("%w: %d", ErrAccountNotFound, accountID)
+	}
+
+	for _, account := range repo.accounts {
+		if account.ParentID == accountID {
+			return fmt.Errorf("%w: account %d has sub-account %d", ErrAccountInUse, accountID, account.ID)
+		}
+	}
+
+	for _, posting := range repo.postings {
+		if posting.AccountID == accountID {
+			return fmt.Errorf("%w: account %d has postings", ErrAccountInUse, accountID)
+		}
+	}
+
+	for _, counterparty := range repo.counterparties {
+		if counterparty.AccountID == accountID {
+			return fmt.Errorf("%w: account %d is counterparty %s's default account", ErrAccountInUse, accountID, counterparty.Name)
+		}
+	}
+
+	repo.accounts = slices.Delete(repo.accounts, idx, idx+1)
+
+	return nil
+}
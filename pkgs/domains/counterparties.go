@@ -0,0 +1,99 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// The people and businesses we pay and get paid by, as named on bank statements (see JournalEntry.Counterparty).
+// Only counterparties that have been given an alias or a default account are stored.
+type CounterpartyRepository interface {
+	// SaveCounterparty creates the counterparty named param.Name, or updates it if there already is one.
+	SaveCounterparty(context.Context, SaveCounterpartyParams) (counterpartyID int64, err error)
+	ListCounterparties(context.Context) ([]Counterparty, error) // by name
+}
+
+var ErrInvalidCounterparty = errors.New("invalid counterparty")
+
+type Counterparty struct {
+	ID        int64
+	Name      string // as the bank writes it, e.g. "PERSON_A PAYNOW_ID_001" - matched case-insensitively
+	Alias     string // what we call them, e.g. "Landlord" - blank to use Name
+	AccountID int64  // account transactions with them are booked to by default - 0 for none
+}
+
+// Alias, or Name if there isn't one.
+func (c Counterparty) DisplayName() string {
+	if c.Alias != "" {
+		return c.Alias
+	}
+
+	return c.Name
+}
+
+type SaveCounterpartyParams struct {
+	Name      string
+	Alias     string
+	AccountID int64
+}
+
+func ValidateCounterparty(param SaveCounterpartyParams) error {
+	if strings.TrimSpace(param.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidCounterparty)
+	}
+
+	return nil
+}
+
+// The counterparty named name, if there is one.
+func FindCounterparty(counterparties []Counterparty, name string) (Counterparty, bool) {
+	idx := slices.IndexFunc(counterparties, func(c Counterparty) bool { return strings.EqualFold(c.Name, name) })
+	if idx < 0 {
+		return Counterparty{}, false
+	}
+
+	return counterparties[idx], true
+}
+
+func (repo *InMemoryAccountingRepository) SaveCounterparty(_ context.Context, param SaveCounterpartyParams) (counterpartyID int64, err error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	param.Name = strings.TrimSpace(param.Name)
+	if err := ValidateCounterparty(param); err != nil {
+		return 0, err
+	}
+	if _, ok := accountsByID(repo.accounts)[param.AccountID]; param.AccountID != 0 && !ok {
+		return 0, fmt.Errorf("%w: %d", ErrAccountNotFound, param.AccountID)
+	}
+
+	idx := slices.IndexFunc(repo.counterparties, func(c Counterparty) bool { return strings.EqualFold(c.Name, param.Name) })
+	if idx >= 0 {
+		repo.counterparties[idx].Alias = param.Alias
+		repo.counterparties[idx].AccountID = param.AccountID
+		return repo.counterparties[idx].ID, nil
+	}
+
+	counterpartyID = int64(len(repo.counterparties)) + 1
+	repo.counterparties = append(repo.counterparties, Counterparty{
+		ID:        counterpartyID,
+		Name:      param.Name,
+		Alias:     param.Alias,
+		AccountID: param.AccountID,
+	})
+
+	return counterpartyID, nil
+}
+
+func (repo *InMemoryAccountingRepository) ListCounterparties(context.Context) ([]Counterparty, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	counterparties := slices.Clone(repo.counterparties)
+	slices.SortFunc(counterparties, func(a, b Counterparty) int { return strings.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name)) })
+
+	return counterparties, nil
+}
@@ -0,0 +1,573 @@
+// Package domaintest holds the conformance test suite every domain.AccountingRepository implementation must pass.
+package domaintest
+
+import (
+	"errors"
+	domain "personal-finance/pkgs/domains"
+	"personal-finance/pkgs/money"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Returns a new, empty (i.e. only seeded with the default chart of accounts) repository.
+type NewAccountingRepositoryFunc func(t *testing.T) domain.AccountingRepository
+
+func RunAccountingRepositoryTests(t *testing.T, newRepo NewAccountingRepositoryFunc) {
+	t.Helper()
+
+	tests := map[string]func(*testing.T, NewAccountingRepositoryFunc){
+		"CreateJournalEntry":                testCreateJournalEntry,
+		"CreateJournalEntryUnbalanced":      testCreateJournalEntryUnbalanced,
+		"CreateJournalEntryFingerprint":     testCreateJournalEntryFingerprint,
+		"CreateJournalEntryProvenance":      testCreateJournalEntryProvenance,
+		"CreateJournalEntryReversal":        testCreateJournalEntryReversal,
+		"CreateJournalEntryReplacedBy":      testCreateJournalEntryReplacedBy,
+		"CreateJournalEntryPending":         testCreateJournalEntryPending,
+		"CreateJournalEntryTags":            testCreateJournalEntryTags,
+		"CreateJournalEntryUnknownAccount":  testCreateJournalEntryUnknownAccount,
+		"CreateExpense":                     testCreateExpense,
+		"CreateIncome":                      testCreateIncome,
+		"SeedsChartOfAccounts":              testSeedsChartOfAccounts,
+		"CreateSubAccount":                  testCreateSubAccount,
+		"UpdateAccount":                     testUpdateAccount,
+		"DeleteAccountWithPostings":         testDeleteAccountWithPostings,
+		"ListJournalEntriesPreservesOrder":  testListJournalEntriesPreservesOrder,
+		"ListTransactionsIgnoresFundingLeg": testListTransactionsIgnoresFundingLeg,
+		"WithTxCommits":                     testWithTxCommits,
+		"WithTxRollsBack":                   testWithTxRollsBack,
+		"WithTxNestedRollsBackInner":        testWithTxNestedRollsBackInner,
+		"ConcurrentWrites":                  testConcurrentWrites,
+		"CreateImport":                      testCreateImport,
+		"CreateImportMixedCurrencies":       testCreateImportMixedCurrencies,
+		"CreateImportUnknownAccount":        testCreateImportUnknownAccount,
+		"GetImportNotFound":                 testGetImportNotFound,
+		"CreateReconciliation":              testCreateReconciliation,
+		"CreateReconciliationUnknownImport": testCreateReconciliationUnknownImport,
+		"SaveCounterparty":                  testSaveCounterparty,
+		"CreateJournalEntryCounterparty":    testCreateJournalEntryCounterparty,
+		"DeleteCounterpartyAccount":         testDeleteCounterpartyAccount,
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			test(t, newRepo)
+		})
+	}
+}
+
+func testCreateJournalEntry(t *testing.T, newRepo NewAccountingRepositoryFunc) {
+	ctx := t.Context()
+	repo := newRepo(t)
+
+	date := time.Date(2025, 10, 22, 0, 0, 0, 0, time.UTC)
+	id, err := repo.CreateJournalEntry(ctx, domain.CreateJournalEntryParams{Name: "groceries", Description: "weekly shop", Date: date}, []domain.CreatePostingParams{
+		{AccountID: domain.AccountID_Expense_Groceries, Debit: money.New(12_400_000, money.SGD)},
+		{AccountID: domain.AccountID_Asset_BankAccount, Credit: money.New(12_400_000, money.SGD)},
+	})
+	require.NoError(t, err)
+
+	entries, err := repo.ListJournalEntries(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, id, entries[0].ID)
+	require.Equal(t, "groceries", entries[0].Name)
+	require.Equal(t, "weekly shop", entries[0].Description)
+	require.True(t, date.Equal(entries[0].Date), "want %v, have %v", date, entries[0].Date)
+	require.Len(t, entries[0].Postings, 2)
+
+	for _, posting := range entries[0].Postings {
+		require.Equal(t, id, posting.JournalEntryID)
+	}
+}
+
+func testCreateJournalEntryFingerprint(t *testing.T, newRepo NewAccountingRepositoryFunc) {
+	ctx := t.Context()
+	repo := newRepo(t)
+
+	err := repo.CreateExpense(ctx, domain.CreateExpenseParams{
+		Name:        "coffee",
+		Debit:       money.New(2_000_000, money.SGD),
+		Fingerprint: "identity",
+		ContentHash: "content",
+	})
+	require.NoError(t, err)
+
+	entries, err := repo.ListJournalEntries(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, "identity", entries[0].Fingerprint)
+	require.Equal(t, "content", entries[0].ContentHash)
+}
+
+func testCreateJournalEntryReversal(t *testing.T, newRepo NewAccountingRepositoryFunc) {
+	ctx := t.Context()
+	repo := newRepo(t)
+
+	date := time.Date(2025, 10, 22, 0, 0, 0, 0, time.UTC)
+	err := repo.CreateExpense(ctx, domain.CreateExpenseParams{Name: "coffee", TransactedAt: date, Debit: money.New(2_000_000, money.SGD)})
+	require.NoError(t, err)
+
+	entries, err := repo.ListJournalEntries(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	original := entries[0]
+
+	reversal, postings := domain.NewReversalJournalEntry(original)
+	reversalID, err := repo.CreateJournalEntry(ctx, reversal, postings)
+	require.NoError(t, err)
+
+	entries, err = repo.ListJournalEntries(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, reversalID, entries[1].ID)
+	require.Equal(t, original.ID, entries[1].Reverses)
+	require.Equal(t, date, entries[1].Date)
+	require.Equal(t, map[int64]int64{original.ID: reversalID}, domain.Reversals(entries))
+	for idx, posting := range entries[1].Postings {
+		require.Equal(t, original.Postings[idx].AccountID, posting.AccountID)
+		require.Equal(t, original.Postings[idx].Debit, posting.Credit)
+		require.Equal(t, original.Postings[idx].Credit, posting.Debit)
+	}
+
+	_, err = repo.CreateJournalEntry(ctx, reversal, postings)
+	require.ErrorIs(t, err, domain.ErrAlreadyReversed)
+
+	reversal.Reverses = reversalID + 1
+	_, err = repo.CreateJournalEntry(ctx, reversal, postings)
+	require.ErrorIs(t, err, domain.ErrJournalEntryNotFound)
+}
+
+func testCreateJournalEntryReplacedBy(t *testing.T, newRepo NewAccountingRepositoryFunc) {
+	ctx := t.Context()
+	repo := newRepo(t)
+
+	date := time.Date(2025, 10, 22, 0, 0, 0, 0, time.UTC)
+	err := repo.CreateExpense(ctx, domain.CreateExpenseParams{Name: "coffee", TransactedAt: date, Debit: money.New(2_000_000, money.SGD)})
+	require.NoError(t, err)
+
+	entries, err := repo.ListJournalEntries(ctx)
+	require.NoError(t, err)
+	original := entries[0]
+
+	replacement, postings := domain.NewRebookedJournalEntry(original, domain.AccountID_Expense_Uncategorized, domain.AccountID_Expense_DiningOut)
+	replacementID, err := repo.CreateJournalEntry(ctx, replacement, postings)
+	require.NoError(t, err)
+
+	reversal, postings := domain.NewReversalJournalEntry(original)
+	reversal.ReplacedBy = replacementID + 1
+	_, err = repo.CreateJournalEntry(ctx, reversal, postings)
+	require.ErrorIs(t, err, domain.ErrJournalEntryNotFound)
+
+	reversal.ReplacedBy = replacementID
+	reversalID, err := repo.CreateJournalEntry(ctx, reversal, postings)
+	require.NoError(t, err)
+
+	entries, err = repo.ListJournalEntries(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	require.Equal(t, reversalID, entries[2].ID)
+	require.Equal(t, replacementID, entries[2].ReplacedBy)
+	require.Zero(t, entries[1].ReplacedBy)
+}
+
+func testCreateJournalEntryPending(t *testing.T, newRepo NewAccountingRepositoryFunc) {
+	ctx := t.Context()
+	repo := newRepo(t)
+
+	date := time.Date(2025, 10, 22, 0, 0, 0, 0, time.UTC)
+	err := repo.CreateExpense(ctx, domain.CreateExpenseParams{Name: "hotel hold", TransactedAt: date, Debit: money.New(200_000_000, money.SGD), Pending: true})
+	require.NoError(t, err)
+	err = repo.CreateExpense(ctx, domain.CreateExpenseParams{Name: "coffee", TransactedAt: date, Debit: money.New(2_000_000, money.SGD)})
+	require.NoError(t, err)
+
+	entries, err := repo.ListJournalEntries(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.True(t, entries[0].Pending)
+	require.False(t, entries[1].Pending)
+
+	// reversing a pending entry is pending too, so leaving out pending entries leaves out both
+	reversal, postings := domain.NewReversalJournalEntry(entries[0])
+	_, err = repo.CreateJournalEntry(ctx, reversal, postings)
+	require.NoError(t, err)
+
+	entries, err = repo.ListJournalEntries(ctx)
+	require.NoError(t, err)
+	require.True(t, entries[2].Pending)
+
+	settled := domain.SettledEntries(entries)
+	require.Len(t, settled, 1)
+	require.Equal(t, "coffee", settled[0].Name)
+}
+
+func testCreateJournalEntryTags(t *testing.T, newRepo NewAccountingRepositoryFunc) {
+	ctx := t.Context()
+	repo := newRepo(t)
+
+	err := repo.CreateExpense(ctx, domain.CreateExpenseParams{Name: "hotel", Debit: money.New(200_000_000, money.SGD), Tags: []string{"travel", " ", "work", "travel"}})
+	require.NoError(t, err)
+	err = repo.CreateExpense(ctx, domain.CreateExpenseParams{Name: "coffee", Debit: money.New(2_000_000, money.SGD)})
+	require.NoError(t, err)
+
+	entries, err := repo.ListJournalEntries(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{"travel", "work"}, entries[0].Tags)
+	require.Empty(t, entries[1].Tags)
+
+	// reversals keep the tags, so reports by tag net to zero
+	reversal, postings := domain.NewReversalJournalEntry(entries[0])
+	_, err = repo.CreateJournalEntry(ctx, reversal, postings)
+	require.NoError(t, err)
+
+	entries, err = repo.ListJournalEntries(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{"travel", "work"}, entries[2].Tags)
+}
+
+func testCreateJournalEntryUnbalanced(t *testing.T, newRepo NewAccountingRepositoryFunc) {
+	ctx := t.Context()
+	repo := newRepo(t)
+
+	_, err := repo.CreateJournalEntry(ctx, domain.CreateJournalEntryParams{Name: "groceries"}, []domain.CreatePostingParams{
+		{AccountID: domain.AccountID_Expense_Groceries, Debit: money.New(12_400_000, money.SGD)},
+		{AccountID: domain.AccountID_Asset_BankAccount, Credit: money.New(12_000_000, money.SGD)},
+	})
+	require.ErrorIs(t, err, domain.ErrUnbalancedJournalEntry)
+
+	_, err = repo.CreateJournalEntry(ctx, domain.CreateJournalEntryParams{Name: "one leg"}, []domain.CreatePostingParams{
+		{AccountID: domain.AccountID_Expense_Groceries, Debit: money.New(0, money.SGD)},
+	})
+	require.ErrorIs(t, err, domain.ErrUnbalancedJournalEntry)
+
+	_, err = repo.CreateJournalEntry(ctx, domain.CreateJournalEntryParams{Name: "nothing"}, []domain.CreatePostingParams{
+		{AccountID: domain.AccountID_Expense_Groceries, Debit: money.New(0, money.SGD)},
+		{AccountID: domain.AccountID_Asset_BankAccount, Credit: money.New(0, money.SGD)},
+	})
+	require.ErrorIs(t, err, domain.ErrInvalidPosting)
+
+	_, err = repo.CreateJournalEntry(ctx, domain.CreateJournalEntryParams{Name: "both sides"}, []domain.CreatePostingParams{
+		{AccountID: domain.AccountID_Expense_Groceries, Debit: money.New(12_400_000, money.SGD), Credit: money.New(2_400_000, money.SGD)},
+		{AccountID: domain.AccountID_Asset_BankAccount, Credit: money.New(10_000_000, money.SGD)},
+	})
+	require.ErrorIs(t, err, domain.ErrInvalidPosting)
+
+	entries, err := repo.ListJournalEntries(ctx)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
+
+func testCreateJournalEntryUnknownAccount(t *testing.T, newRepo NewAccountingRepositoryFunc) {
+	ctx := t.Context()
+	repo := newRepo(t)
+
+	_, err := repo.CreateJournalEntry(ctx, domain.CreateJournalEntryParams{Name: "mystery"}, []domain.CreatePostingParams{
+		{AccountID: 9999, Debit: money.New(1_000_000, money.SGD)},
+		{AccountID: domain.AccountID_Asset_BankAccount, Credit: money.New(1_000_000, money.SGD)},
+	})
+	require.ErrorIs(t, err, domain.ErrAccountNotFound)
+
+	entries, err := repo.ListJournalEntries(ctx)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
+
+func testCreateExpense(t *testing.T, newRepo NewAccountingRepositoryFunc) {
+	ctx := t.Context()
+	repo := newRepo(t)
+
+	err := repo.CreateExpense(ctx, domain.CreateExpenseParams{
+		Name:              "MERCHANT_A",
+		TransactedAt:      time.Date(2025, 10, 22, 0, 0, 0, 0, time.UTC),
+		Debit:             money.New(12_400_000, money.SGD),
+		CategoryAccountID: domain.AccountID_Expense_Groceries,
+	})
+	require.NoError(t, err)
+
+	entries, err := repo.ListJournalEntries(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Len(t, entries[0].Postings, 2)
+
+	require.Equal(t, int64(domain.AccountID_Expense_Groceries), entries[0].Postings[0].AccountID)
+	require.Equal(t, money.New(12_400_000, money.SGD), entries[0].Postings[0].Debit)
+	require.Equal(t, int64(domain.AccountID_Asset_BankAccount), entries[0].Postings[1].AccountID)
+	require.Equal(t, money.New(12_400_000, money.SGD), entries[0].Postings[1].Credit)
+
+	expenses, err := repo.ListTransactions(ctx)
+	require.NoError(t, err)
+	require.Len(t, expenses, 1)
+	require.Equal(t, money.New(12_400_000, money.SGD), expenses[0].Debit)
+	require.True(t, expenses[0].Credit.IsZero())
+}
+
+func testCreateIncome(t *testing.T, newRepo NewAccountingRepositoryFunc) {
+	ctx := t.Context()
+	repo := newRepo(t)
+
+	err := repo.CreateIncome(ctx, domain.CreateIncomeParams{
+		Name:   "GIRO - SALARY",
+		Credit: money.New(8_517_000_000, money.SGD),
+	})
+	require.NoError(t, err)
+
+	entries, err := repo.ListJournalEntries(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, int64(domain.AccountID_Income_Uncategorized), entries[0].Postings[0].AccountID)
+	require.Equal(t, money.New(8_517_000_000, money.SGD), entries[0].Postings[0].Credit)
+	require.Equal(t, int64(domain.AccountID_Asset_BankAccount), entries[0].Postings[1].AccountID)
+	require.Equal(t, money.New(8_517_000_000, money.SGD), entries[0].Postings[1].Debit)
+}
+
+func testSeedsChartOfAccounts(t *testing.T, newRepo NewAccountingRepositoryFunc) {
+	ctx := t.Context()
+	repo := newRepo(t)
+
+	account, err := repo.GetAccount(ctx, domain.AccountID_Liability_CreditCard)
+	require.NoError(t, err)
+	require.Equal(t, domain.AccountType_Liability, account.Type)
+	require.Equal(t, domain.NormalBalance_Credit, account.NormalBalance())
+
+	accounts, err := repo.ListAccounts(ctx)
+	require.NoError(t, err)
+	require.Equal(t, domain.DefaultChartOfAccounts()[0], accounts[0])
+	require.Len(t, accounts, len(domain.DefaultChartOfAccounts()))
+}
+
+func testCreateSubAccount(t *testing.T, newRepo NewAccountingRepositoryFunc) {
+	ctx := t.Context()
+	repo := newRepo(t)
+
+	coffeeID, err := repo.CreateAccount(ctx, domain.CreateAccountParams{
+		Name:     "Coffee",
+		ParentID: domain.AccountID_Expense_DiningOut,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(domain.AccountID_Expense_DiningOut+1), coffeeID)
+
+	coffee, err := repo.GetAccount(ctx, coffeeID)
+	require.NoError(t, err)
+	require.Equal(t, domain.AccountType_Expense, coffee.Type)
+
+	accounts, err := repo.ListAccounts(ctx)
+	require.NoError(t, err)
+
+	path, err := domain.AccountPath(accounts, coffeeID)
+	require.NoError(t, err)
+	require.Equal(t, "Expense:DiningOut:Coffee", path)
+
+	found, err := domain.FindAccountByPath(accounts, "expense:diningout:coffee")
+	require.NoError(t, err)
+	require.Equal(t, coffeeID, found.ID)
+
+	// duplicate names under the same parent are rejected
+	_, err = repo.CreateAccount(ctx, domain.CreateAccountParams{Name: "Coffee", ParentID: domain.AccountID_Expense_DiningOut})
+	require.ErrorIs(t, err, domain.ErrInvalidAccount)
+
+	// child types must match their parent's
+	_, err = repo.CreateAccount(ctx, domain.CreateAccountParams{Name: "Tea", Type: domain.AccountType_Asset, ParentID: domain.AccountID_Expense_DiningOut})
+	require.ErrorIs(t, err, domain.ErrInvalidAccount)
+
+	// parents with children can't be deleted
+	err = repo.DeleteAccount(ctx, domain.AccountID_Expense_DiningOut)
+	require.ErrorIs(t, err, domain.ErrAccountInUse)
+
+	require.NoError(t, repo.DeleteAccount(ctx, coffeeID))
+	_, err = repo.GetAccount(ctx, coffeeID)
+	require.ErrorIs(t, err, domain.ErrAccountNotFound)
+}
+
+func testUpdateAccount(t *testing.T, newRepo NewAccountingRepositoryFunc) {
+	ctx := t.Context()
+	repo := newRepo(t)
+
+	id, err := repo.CreateAccount(ctx, domain.CreateAccountParams{Name: "Hawker", ParentID: domain.AccountID_Expense_DiningOut})
+	require.NoError(t, err)
+
+	err = repo.UpdateAccount(ctx, domain.UpdateAccountParams{ID: id, Name: "HawkerCentre", ParentID: domain.AccountID_Expense_DiningOut})
+	require.NoError(t, err)
+
+	account, err := repo.GetAccount(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, "HawkerCentre", account.Name)
+
+	// can't move an account under one of its own descendants
+	err = repo.UpdateAccount(ctx, domain.UpdateAccountParams{ID: domain.AccountID_Expense_DiningOut, Name: "DiningOut", ParentID: id})
+	require.ErrorIs(t, err, domain.ErrInvalidAccount)
+
+	// can't move an expense account under an asset
+	err = repo.UpdateAccount(ctx, domain.UpdateAccountParams{ID: id, Name: "HawkerCentre", ParentID: domain.AccountID_Asset_BankAccount})
+	require.ErrorIs(t, err, domain.ErrInvalidAccount)
+
+	err = repo.UpdateAccount(ctx, domain.UpdateAccountParams{ID: 9999, Name: "Nope"})
+	require.ErrorIs(t, err, domain.ErrAccountNotFound)
+}
+
+func testDeleteAccountWithPostings(t *testing.T, newRepo NewAccountingRepositoryFunc) {
+	ctx := t.Context()
+	repo := newRepo(t)
+
+	require.NoError(t, repo.CreateExpense(ctx, domain.CreateExpenseParams{Name: "lunch", Debit: money.New(5_000_000, money.SGD), CategoryAccountID: domain.AccountID_Expense_DiningOut}))
+
+	err := repo.DeleteAccount(ctx, domain.AccountID_Expense_DiningOut)
+	require.ErrorIs(t, err, domain.ErrAccountInUse)
+
+	err = repo.DeleteAccount(ctx, 9999)
+	require.ErrorIs(t, err, domain.ErrAccountNotFound)
+}
+
+func testListJournalEntriesPreservesOrder(t *testing.T, newRepo NewAccountingRepositoryFunc) {
+	ctx := t.Context()
+	repo := newRepo(t)
+
+	names := []string{"first", "second", "third"}
+	for _, name := range names {
+		require.NoError(t, repo.CreateExpense(ctx, domain.CreateExpenseParams{Name: name, Debit: money.New(1_000_000, money.SGD)}))
+	}
+
+	entries, err := repo.ListJournalEntries(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, len(names))
+	for idx, name := range names {
+		require.Equal(t, name, entries[idx].Name)
+	}
+}
+
+func testListTransactionsIgnoresFundingLeg(t *testing.T, newRepo NewAccountingRepositoryFunc) {
+	ctx := t.Context()
+	repo := newRepo(t)
+
+	// moving money between balance sheet accounts isn't spending or earning
+	_, err := repo.CreateJournalEntry(ctx, domain.CreateJournalEntryParams{Name: "card bill"}, []domain.CreatePostingParams{
+		{AccountID: domain.AccountID_Liability_CreditCard, Debit: money.New(500_000_000, money.SGD)},
+		{AccountID: domain.AccountID_Asset_BankAccount, Credit: money.New(500_000_000, money.SGD)},
+	})
+	require.NoError(t, err)
+
+	expenses, err := repo.ListTransactions(ctx)
+	require.NoError(t, err)
+	require.Len(t, expenses, 1)
+	require.True(t, expenses[0].Debit.IsZero())
+	require.True(t, expenses[0].Credit.IsZero())
+}
+
+func testWithTxCommits(t *testing.T, newRepo NewAccountingRepositoryFunc) {
+	ctx := t.Context()
+	repo := newRepo(t)
+
+	err := repo.WithTx(ctx, func(tx domain.AccountingRepository) error {
+		if err := tx.CreateExpense(ctx, domain.CreateExpenseParams{Name: "first", Debit: money.New(1_000_000, money.SGD)}); err != nil {
+			return err
+		}
+
+		// writes are visible inside the transaction
+		entries, err := tx.ListJournalEntries(ctx)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+
+		return tx.CreateExpense(ctx, domain.CreateExpenseParams{Name: "second", Debit: money.New(2_000_000, money.SGD)})
+	})
+	require.NoError(t, err)
+
+	entries, err := repo.ListJournalEntries(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+}
+
+func testWithTxRollsBack(t *testing.T, newRepo NewAccountingRepositoryFunc) {
+	ctx := t.Context()
+	repo := newRepo(t)
+
+	errBoom := errors.New("boom")
+	err := repo.WithTx(ctx, func(tx domain.AccountingRepository) error {
+		if _, err := tx.CreateAccount(ctx, domain.CreateAccountParams{Name: "Coffee", ParentID: domain.AccountID_Expense_DiningOut}); err != nil {
+			return err
+		}
+
+		if err := tx.CreateExpense(ctx, domain.CreateExpenseParams{Name: "first", Debit: money.New(1_000_000, money.SGD)}); err != nil {
+			return err
+		}
+
+		return errBoom
+	})
+	require.ErrorIs(t, err, errBoom)
+
+	entries, err := repo.ListJournalEntries(ctx)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+
+	accounts, err := repo.ListAccounts(ctx)
+	require.NoError(t, err)
+	require.Len(t, accounts, len(domain.DefaultChartOfAccounts()))
+}
+
+func testWithTxNestedRollsBackInner(t *testing.T, newRepo NewAccountingRepositoryFunc) {
+	ctx := t.Context()
+	repo := newRepo(t)
+
+	errBoom := errors.New("boom")
+	err := repo.WithTx(ctx, func(tx domain.AccountingRepository) error {
+		if err := tx.CreateExpense(ctx, domain.CreateExpenseParams{Name: "outer", Debit: money.New(1_000_000, money.SGD)}); err != nil {
+			return err
+		}
+
+		err := tx.WithTx(ctx, func(tx domain.AccountingRepository) error {
+			if err := tx.CreateExpense(ctx, domain.CreateExpenseParams{Name: "inner", Debit: money.New(1_000_000, money.SGD)}); err != nil {
+				return err
+			}
+
+			return errBoom
+		})
+		require.ErrorIs(t, err, errBoom)
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	entries, err := repo.ListJournalEntries(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, "outer", entries[0].Name)
+}
+
+func testConcurrentWrites(t *testing.T, newRepo NewAccountingRepositoryFunc) {
+	ctx := t.Context()
+	repo := newRepo(t)
+
+	const writers = 8
+	const writesPerWriter = 10
+
+	var wg sync.WaitGroup
+	errs := make(chan error, writers)
+	for range writers {
+		wg.Go(func() {
+			errs <- repo.WithTx(ctx, func(tx domain.AccountingRepository) error {
+				for range writesPerWriter {
+					if err := tx.CreateExpense(ctx, domain.CreateExpenseParams{Name: "coffee", Debit: money.New(1_000_000, money.SGD)}); err != nil {
+						return err
+					}
+				}
+
+				return nil
+			})
+		})
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	entries, err := repo.ListJournalEntries(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, writers*writesPerWriter)
+
+	for _, entry := range entries {
+		require.Len(t, entry.Postings, 2, "journal entry %d", entry.ID)
+	}
+}
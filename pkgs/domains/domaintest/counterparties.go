@@ -0,0 +1,71 @@
+package domaintest
+
+import (
+	domain "personal-finance/pkgs/domains"
+	"personal-finance/pkgs/money"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testSaveCounterparty(t *testing.T, newRepo NewAccountingRepositoryFunc) {
+	ctx := t.Context()
+	repo := newRepo(t)
+
+	id, err := repo.SaveCounterparty(ctx, domain.SaveCounterpartyParams{Name: "PERSON_A PAYNOW_ID_001", Alias: "Landlord", AccountID: domain.AccountID_Expense_Housing})
+	require.NoError(t, err)
+	_, err = repo.SaveCounterparty(ctx, domain.SaveCounterpartyParams{Name: "COMPANY_A"})
+	require.NoError(t, err)
+
+	// names are matched case-insensitively, so this updates rather than adds
+	updatedID, err := repo.SaveCounterparty(ctx, domain.SaveCounterpartyParams{Name: "person_a paynow_id_001", Alias: "Old Landlord"})
+	require.NoError(t, err)
+	require.Equal(t, id, updatedID)
+
+	counterparties, err := repo.ListCounterparties(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []domain.Counterparty{
+		{ID: counterparties[0].ID, Name: "COMPANY_A"},
+		{ID: id, Name: "PERSON_A PAYNOW_ID_001", Alias: "Old Landlord"},
+	}, counterparties)
+
+	found, ok := domain.FindCounterparty(counterparties, "Person_A PayNow_ID_001")
+	require.True(t, ok)
+	require.Equal(t, "Old Landlord", found.DisplayName())
+
+	_, err = repo.SaveCounterparty(ctx, domain.SaveCounterpartyParams{Name: " "})
+	require.ErrorIs(t, err, domain.ErrInvalidCounterparty)
+
+	_, err = repo.SaveCounterparty(ctx, domain.SaveCounterpartyParams{Name: "PERSON_B", AccountID: 999_999})
+	require.ErrorIs(t, err, domain.ErrAccountNotFound)
+}
+
+func testCreateJournalEntryCounterparty(t *testing.T, newRepo NewAccountingRepositoryFunc) {
+	ctx := t.Context()
+	repo := newRepo(t)
+
+	err := repo.CreateExpense(ctx, domain.CreateExpenseParams{Name: "rent", Debit: money.New(2_000_000_000, money.SGD), Counterparty: "PERSON_A PAYNOW_ID_001"})
+	require.NoError(t, err)
+
+	entries, err := repo.ListJournalEntries(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, "PERSON_A PAYNOW_ID_001", entries[0].Counterparty)
+}
+
+func testDeleteCounterpartyAccount(t *testing.T, newRepo NewAccountingRepositoryFunc) {
+	ctx := t.Context()
+	repo := newRepo(t)
+
+	accountID, err := repo.CreateAccount(ctx, domain.CreateAccountParams{Name: "Rent", ParentID: domain.AccountID_Expense_Housing})
+	require.NoError(t, err)
+	_, err = repo.SaveCounterparty(ctx, domain.SaveCounterpartyParams{Name: "PERSON_A", AccountID: accountID})
+	require.NoError(t, err)
+
+	err = repo.DeleteAccount(ctx, accountID)
+	require.ErrorIs(t, err, domain.ErrAccountInUse)
+
+	_, err = repo.SaveCounterparty(ctx, domain.SaveCounterpartyParams{Name: "PERSON_A"})
+	require.NoError(t, err)
+	require.NoError(t, repo.DeleteAccount(ctx, accountID))
+}
@@ -0,0 +1,136 @@
+package domaintest
+
+import (
+	domain "personal-finance/pkgs/domains"
+	"personal-finance/pkgs/money"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testCreateImport(t *testing.T, newRepo NewAccountingRepositoryFunc) {
+	ctx := t.Context()
+	repo := newRepo(t)
+
+	balance := money.New(18_477_160_000, money.SGD)
+	limit := money.New(12_000_000_000, money.SGD)
+	withMetadata := domain.CreateImportParams{
+		Parser:     "dbs",
+		AccountID:  domain.AccountID_Liability_CreditCard,
+		ImportedAt: time.Date(2025, 10, 23, 8, 30, 0, 0, time.UTC),
+		Metadata: domain.StatementMetadata{
+			AccountRef:       "****1234",
+			AsOf:             time.Date(2025, 10, 22, 0, 0, 0, 0, time.UTC),
+			AvailableBalance: &balance,
+			CreditLimit:      &limit,
+		},
+	}
+	withoutMetadata := domain.CreateImportParams{Parser: "ocbc", AccountID: domain.AccountID_Asset_BankAccount, ImportedAt: time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC)}
+
+	firstID, err := repo.CreateImport(ctx, withMetadata)
+	require.NoError(t, err)
+	secondID, err := repo.CreateImport(ctx, withoutMetadata)
+	require.NoError(t, err)
+	require.NotZero(t, firstID)
+	require.NotEqual(t, firstID, secondID)
+
+	imp, err := repo.GetImport(ctx, firstID)
+	require.NoError(t, err)
+	require.Equal(t, firstID, imp.ID)
+	require.Equal(t, "dbs", imp.Parser)
+	require.EqualValues(t, domain.AccountID_Liability_CreditCard, imp.AccountID)
+	require.True(t, withMetadata.ImportedAt.Equal(imp.ImportedAt), "want %v, have %v", withMetadata.ImportedAt, imp.ImportedAt)
+	require.Equal(t, "****1234", imp.Metadata.AccountRef)
+	require.True(t, withMetadata.Metadata.AsOf.Equal(imp.Metadata.AsOf), "want %v, have %v", withMetadata.Metadata.AsOf, imp.Metadata.AsOf)
+	require.Nil(t, imp.Metadata.LedgerBalance)
+	require.Equal(t, &balance, imp.Metadata.AvailableBalance)
+	require.Equal(t, &limit, imp.Metadata.CreditLimit)
+
+	imports, err := repo.ListImports(ctx)
+	require.NoError(t, err)
+	require.Len(t, imports, 2)
+	require.Equal(t, firstID, imports[0].ID)
+	require.Equal(t, secondID, imports[1].ID)
+	require.True(t, imports[1].Metadata.AsOf.IsZero())
+	require.Nil(t, imports[1].Metadata.CreditLimit)
+}
+
+func testCreateImportMixedCurrencies(t *testing.T, newRepo NewAccountingRepositoryFunc) {
+	ctx := t.Context()
+	repo := newRepo(t)
+
+	balance := money.New(1_000_000, money.SGD)
+	limit := money.New(1_000_000, "USD")
+	_, err := repo.CreateImport(ctx, domain.CreateImportParams{
+		Parser:    "dbs",
+		AccountID: domain.AccountID_Liability_CreditCard,
+		Metadata:  domain.StatementMetadata{LedgerBalance: &balance, CreditLimit: &limit},
+	})
+	require.ErrorIs(t, err, domain.ErrInvalidImport)
+}
+
+func testCreateImportUnknownAccount(t *testing.T, newRepo NewAccountingRepositoryFunc) {
+	ctx := t.Context()
+	repo := newRepo(t)
+
+	_, err := repo.CreateImport(ctx, domain.CreateImportParams{Parser: "ocbc", AccountID: 42})
+	require.ErrorIs(t, err, domain.ErrAccountNotFound)
+
+	_, err = repo.CreateImport(ctx, domain.CreateImportParams{Parser: "ocbc", AccountID: domain.AccountID_Expense_Groceries})
+	require.ErrorIs(t, err, domain.ErrInvalidImport)
+}
+
+func testGetImportNotFound(t *testing.T, newRepo NewAccountingRepositoryFunc) {
+	ctx := t.Context()
+	repo := newRepo(t)
+
+	_, err := repo.GetImport(ctx, 42)
+	require.ErrorIs(t, err, domain.ErrImportNotFound)
+}
+
+func testCreateJournalEntryProvenance(t *testing.T, newRepo NewAccountingRepositoryFunc) {
+	ctx := t.Context()
+	repo := newRepo(t)
+
+	importID, err := repo.CreateImport(ctx, domain.CreateImportParams{
+		Parser:     "ocbc",
+		AccountID:  domain.AccountID_Asset_BankAccount,
+		FileSHA256: "3ba3e383",
+		ImportedAt: time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC),
+	})
+	require.NoError(t, err)
+
+	imp, err := repo.GetImport(ctx, importID)
+	require.NoError(t, err)
+	require.Equal(t, "3ba3e383", imp.FileSHA256)
+
+	err = repo.CreateExpense(ctx, domain.CreateExpenseParams{Name: "by hand", Debit: money.New(1_000_000, money.SGD)})
+	require.NoError(t, err)
+
+	err = repo.CreateExpense(ctx, domain.CreateExpenseParams{
+		Name:       "coffee",
+		Debit:      money.New(2_000_000, money.SGD),
+		Provenance: domain.Provenance{ImportID: importID, Row: 7, RawRow: "16/12/2025,16/12/2025,coffee,2.00,"},
+	})
+	require.NoError(t, err)
+
+	entries, err := repo.ListJournalEntries(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Zero(t, entries[0].Provenance)
+	require.Equal(t, domain.Provenance{
+		ImportID:   importID,
+		Row:        7,
+		RawRow:     "16/12/2025,16/12/2025,coffee,2.00,",
+		Parser:     "ocbc",
+		FileSHA256: "3ba3e383",
+	}, entries[1].Provenance)
+
+	err = repo.CreateExpense(ctx, domain.CreateExpenseParams{
+		Name:       "orphan",
+		Debit:      money.New(2_000_000, money.SGD),
+		Provenance: domain.Provenance{ImportID: importID + 1},
+	})
+	require.ErrorIs(t, err, domain.ErrImportNotFound)
+}
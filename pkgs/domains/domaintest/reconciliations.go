@@ -0,0 +1,64 @@
+package domaintest
+
+import (
+	domain "personal-finance/pkgs/domains"
+	"personal-finance/pkgs/money"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testCreateReconciliation(t *testing.T, newRepo NewAccountingRepositoryFunc) {
+	ctx := t.Context()
+	repo := newRepo(t)
+
+	importID, err := repo.CreateImport(ctx, domain.CreateImportParams{Parser: "ocbc", AccountID: domain.AccountID_Asset_BankAccount})
+	require.NoError(t, err)
+
+	param := domain.CreateReconciliationParams{
+		AccountID:    domain.AccountID_Asset_BankAccount,
+		ImportID:     importID,
+		AsOf:         time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC),
+		Balance:      money.New(18_477_160_000, money.SGD),
+		ReconciledAt: time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC),
+	}
+	id, err := repo.CreateReconciliation(ctx, param)
+	require.NoError(t, err)
+
+	_, err = repo.CreateReconciliation(ctx, domain.CreateReconciliationParams{
+		AccountID: domain.AccountID_Liability_CreditCard,
+		AsOf:      time.Date(2025, 10, 22, 0, 0, 0, 0, time.UTC),
+		Balance:   money.New(251_900_000, money.SGD),
+	})
+	require.NoError(t, err)
+
+	reconciliations, err := repo.ListReconciliations(ctx)
+	require.NoError(t, err)
+	require.Len(t, reconciliations, 2)
+
+	r := reconciliations[0]
+	require.Equal(t, id, r.ID)
+	require.EqualValues(t, domain.AccountID_Asset_BankAccount, r.AccountID)
+	require.Equal(t, importID, r.ImportID)
+	require.True(t, param.AsOf.Equal(r.AsOf), "want %v, have %v", param.AsOf, r.AsOf)
+	require.True(t, param.ReconciledAt.Equal(r.ReconciledAt), "want %v, have %v", param.ReconciledAt, r.ReconciledAt)
+	require.Equal(t, param.Balance, r.Balance)
+	require.Zero(t, reconciliations[1].ImportID)
+
+	last, ok := domain.LastReconciliation(reconciliations, domain.AccountID_Asset_BankAccount)
+	require.True(t, ok)
+	require.Equal(t, id, last.ID)
+}
+
+func testCreateReconciliationUnknownImport(t *testing.T, newRepo NewAccountingRepositoryFunc) {
+	ctx := t.Context()
+	repo := newRepo(t)
+
+	_, err := repo.CreateReconciliation(ctx, domain.CreateReconciliationParams{
+		AccountID: domain.AccountID_Asset_BankAccount,
+		ImportID:  42,
+		AsOf:      time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC),
+	})
+	require.ErrorIs(t, err, domain.ErrImportNotFound)
+}
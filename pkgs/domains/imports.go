@@ -0,0 +1,138 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"personal-finance/pkgs/money"
+	"time"
+)
+
+// Records of the bank statements imported into the ledger.
+type ImportRepository interface {
+	CreateImport(context.Context, CreateImportParams) (importID int64, err error)
+	GetImport(context.Context, int64) (Import, error)
+	ListImports(context.Context) ([]Import, error) // oldest first
+}
+
+var (
+	ErrImportNotFound = errors.New("import not found")
+	ErrInvalidImport  = errors.New("invalid import")
+)
+
+// What a bank statement says about itself in the rows above its transactions.
+// Fields the statement doesn't have (or that can't be read) are left unset.
+type StatementMetadata struct {
+	// Masked account or card number, e.g. "****1234" - never the full number.
+	AccountRef string
+	AsOf       time.Time // when the bank produced the statement
+
+	LedgerBalance    *money.Amount // balance including pending transactions
+	AvailableBalance *money.Amount // balance that can be spent - for credit cards, the available limit
+	CreditLimit      *money.Amount
+}
+
+// Currency of the metadata's amounts, or "" if it has none.
+func (m StatementMetadata) Currency() (money.Currency, error) {
+	var currency money.Currency
+	for _, amount := range []*money.Amount{m.LedgerBalance, m.AvailableBalance, m.CreditLimit} {
+		if amount == nil {
+			continue
+		}
+
+		if currency != "" && amount.Currency() != currency {
+			return "", fmt.Errorf("%w: statement amounts are in both %s and %s", ErrInvalidImport, currency, amount.Currency())
+		}
+		currency = amount.Currency()
+	}
+
+	return currency, nil
+}
+
+type Import struct {
+	ID         int64
+	Parser     string // name of the statement parser, e.g. "ocbc"
+	AccountID  int64  // asset or liability account the statement is for, e.g. AccountID_Asset_BankAccount
+	FileSHA256 string // hex encoded hash of the statement file - "" if it wasn't read from a file
+	ImportedAt time.Time
+	Metadata   StatementMetadata
+}
+
+type CreateImportParams struct {
+	Parser     string
+	AccountID  int64
+	FileSHA256 string
+	ImportedAt time.Time
+	Metadata   StatementMetadata
+}
+
+func ValidateImport(param CreateImportParams) error {
+	if param.Parser == "" {
+		return fmt.Errorf("%w: parser is required", ErrInvalidImport)
+	}
+	if param.AccountID == 0 {
+		return fmt.Errorf("%w: account is required", ErrInvalidImport)
+	}
+
+	_, err := param.Metadata.Currency()
+	return err
+}
+
+// Statements are for accounts that hold a balance - bank accounts, cards, loans and the like.
+func ValidateImportAccount(account LedgerAccount) error {
+	if account.Type != AccountType_Asset && account.Type != AccountType_Liability {
+		return fmt.Errorf("%w: account %d is an %s account - statements are for asset or liability accounts", ErrInvalidImport, account.ID, account.Type)
+	}
+
+	return nil
+}
+
+func (repo *InMemoryAccountingRepository) CreateImport(_ context.Context, param CreateImportParams) (importID int64, err error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if err := ValidateImport(param); err != nil {
+		return 0, err
+	}
+	account, ok := accountsByID(repo.accounts)[param.AccountID]
+	if !ok {
+		return 0, fmt.Errorf("%w: %d", ErrAccountNotFound, param.AccountID)
+	}
+	if err := ValidateImportAccount(account); err != nil {
+		return 0, err
+	}
+
+	// IDs start at 1 so 0 can mean "not imported"
+	importID = int64(len(repo.imports)) + 1
+	repo.imports = append(repo.imports, Import{
+		ID:         importID,
+		Parser:     param.Parser,
+		AccountID:  param.AccountID,
+		FileSHA256: param.FileSHA256,
+		ImportedAt: param.ImportedAt,
+		Metadata:   param.Metadata,
+	})
+
+	return importID, nil
+}
+
+func (repo *InMemoryAccountingRepository) GetImport(_ context.Context, importID int64) (Import, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	if importID < 1 || importID > int64(len(repo.imports)) {
+		return Import{}, fmt.Errorf("%w: %d", ErrImportNotFound, importID)
+	}
+
+	return repo.imports[importID-1], nil
+}
+
+func (repo *InMemoryAccountingRepository) ListImports(context.Context) ([]Import, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	imports := make([]Import, len(repo.imports))
+	copy(imports, repo.imports)
+
+	return imports, nil
+}
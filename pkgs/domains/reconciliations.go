@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"personal-finance/pkgs/money"
+	"time"
+)
+
+// Records of the points where the ledger was checked against a bank statement and agreed with it.
+type ReconciliationRepository interface {
+	CreateReconciliation(context.Context, CreateReconciliationParams) (reconciliationID int64, err error)
+	ListReconciliations(context.Context) ([]Reconciliation, error) // oldest first
+}
+
+var ErrInvalidReconciliation = errors.New("invalid reconciliation")
+
+// The account's balance agreed with the bank's as of AsOf. Everything up to AsOf is considered settled.
+type Reconciliation struct {
+	ID           int64
+	AccountID    int64
+	ImportID     int64 // statement the balance came from - 0 if it wasn't imported
+	AsOf         time.Time
+	Balance      money.Amount // in the account's normal direction, e.g. positive means owed for a liability
+	ReconciledAt time.Time
+}
+
+type CreateReconciliationParams struct {
+	AccountID    int64
+	ImportID     int64
+	AsOf         time.Time
+	Balance      money.Amount
+	ReconciledAt time.Time
+}
+
+func ValidateReconciliation(param CreateReconciliationParams) error {
+	if param.AccountID == 0 {
+		return fmt.Errorf("%w: account is required", ErrInvalidReconciliation)
+	}
+	if param.AsOf.IsZero() {
+		return fmt.Errorf("%w: as of date is required", ErrInvalidReconciliation)
+	}
+
+	return nil
+}
+
+// The most recent reconciliation of accountID (by AsOf), if there's been one.
+func LastReconciliation(reconciliations []Reconciliation, accountID int64) (Reconciliation, bool) {
+	last, found := Reconciliation{}, false
+	for _, r := range reconciliations {
+		if r.AccountID == accountID && (!found || !r.AsOf.Before(last.AsOf)) {
+			last, found = r, true
+		}
+	}
+
+	return last, found
+}
+
+func (repo *InMemoryAccountingRepository) CreateReconciliation(_ context.Context, param CreateReconciliationParams) (reconciliationID int64, err error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if err := ValidateReconciliation(param); err != nil {
+		return 0, err
+	}
+	if _, ok := accountsByID(repo.accounts)[param.AccountID]; !ok {
+		return 0, fmt.Errorf("%w: %d", ErrAccountNotFound, param.AccountID)
+	}
+	if param.ImportID != 0 && (param.ImportID < 1 || param.ImportID > int64(len(repo.imports))) {
+		return 0, fmt.Errorf("%w: %d", ErrImportNotFound, param.ImportID)
+	}
+
+	reconciliationID = int64(len(repo.reconciliations)) + 1
+	repo.reconciliations = append(repo.reconciliations, Reconciliation{
+		ID:           reconciliationID,
+		AccountID:    param.AccountID,
+		ImportID:     param.ImportID,
+		AsOf:         param.AsOf,
+		Balance:      param.Balance,
+		ReconciledAt: param.ReconciledAt,
+	})
+
+	return reconciliationID, nil
+}
+
+func (repo *InMemoryAccountingRepository) ListReconciliations(context.Context) ([]Reconciliation, error) {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	reconciliations := make([]Reconciliation, len(repo.reconciliations))
+	copy(reconciliations, repo.reconciliations)
+
+	return reconciliations, nil
+}
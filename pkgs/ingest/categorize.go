@@ -0,0 +1,50 @@
+package ingest
+
+import domain "personal-finance/pkgs/domains"
+
+// Decides how transactions are booked, e.g. by matching them against rules (see pkgs/rules).
+type Categorizer interface {
+	// How to book t, from a statement read by the parser named source - false to leave it to the defaults.
+	Categorize(t Transaction, source string) (Category, bool)
+}
+
+type Category struct {
+	AccountID int64    // account spending, refunds and income are booked to - 0 to leave it to the defaults
+	Name      string   // what to call the transaction instead (see Transaction.Name) - blank to keep its name
+	Tags      []string // added to the transaction's tags
+}
+
+// The transaction and category account categorize decides on, starting from categoryAccountID.
+// Payments between our own accounts aren't categorised, so they only get renamed and tagged.
+// A category whose account is on the wrong side of the money (see categoryFits) is ignored, as if categorizer hadn't matched
+// (accountTypes has the type of every account).
+func categorize(categorizer Categorizer, source string, accountTypes map[int64]domain.AccountType, t Transaction, categoryAccountID int64) (Transaction, int64) {
+	if categorizer == nil {
+		return t, categoryAccountID
+	}
+
+	category, ok := categorizer.Categorize(t, source)
+	if !ok || !categoryFits(accountTypes, t, category.AccountID) {
+		return t, categoryAccountID
+	}
+
+	if category.AccountID != 0 {
+		categoryAccountID = category.AccountID
+	}
+	if category.Name != "" {
+		t.Name = category.Name
+	}
+	t.Tags = append(t.Tags[:len(t.Tags):len(t.Tags)], category.Tags...)
+
+	return t, categoryAccountID
+}
+
+// Whether t can be booked to the category account with accountID: an expense account for spending and refunds,
+// an income account for income (see Transaction.CategoryType). Payments and accountID 0 (uncategorised) fit anything,
+// and accounts missing from accountTypes are left for the repository to reject.
+func categoryFits(accountTypes map[int64]domain.AccountType, t Transaction, accountID int64) bool {
+	accountType, ok := accountTypes[accountID]
+	want := t.CategoryType()
+
+	return accountID == 0 || !ok || want == "" || accountType == want
+}
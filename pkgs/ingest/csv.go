@@ -0,0 +1,211 @@
+package ingest
+
+import (
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+
+	gocsv "github.com/JoelLau/go-csv"
+)
+
+// Reads a statement csv file into a 2D array of cells.
+func ReadTable(path string) (table [][]string, err error) {
+	table, _, err = ReadStatementFile(path)
+	return table, err
+}
+
+// Like ReadTable, but also returns the SHA-256 of the file (hex encoded) so imports can record exactly what they read.
+func ReadStatementFile(path string) (table [][]string, fileSHA256 string, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("error opening file at '%s': %+v", path, err)
+	}
+	defer func() {
+		if closeErr := file.Close(); closeErr != nil && err == nil {
+			err = fmt.Errorf("error closing file: %+v", closeErr)
+		}
+	}()
+
+	fileBytes, err := io.ReadAll(file)
+	if err != nil {
+		return nil, "", fmt.Errorf("error reading bytes from reader: %+v", err)
+	}
+
+	table, err = gocsv.ReadAll(fileBytes)
+	if err != nil {
+		return nil, "", fmt.Errorf("error converting file bytes to string 2D array: %+v", err)
+	}
+
+	sum := sha256.Sum256(fileBytes)
+	return table, hex.EncodeToString(sum[:]), nil
+}
+
+// Unmarshals table into v (a pointer to a slice of structs with `csv` tags), using the first row as the header.
+func UnmarshalTable(table [][]string, v any) error {
+	// re-encode so cells with commas, quotes and newlines (e.g. OCBC's multi-line descriptions) survive the round trip
+	sb := &strings.Builder{}
+	w := csv.NewWriter(sb)
+	if err := w.WriteAll(table); err != nil {
+		return fmt.Errorf("error writing csv: %+v", err)
+	}
+
+	if err := gocsv.Unmarshal([]byte(sb.String()), v); err != nil {
+		return fmt.Errorf("error unmarshalling csv: %+v", err)
+	}
+
+	return nil
+}
+
+var ErrUnexpectedLayout = errors.New("unexpected statement layout")
+
+// Names of the columns T's fields are tagged with, e.g. `csv:"Transaction date"`, in field order.
+func Columns[T any]() []string {
+	typ := reflect.TypeFor[T]()
+
+	columns := []string{}
+	for i := range typ.NumField() {
+		name, _, _ := strings.Cut(typ.Field(i).Tag.Get("csv"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		columns = append(columns, name)
+	}
+
+	return columns
+}
+
+// Index of the first row of table that contains every one of columns, or -1 if there's none.
+func FindHeaderRow(table [][]string, columns []string) int {
+	return slices.IndexFunc(table, func(row []string) bool { return RowHasColumns(row, columns) })
+}
+
+// Like FindHeaderRow, but explains what's wrong when no row has every column.
+// The error names the columns missing from (and unexpected in) the row that looks most like the header,
+// which is usually what changed when a bank changes its export layout.
+// Columns that aren't expected are fine as long as none are missing.
+func LocateHeaderRow(table [][]string, columns []string) (int, error) {
+	best, bestMatches := -1, 0
+	for idx, row := range table {
+		matches := 0
+		for _, cell := range row {
+			if slices.Contains(columns, strings.TrimSpace(cell)) {
+				matches++
+			}
+		}
+
+		if matches > bestMatches {
+			best, bestMatches = idx, matches
+		}
+	}
+
+	if best < 0 {
+		return -1, fmt.Errorf("%w: no header row found - expected columns: %s", ErrUnexpectedLayout, strings.Join(columns, ", "))
+	}
+
+	missing, unexpected := compareColumns(table[best], columns)
+	if len(missing) > 0 {
+		return -1, fmt.Errorf("%w: header row (line %d) is missing columns: %s (unexpected columns: %s)",
+			ErrUnexpectedLayout, best+1, strings.Join(missing, ", "), strings.Join(unexpected, ", "))
+	}
+
+	return best, nil
+}
+
+// Columns missing from header, and non-blank cells of header that aren't columns.
+func compareColumns(header []string, columns []string) (missing []string, unexpected []string) {
+	cells := make([]string, 0, len(header))
+	for _, cell := range header {
+		if cell = strings.TrimSpace(cell); cell != "" {
+			cells = append(cells, cell)
+		}
+	}
+
+	for _, column := range columns {
+		if !slices.Contains(cells, column) {
+			missing = append(missing, column)
+		}
+	}
+
+	for _, cell := range cells {
+		if !slices.Contains(columns, cell) {
+			unexpected = append(unexpected, cell)
+		}
+	}
+
+	return missing, unexpected
+}
+
+// Unmarshals the rows under the header row (see LocateHeaderRow) of table into T's, a struct with `csv` tags,
+// along with where each of them was in the table.
+// Everything above the header - bank statements' metadata preambles - is ignored.
+func UnmarshalStatement[T any](table [][]string) ([]T, []SourceRow, error) {
+	headerRow, err := LocateHeaderRow(table, Columns[T]())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var rows []T
+	if err := UnmarshalTable(table[headerRow:], &rows); err != nil {
+		return nil, nil, err
+	}
+
+	sources := make([]SourceRow, len(rows))
+	for idx := range rows {
+		row := headerRow + 1 + idx
+		sources[idx] = SourceRow{Row: row + 1, Cells: table[row]}
+	}
+
+	return rows, sources, nil
+}
+
+// Reports whether any row before the header row (see FindHeaderRow) starts with marker, e.g. "Account details for:".
+// Banks put a metadata preamble above the transactions that identifies the kind of statement.
+func HasPreamble(table [][]string, headerRow int, marker string) bool {
+	_, ok := PreambleValue(table, headerRow, marker)
+	return ok
+}
+
+// Second cell of the first row above the header row whose first cell is label,
+// e.g. "18,477.16" from the row "Ledger Balance,18,477.16".
+// Labels match case-insensitively and with or without a trailing colon.
+func PreambleValue(table [][]string, headerRow int, label string) (string, bool) {
+	normalise := func(s string) string { return strings.ToLower(strings.TrimSuffix(strings.TrimSpace(s), ":")) }
+
+	for _, row := range table[:max(headerRow, 0)] {
+		if len(row) == 0 || normalise(row[0]) != normalise(label) {
+			continue
+		}
+
+		if len(row) < 2 {
+			return "", true
+		}
+
+		return strings.TrimSpace(row[1]), true
+	}
+
+	return "", false
+}
+
+// Reports whether row contains every one of columns (ignoring surrounding whitespace).
+func RowHasColumns(row []string, columns []string) bool {
+	cells := make(map[string]bool, len(row))
+	for _, cell := range row {
+		cells[strings.TrimSpace(cell)] = true
+	}
+
+	for _, column := range columns {
+		if !cells[column] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,106 @@
+package ingest_test
+
+import (
+	"personal-finance/pkgs/ingest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindHeaderRow(t *testing.T) {
+	t.Parallel()
+
+	table := [][]string{
+		{"Account details for:", "ACCOUNT_ID_001"},
+		{"", ""},
+		{"Date", " Amount ", "Notes"},
+		{"1/1/2025", "1.00", ""},
+	}
+
+	headerRow := ingest.FindHeaderRow(table, []string{"Date", "Amount"})
+	require.Equal(t, 2, headerRow)
+	require.True(t, ingest.HasPreamble(table, headerRow, "account details for:"))
+	require.False(t, ingest.HasPreamble(table, headerRow, "Card Transaction Details For:"))
+
+	require.Equal(t, -1, ingest.FindHeaderRow(table, []string{"Date", "Balance"}))
+	require.False(t, ingest.HasPreamble(table, -1, "Account details for:"))
+}
+
+type statementRow struct {
+	Date     string `csv:"Date"`
+	Amount   string `csv:"Amount,omitempty"`
+	Ignored  string `csv:"-"`
+	Untagged string
+}
+
+func TestColumns(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, []string{"Date", "Amount"}, ingest.Columns[statementRow]())
+}
+
+func TestLocateHeaderRow(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		table   [][]string
+		want    int
+		wantErr string
+	}{
+		"after preamble": {
+			table: [][]string{{"Account details for:", "ACCOUNT_ID_001"}, {"", ""}, {"Date", "Amount"}},
+			want:  2,
+		},
+		"extra columns": {
+			table: [][]string{{"Date", "Amount", "Balance"}},
+			want:  0,
+		},
+		"renamed column": {
+			table:   [][]string{{"Account details for:", "ACCOUNT_ID_001"}, {"Date", "Amount (SGD)"}},
+			wantErr: "header row (line 2) is missing columns: Amount (unexpected columns: Amount (SGD))",
+		},
+		"no header": {
+			table:   [][]string{{"1/1/2025", "1.00"}},
+			wantErr: "no header row found - expected columns: Date, Amount",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			headerRow, err := ingest.LocateHeaderRow(tt.table, ingest.Columns[statementRow]())
+			if tt.wantErr != "" {
+				require.ErrorIs(t, err, ingest.ErrUnexpectedLayout)
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, headerRow)
+		})
+	}
+}
+
+func TestPreambleValue(t *testing.T) {
+	t.Parallel()
+
+	table := [][]string{
+		{"Credit Limit:", " SGD 12,000.00 "},
+		{"Ledger Balance"},
+		{"Date", "Amount"},
+		{"Available Balance", "1.00"},
+	}
+
+	value, ok := ingest.PreambleValue(table, 2, "credit limit")
+	require.True(t, ok)
+	require.Equal(t, "SGD 12,000.00", value)
+
+	value, ok = ingest.PreambleValue(table, 2, "Ledger Balance:")
+	require.True(t, ok)
+	require.Empty(t, value)
+
+	// rows under the header are transactions, not metadata
+	_, ok = ingest.PreambleValue(table, 2, "Available Balance")
+	require.False(t, ok)
+}
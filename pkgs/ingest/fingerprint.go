@@ -0,0 +1,58 @@
+package ingest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Stable identity of a statement row, so the same bank transaction is recognised when it appears in another export.
+type Fingerprint struct {
+	// Hash of what identifies the transaction: source, account, transaction date, amount, normalised description,
+	// and how many identical rows came before it in the statement (e.g. two $2 coffees on the same day).
+	// The posting date is left out as banks revise it while a transaction settles.
+	Identity string
+
+	// Hash of everything the statement says about the transaction. Rows with the same Identity but a different
+	// Content were revised by the bank since they were imported.
+	Content string
+}
+
+// Fingerprints of txs, in the same order. source is the parser's name and accountID the ledger account the statement is for.
+func Fingerprints(source string, accountID int64, txs []Transaction) []Fingerprint {
+	fingerprints := make([]Fingerprint, len(txs))
+	occurrences := map[string]int{}
+	for idx, t := range txs {
+		key := []string{
+			source,
+			strconv.FormatInt(accountID, 10),
+			t.Date.Format(time.DateOnly),
+			t.Amount.Decimal(),
+			string(t.Amount.Currency()),
+			NormaliseDescription(t.Description),
+		}
+
+		occurrence := occurrences[hash(key...)]
+		occurrences[hash(key...)]++
+
+		identity := hash(append(key, strconv.Itoa(occurrence))...)
+		fingerprints[idx] = Fingerprint{
+			Identity: identity,
+			Content:  hash(identity, t.PostingDate.Format(time.DateOnly), t.Description),
+		}
+	}
+
+	return fingerprints
+}
+
+// Uppercases the description and collapses runs of whitespace, which banks are inconsistent about between exports.
+func NormaliseDescription(s string) string {
+	return strings.Join(strings.Fields(strings.ToUpper(s)), " ")
+}
+
+func hash(fields ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(fields, "\x1f")))
+	return hex.EncodeToString(sum[:])
+}
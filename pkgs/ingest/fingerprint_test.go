@@ -0,0 +1,75 @@
+package ingest_test
+
+import (
+	"log/slog"
+	domain "personal-finance/pkgs/domains"
+	"personal-finance/pkgs/ingest"
+	"personal-finance/pkgs/money"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFingerprints(t *testing.T) {
+	t.Parallel()
+
+	coffee := ingest.Transaction{
+		Date:        time.Date(2025, 10, 22, 0, 0, 0, 0, time.UTC),
+		PostingDate: time.Date(2025, 10, 23, 0, 0, 0, 0, time.UTC),
+		Description: "COFFEE   SHOP  SINGAPORE",
+		Amount:      money.MustParse("-2", money.SGD),
+	}
+
+	revised := coffee
+	revised.PostingDate = time.Date(2025, 10, 24, 0, 0, 0, 0, time.UTC)
+	respaced := coffee
+	respaced.Description = "Coffee Shop Singapore"
+
+	fingerprints := ingest.Fingerprints("dbs", domain.AccountID_Liability_CreditCard, []ingest.Transaction{coffee, coffee})
+	require.NotEqual(t, fingerprints[0].Identity, fingerprints[1].Identity, "identical same-day rows are different transactions")
+
+	revisedFingerprint := ingest.Fingerprints("dbs", domain.AccountID_Liability_CreditCard, []ingest.Transaction{revised})[0]
+	require.Equal(t, fingerprints[0].Identity, revisedFingerprint.Identity)
+	require.NotEqual(t, fingerprints[0].Content, revisedFingerprint.Content)
+
+	respacedFingerprint := ingest.Fingerprints("dbs", domain.AccountID_Liability_CreditCard, []ingest.Transaction{respaced})[0]
+	require.Equal(t, fingerprints[0].Identity, respacedFingerprint.Identity)
+
+	otherAccount := ingest.Fingerprints("dbs", domain.AccountID_Asset_BankAccount, []ingest.Transaction{coffee})[0]
+	require.NotEqual(t, fingerprints[0].Identity, otherAccount.Identity)
+}
+
+func TestImportSkipsSeenTransactions(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	slogger := slog.New(slog.NewTextHandler(new(strings.Builder), &slog.HandlerOptions{}))
+	repo := domain.NewInMemoryAccountingRepository()
+
+	coffee := ingest.Transaction{
+		Date:        time.Date(2025, 10, 22, 0, 0, 0, 0, time.UTC),
+		PostingDate: time.Date(2025, 10, 23, 0, 0, 0, 0, time.UTC),
+		Description: "COFFEE",
+		Amount:      money.MustParse("-2", money.SGD),
+	}
+	lunch := coffee
+	lunch.Description = "LUNCH"
+	lunch.Amount = money.MustParse("-12", money.SGD)
+
+	summary, err := ingest.Import(ctx, slogger, repo, ingest.Statement{Transactions: []ingest.Transaction{coffee, coffee}}, ingest.Options{Parser: "dbs"})
+	require.NoError(t, err)
+	require.Equal(t, 2, summary.Imported)
+
+	revised := coffee
+	revised.PostingDate = time.Date(2025, 10, 24, 0, 0, 0, 0, time.UTC)
+
+	summary, err = ingest.Import(ctx, slogger, repo, ingest.Statement{Transactions: []ingest.Transaction{coffee, revised, lunch}}, ingest.Options{Parser: "dbs"})
+	require.NoError(t, err)
+	require.Equal(t, ingest.Summary{ImportID: 2, Rows: 3, Imported: 1, Duplicates: 1, Changed: 1}, summary)
+
+	entries, err := repo.ListJournalEntries(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+}
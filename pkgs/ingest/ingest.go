@@ -0,0 +1,420 @@
+// Package ingest imports bank statement transactions into the ledger.
+package ingest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	domain "personal-finance/pkgs/domains"
+	"personal-finance/pkgs/money"
+	"personal-finance/pkgs/period"
+	"time"
+)
+
+var ErrWrongAccountType = errors.New("account is the wrong type for the transaction")
+
+// A statement row, normalised so it no longer matters which bank it came from.
+type Transaction struct {
+	Date        time.Time    // when the transaction happened
+	PostingDate time.Time    // when the bank posted it (a.k.a. value date)
+	Description string       // as the bank writes it
+	Name        string       // what to call it in the ledger, e.g. the merchant - Description if blank
+	Amount      money.Amount // money into (+) or out of (-) the account, from the account holder's point of view
+	Type        TransactionType
+	Pending     bool      // authorised but not yet settled - the settled version replaces it when it's imported
+	Source      SourceRow // zero if the transaction wasn't read from a file
+
+	// for statements that say
+	PaymentType  string // how the money moved, e.g. "PayNow" or "Contactless"
+	Counterparty string // who the money went to or came from
+	PurposeCode  string // what the payment was for, as a code, e.g. "SALARY" or "OTHR"
+	Reference    string // what the payer wrote on the payment, e.g. an invoice number
+
+	Tags []string // labels for its journal entry (see Categorizer)
+}
+
+// Name, or Description if the transaction hasn't been named.
+func (t Transaction) EntryName() string {
+	if t.Name != "" {
+		return t.Name
+	}
+
+	return t.Description
+}
+
+// The type of account t is categorised to: expense for spending and refunds, income for income.
+// Blank for payments, which move money between our own accounts and aren't categorised.
+func (t Transaction) CategoryType() domain.AccountType {
+	switch {
+	case t.Type == TransactionType_Payment:
+		return ""
+	case t.Amount.IsNegative() || t.Type == TransactionType_Refund:
+		return domain.AccountType_Expense
+	default:
+		return domain.AccountType_Income
+	}
+}
+
+// What a transaction is, for statements that say - it decides which accounts the transaction is booked to.
+type TransactionType string
+
+const (
+	TransactionType_Unknown  TransactionType = ""         // spending if money went out, income if it came in
+	TransactionType_Purchase TransactionType = "purchase" // spending
+	TransactionType_Refund   TransactionType = "refund"   // spending given back - reduces the expense instead of counting as income
+	TransactionType_Payment  TransactionType = "payment"  // money from another of our accounts, e.g. a card bill paid from the bank account
+)
+
+// A parsed bank statement.
+type Statement struct {
+	Metadata     domain.StatementMetadata
+	Transactions []Transaction
+}
+
+// Dates transactions can be filtered by (see Options.DateField)
+const (
+	DateField_Transaction = "transaction"
+	DateField_Posting     = "posting"
+)
+
+func ParseDateField(s string) (string, error) {
+	switch s {
+	case DateField_Transaction, DateField_Posting:
+		return s, nil
+	case "value": // what OCBC calls its posting date
+		return DateField_Posting, nil
+	default:
+		return "", fmt.Errorf("unknown date field '%s' - expected %s or %s", s, DateField_Transaction, DateField_Posting)
+	}
+}
+
+type Options struct {
+	Parser     string           // name of the parser that read the statement, e.g. "ocbc"
+	FileSHA256 string           // hash of the statement file (see ReadStatementFile)
+	AccountID  int64            // asset or liability account the statement is for - defaults to AccountID_Asset_BankAccount
+	DateRange  period.Range     // only import transactions in this range
+	DateField  string           // date DateRange applies to - defaults to DateField_Transaction
+	Transfers  *TransferOptions // collapse transfers between our own accounts once imported (see CollapseTransfers) - nil to skip
+
+	// Decides which accounts transactions are booked to, ahead of their counterparty's default account - nil to skip
+	Categorizer Categorizer
+
+	// Decides the accounts of transactions that Categorizer and counterparties leave uncategorised,
+	// e.g. a classifier that learnt from past entries (see pkgs/classify) - nil to skip
+	Fallback Categorizer
+}
+
+type Summary struct {
+	ImportID   int64 `json:"import_id"`
+	Rows       int   `json:"rows"`       // transactions in the statement
+	Excluded   int   `json:"excluded"`   // transactions outside of the date range
+	Imported   int   `json:"imported"`   // new transactions
+	Duplicates int   `json:"duplicates"` // transactions already in the ledger - skipped
+	Changed    int   `json:"changed"`    // transactions already in the ledger that the bank has since revised - skipped
+	Pending    int   `json:"pending"`    // imported transactions that haven't settled yet (counted in Imported too)
+	Settled    int   `json:"settled"`    // pending transactions already in the ledger that have now settled - replaced
+	Transfers  int   `json:"transfers"`  // pairs of entries collapsed into a transfer between our own accounts
+}
+
+// Imports a statement as a single unit of work - either it and every one of its transactions are recorded or none are.
+// Transactions already in the ledger (see Fingerprint) are skipped, so overlapping statements can be imported safely.
+// Pending transactions already in the ledger are reversed once their settled version turns up, and the settled version imported.
+func Import(ctx context.Context, slogger *slog.Logger, repo domain.AccountingRepository, statement Statement, opts Options) (Summary, error) {
+	if opts.AccountID == 0 {
+		opts.AccountID = domain.AccountID_Asset_BankAccount
+	}
+
+	summary := Summary{Rows: len(statement.Transactions)}
+
+	// fingerprint the whole statement so occurrence counts don't depend on the date filter
+	allFingerprints := Fingerprints(opts.Parser, opts.AccountID, statement.Transactions)
+	txs, fingerprints := []Transaction{}, []Fingerprint{}
+	for idx, t := range statement.Transactions {
+		if inDateRange(t, opts.DateRange, opts.DateField) {
+			txs = append(txs, t)
+			fingerprints = append(fingerprints, allFingerprints[idx])
+		}
+	}
+
+	summary.Excluded = summary.Rows - len(txs)
+	slogger.InfoContext(ctx, "filtered transactions by date",
+		slog.String("period", opts.DateRange.String()),
+		slog.String("date field", opts.DateField),
+		slog.Int("included", len(txs)),
+		slog.Int("excluded", summary.Excluded),
+	)
+
+	err := repo.WithTx(ctx, func(tx domain.AccountingRepository) error {
+		var err error
+		summary.ImportID, err = tx.CreateImport(ctx, domain.CreateImportParams{
+			Parser:     opts.Parser,
+			AccountID:  opts.AccountID,
+			FileSHA256: opts.FileSHA256,
+			ImportedAt: time.Now(),
+			Metadata:   statement.Metadata,
+		})
+		if err != nil {
+			return fmt.Errorf("error recording import: %w", err)
+		}
+
+		seen, err := importedContentHashes(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		pending, err := listPendingEntries(ctx, tx, opts.AccountID)
+		if err != nil {
+			return err
+		}
+
+		counterparties, err := tx.ListCounterparties(ctx)
+		if err != nil {
+			return fmt.Errorf("error listing counterparties: %+v", err)
+		}
+
+		accounts, err := tx.ListAccounts(ctx)
+		if err != nil {
+			return fmt.Errorf("error listing accounts: %+v", err)
+		}
+		accountTypes := make(map[int64]domain.AccountType, len(accounts))
+		for _, account := range accounts {
+			accountTypes[account.ID] = account.Type
+		}
+
+		for idx, t := range txs {
+			slogger.DebugContext(ctx, "processing", slog.Int("row #", idx), slog.Any("transaction", t))
+
+			// every posting has to move money (see domain.ValidatePostings), so there's nothing to book
+			if t.Amount.IsZero() {
+				return fmt.Errorf("error importing transaction (%d, %s): its amount is zero", idx, t.Description)
+			}
+
+			fingerprint := fingerprints[idx]
+			var settles *domain.JournalEntry // the pending entry t is the settled version of, if any - reversed once t is booked
+			if !t.Pending {
+				if entry, ok := pending.takeByIdentity(fingerprint.Identity); ok {
+					delete(seen, fingerprint.Identity)
+					settles = &entry
+				}
+			}
+
+			if contentHash, ok := seen[fingerprint.Identity]; ok {
+				if contentHash == fingerprint.Content {
+					summary.Duplicates++
+					continue
+				}
+
+				slogger.WarnContext(ctx, "skipping transaction the bank has revised since it was imported",
+					slog.String("description", t.Description),
+					slog.String("date", t.Date.Format(time.DateOnly)),
+					slog.String("amount", t.Amount.String()),
+				)
+				summary.Changed++
+				continue
+			}
+
+			if !t.Pending && settles == nil {
+				if entry, ok := pending.takeByAmount(t); ok {
+					settles = &entry
+				}
+			}
+
+			// book it to the counterparty's default account, if they have one, unless the categorizer says otherwise -
+			// either is skipped if its account is on the wrong side of the money, e.g. an expense account for a landlord's refund
+			counterparty, _ := domain.FindCounterparty(counterparties, t.Counterparty)
+			categoryAccountID := counterparty.AccountID
+			if !categoryFits(accountTypes, t, categoryAccountID) {
+				categoryAccountID = 0
+			}
+
+			t, categoryAccountID := categorize(opts.Categorizer, opts.Parser, accountTypes, t, categoryAccountID)
+			if categoryAccountID == 0 {
+				t, categoryAccountID = categorize(opts.Fallback, opts.Parser, accountTypes, t, categoryAccountID)
+			}
+
+			provenance := domain.Provenance{ImportID: summary.ImportID, Row: t.Source.Row, RawRow: RedactRow(t.Source.Cells)}
+			entryID, err := createEntry(ctx, tx, opts.AccountID, categoryAccountID, t, fingerprint, provenance)
+			if err != nil {
+				return fmt.Errorf("error importing transaction (%d, %s): %w", idx, t.Description, err)
+			}
+
+			seen[fingerprint.Identity] = fingerprint.Content
+			summary.Imported++
+			if t.Pending {
+				summary.Pending++
+			}
+			if settles != nil {
+				if err := settle(ctx, slogger, tx, *settles, summary.ImportID, entryID); err != nil {
+					return err
+				}
+				summary.Settled++
+			}
+		}
+
+		if opts.Transfers == nil {
+			return nil
+		}
+
+		transfers, err := CollapseTransfers(ctx, tx, *opts.Transfers, summary.ImportID, false)
+		if err != nil {
+			return fmt.Errorf("error matching transfers: %w", err)
+		}
+
+		summary.Transfers = len(transfers)
+		return nil
+	})
+	if err != nil {
+		return Summary{}, err
+	}
+
+	return summary, nil
+}
+
+// Reverses a pending entry whose settled version has just been imported as the entry with settledID, by import importID.
+// The reversal is part of the import and points at the settled entry, so reverting the import puts the pending entry back (see Revert),
+// and keeps the pending entry's fingerprint, so the pending transaction still counts as imported if it turns up again.
+func settle(ctx context.Context, slogger *slog.Logger, repo domain.AccountingRepository, entry domain.JournalEntry, importID int64, settledID int64) error {
+	slogger.DebugContext(ctx, "replacing pending transaction with its settled version",
+		slog.Int64("journal entry", entry.ID),
+		slog.String("name", entry.Name),
+	)
+
+	reversal, postings := domain.NewReversalJournalEntry(entry)
+	reversal.Fingerprint, reversal.ContentHash = entry.Fingerprint, entry.ContentHash
+	reversal.Provenance = domain.Provenance{ImportID: importID}
+	reversal.ReplacedBy = settledID
+	if _, err := repo.CreateJournalEntry(ctx, reversal, postings); err != nil {
+		return fmt.Errorf("error reversing pending journal entry %d: %w", entry.ID, err)
+	}
+
+	return nil
+}
+
+// Content hashes of every imported journal entry, by fingerprint identity.
+// Entries that have been reversed (see Revert) don't count, nor do the reversals of entries replaced by a transfer or a settled transaction
+// once it's been reversed. Those reversals only count for fingerprints no entry has, e.g. a pending transaction settled under another.
+func importedContentHashes(ctx context.Context, repo domain.AccountingRepository) (map[string]string, error) {
+	entries, err := repo.ListJournalEntries(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error listing journal entries: %+v", err)
+	}
+
+	reversals := domain.Reversals(entries)
+	seen := map[string]string{}
+	for _, entry := range entries {
+		if _, reversed := reversals[entry.ID]; reversed || entry.Fingerprint == "" {
+			continue
+		}
+		if _, undone := reversals[entry.ReplacedBy]; undone && entry.ReplacedBy != 0 {
+			continue
+		}
+		if _, ok := seen[entry.Fingerprint]; ok && entry.Reverses != 0 {
+			continue
+		}
+
+		seen[entry.Fingerprint] = entry.ContentHash
+	}
+
+	return seen, nil
+}
+
+// Books t against the statement's account (accountID). Money into the account is debited to it and money out credited,
+// which is right for either kind of account: a deposit grows a bank account's debit balance,
+// and a card purchase grows its credit balance (what's owed). Then:
+//   - payments move money between the account and AccountID_Asset_Clearing, where the other account's side of the payment lands
+//   - refunds reduce spending
+//   - otherwise, money going out is spending and money coming in is income
+//
+// Spending, refunds and income are booked to categoryAccountID, or left uncategorised if it's 0.
+// categoryAccountID must be an expense account for spending and refunds, and an income account for income
+// (see Transaction.CategoryType and categoryFits).
+func createEntry(ctx context.Context, repo domain.AccountingRepository, accountID int64, categoryAccountID int64, t Transaction, fingerprint Fingerprint, provenance domain.Provenance) (int64, error) {
+	if t.Type == TransactionType_Payment {
+		return createTransfer(ctx, repo, accountID, domain.AccountID_Asset_Clearing, t, fingerprint, provenance)
+	}
+
+	param := domain.CreateExpenseParams{
+		Name:              t.EntryName(),
+		Description:       t.Description,
+		TransactedAt:      t.Date,
+		CategoryAccountID: categoryAccountID,
+		FundingAccountID:  accountID,
+		Fingerprint:       fingerprint.Identity,
+		ContentHash:       fingerprint.Content,
+		Provenance:        provenance,
+		Pending:           t.Pending,
+		Counterparty:      t.Counterparty,
+		Tags:              t.Tags,
+	}
+
+	var entry domain.CreateJournalEntryParams
+	var postings []domain.CreatePostingParams
+	switch {
+	case t.Type == TransactionType_Refund && !t.Amount.IsNegative():
+		param.Debit, param.Credit = money.Zero(t.Amount.Currency()), t.Amount
+		entry, postings = domain.NewExpenseJournalEntry(param)
+	case t.Amount.IsNegative():
+		spent, err := t.Amount.Neg()
+		if err != nil {
+			return 0, err
+		}
+
+		param.Debit, param.Credit = spent, money.Zero(spent.Currency())
+		entry, postings = domain.NewExpenseJournalEntry(param)
+	default:
+		param.Debit, param.Credit = money.Zero(t.Amount.Currency()), t.Amount
+		entry, postings = domain.NewIncomeJournalEntry(param)
+	}
+
+	return repo.CreateJournalEntry(ctx, entry, postings)
+}
+
+// Books money moving into (or out of, if t.Amount is negative) accountID from otherAccountID.
+func createTransfer(ctx context.Context, repo domain.AccountingRepository, accountID int64, otherAccountID int64, t Transaction, fingerprint Fingerprint, provenance domain.Provenance) (int64, error) {
+	amount, err := t.Amount.Abs()
+	if err != nil {
+		return 0, err
+	}
+
+	into, outOf := accountID, otherAccountID
+	if t.Amount.IsNegative() {
+		into, outOf = otherAccountID, accountID
+	}
+
+	entry := domain.CreateJournalEntryParams{
+		Name:         t.EntryName(),
+		Description:  t.Description,
+		Date:         t.Date,
+		Fingerprint:  fingerprint.Identity,
+		ContentHash:  fingerprint.Content,
+		Provenance:   provenance,
+		Pending:      t.Pending,
+		Counterparty: t.Counterparty,
+		Tags:         t.Tags,
+	}
+	return repo.CreateJournalEntry(ctx, entry, []domain.CreatePostingParams{
+		{Name: t.EntryName(), AccountID: into, Debit: amount, Credit: money.Zero(amount.Currency())},
+		{Name: t.EntryName(), AccountID: outOf, Debit: money.Zero(amount.Currency()), Credit: amount},
+	})
+}
+
+// Keeps only the transactions whose date (picked by dateField) is in dateRange.
+func FilterByDate(txs []Transaction, dateRange period.Range, dateField string) []Transaction {
+	filtered := make([]Transaction, 0, len(txs))
+	for _, t := range txs {
+		if inDateRange(t, dateRange, dateField) {
+			filtered = append(filtered, t)
+		}
+	}
+
+	return filtered
+}
+
+func inDateRange(t Transaction, dateRange period.Range, dateField string) bool {
+	if dateField == DateField_Posting {
+		return dateRange.Contains(t.PostingDate)
+	}
+
+	return dateRange.Contains(t.Date)
+}
@@ -0,0 +1,327 @@
+package ingest_test
+
+import (
+	"log/slog"
+	domain "personal-finance/pkgs/domains"
+	"personal-finance/pkgs/ingest"
+	"personal-finance/pkgs/money"
+	"personal-finance/pkgs/period"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestImport(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	slogger := slog.New(slog.NewTextHandler(new(strings.Builder), &slog.HandlerOptions{}))
+	repo := domain.NewInMemoryAccountingRepository()
+
+	dateRange, err := period.NewRange("2025-12", "", "")
+	require.NoError(t, err)
+
+	txs := []ingest.Transaction{
+		{Date: time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC), Description: "coffee", Amount: money.MustParse("-4.50", money.SGD)},
+		{Date: time.Date(2025, 12, 2, 0, 0, 0, 0, time.UTC), Description: "salary", Amount: money.MustParse("5,000", money.SGD)},
+		{Date: time.Date(2025, 11, 30, 0, 0, 0, 0, time.UTC), Description: "last month", Amount: money.MustParse("-1", money.SGD)},
+	}
+
+	balance := money.MustParse("18,477.16", money.SGD)
+	statement := ingest.Statement{
+		Metadata:     domain.StatementMetadata{AccountRef: "****0001", LedgerBalance: &balance},
+		Transactions: txs,
+	}
+
+	summary, err := ingest.Import(ctx, slogger, repo, statement, ingest.Options{Parser: "ocbc", DateRange: dateRange})
+	require.NoError(t, err)
+	require.Equal(t, ingest.Summary{ImportID: 1, Rows: 3, Excluded: 1, Imported: 2}, summary)
+
+	imp, err := repo.GetImport(ctx, summary.ImportID)
+	require.NoError(t, err)
+	require.Equal(t, "ocbc", imp.Parser)
+	require.Equal(t, statement.Metadata, imp.Metadata)
+
+	entries, err := repo.ListJournalEntries(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	// spending debits an expense account, income credits an income account
+	require.EqualValues(t, domain.AccountID_Expense_Uncategorized, entries[0].Postings[0].AccountID)
+	require.Equal(t, money.MustParse("4.50", money.SGD), entries[0].Postings[0].Debit)
+	require.EqualValues(t, domain.AccountID_Income_Uncategorized, entries[1].Postings[0].AccountID)
+	require.Equal(t, money.MustParse("5000", money.SGD), entries[1].Postings[0].Credit)
+}
+
+func TestImportRejectsZeroAmounts(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	slogger := slog.New(slog.NewTextHandler(new(strings.Builder), &slog.HandlerOptions{}))
+	repo := domain.NewInMemoryAccountingRepository()
+
+	date := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+	statement := ingest.Statement{Transactions: []ingest.Transaction{
+		{Date: date, Description: "coffee", Amount: money.MustParse("-4.50", money.SGD)},
+		{Date: date, Description: "CARD VERIFICATION", Amount: money.Zero(money.SGD)},
+	}}
+
+	_, err := ingest.Import(ctx, slogger, repo, statement, ingest.Options{Parser: "dbs", AccountID: domain.AccountID_Liability_CreditCard})
+	require.ErrorContains(t, err, "transaction (1, CARD VERIFICATION): its amount is zero")
+
+	// nothing of the statement is recorded
+	entries, err := repo.ListJournalEntries(ctx)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+	imports, err := repo.ListImports(ctx)
+	require.NoError(t, err)
+	require.Empty(t, imports)
+}
+
+func TestImportBooksByTransactionType(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	slogger := slog.New(slog.NewTextHandler(new(strings.Builder), &slog.HandlerOptions{}))
+	repo := domain.NewInMemoryAccountingRepository()
+
+	date := time.Date(2025, 10, 22, 0, 0, 0, 0, time.UTC)
+	statement := ingest.Statement{Transactions: []ingest.Transaction{
+		{Date: date, Description: "shoes", Amount: money.MustParse("-80", money.SGD), Type: ingest.TransactionType_Purchase},
+		{Date: date, Description: "shoes returned", Amount: money.MustParse("80", money.SGD), Type: ingest.TransactionType_Refund},
+		{Date: date, Description: "bill payment", Amount: money.MustParse("500", money.SGD), Type: ingest.TransactionType_Payment},
+	}}
+
+	_, err := ingest.Import(ctx, slogger, repo, statement, ingest.Options{Parser: "dbs", AccountID: domain.AccountID_Liability_CreditCard})
+	require.NoError(t, err)
+
+	entries, err := repo.ListJournalEntries(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+
+	type leg struct {
+		AccountID     int64
+		Debit, Credit string
+	}
+	legs := func(entry domain.JournalEntry) []leg {
+		legs := []leg{}
+		for _, posting := range entry.Postings {
+			legs = append(legs, leg{posting.AccountID, posting.Debit.Number(), posting.Credit.Number()})
+		}
+		return legs
+	}
+
+	// purchases are owed on the card
+	require.Equal(t, []leg{
+		{domain.AccountID_Expense_Uncategorized, "80.00", "0.00"},
+		{domain.AccountID_Liability_CreditCard, "0.00", "80.00"},
+	}, legs(entries[0]))
+
+	// refunds undo the expense rather than counting as income
+	require.Equal(t, []leg{
+		{domain.AccountID_Expense_Uncategorized, "0.00", "80.00"},
+		{domain.AccountID_Liability_CreditCard, "80.00", "0.00"},
+	}, legs(entries[1]))
+
+	// bill payments pay down the card, from money on its way from the bank account
+	require.Equal(t, []leg{
+		{domain.AccountID_Liability_CreditCard, "500.00", "0.00"},
+		{domain.AccountID_Asset_Clearing, "0.00", "500.00"},
+	}, legs(entries[2]))
+}
+
+func TestImportSettlesPending(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	slogger := slog.New(slog.NewTextHandler(new(strings.Builder), &slog.HandlerOptions{}))
+	repo := domain.NewInMemoryAccountingRepository()
+	opts := ingest.Options{Parser: "dbs", AccountID: domain.AccountID_Liability_CreditCard}
+
+	date := time.Date(2025, 10, 22, 0, 0, 0, 0, time.UTC)
+	summary, err := ingest.Import(ctx, slogger, repo, ingest.Statement{Transactions: []ingest.Transaction{
+		{Date: date, Description: "HOTEL", Amount: money.MustParse("-200", money.SGD), Pending: true},
+		{Date: date, Description: "GRAB*RIDE", Amount: money.MustParse("-15", money.SGD), Pending: true},
+	}}, opts)
+	require.NoError(t, err)
+	require.Equal(t, ingest.Summary{ImportID: 1, Rows: 2, Imported: 2, Pending: 2}, summary)
+
+	// still pending in the next statement
+	summary, err = ingest.Import(ctx, slogger, repo, ingest.Statement{Transactions: []ingest.Transaction{
+		{Date: date, Description: "HOTEL", Amount: money.MustParse("-200", money.SGD), Pending: true},
+	}}, opts)
+	require.NoError(t, err)
+	require.Equal(t, ingest.Summary{ImportID: 2, Rows: 1, Duplicates: 1}, summary)
+
+	// both settle - one as it was, the other reworded and posted later
+	summary, err = ingest.Import(ctx, slogger, repo, ingest.Statement{Transactions: []ingest.Transaction{
+		{Date: date, PostingDate: date.AddDate(0, 0, 2), Description: "HOTEL", Amount: money.MustParse("-200", money.SGD)},
+		{Date: date, PostingDate: date.AddDate(0, 0, 1), Description: "GRAB*RIDE SINGAPORE SG", Amount: money.MustParse("-15", money.SGD)},
+		{Date: date, Description: "coffee", Amount: money.MustParse("-4.50", money.SGD)},
+	}}, opts)
+	require.NoError(t, err)
+	require.Equal(t, ingest.Summary{ImportID: 3, Rows: 3, Imported: 3, Settled: 2}, summary)
+
+	entries, err := repo.ListJournalEntries(ctx)
+	require.NoError(t, err)
+
+	names := []string{}
+	for _, entry := range domain.SettledEntries(entries) {
+		names = append(names, entry.Name)
+	}
+	require.Equal(t, []string{"HOTEL", "GRAB*RIDE SINGAPORE SG", "coffee"}, names)
+
+	// settling is idempotent
+	summary, err = ingest.Import(ctx, slogger, repo, ingest.Statement{Transactions: []ingest.Transaction{
+		{Date: date, PostingDate: date.AddDate(0, 0, 2), Description: "HOTEL", Amount: money.MustParse("-200", money.SGD)},
+	}}, opts)
+	require.NoError(t, err)
+	require.Equal(t, ingest.Summary{ImportID: 4, Rows: 1, Duplicates: 1}, summary)
+}
+
+func TestImportBooksToCounterpartyAccount(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	slogger := slog.New(slog.NewTextHandler(new(strings.Builder), &slog.HandlerOptions{}))
+	repo := domain.NewInMemoryAccountingRepository()
+
+	_, err := repo.SaveCounterparty(ctx, domain.SaveCounterpartyParams{Name: "PERSON_A", Alias: "Landlord", AccountID: domain.AccountID_Expense_Housing})
+	require.NoError(t, err)
+
+	date := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+	statement := ingest.Statement{Transactions: []ingest.Transaction{
+		{Date: date, Description: "FAST PAYMENT to PERSON_A", Amount: money.MustParse("-2000", money.SGD), Counterparty: "person_a"},
+		{Date: date, Description: "FAST PAYMENT to PERSON_B", Amount: money.MustParse("-30", money.SGD), Counterparty: "PERSON_B"},
+	}}
+
+	_, err = ingest.Import(ctx, slogger, repo, statement, ingest.Options{Parser: "ocbc"})
+	require.NoError(t, err)
+
+	entries, err := repo.ListJournalEntries(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, "person_a", entries[0].Counterparty)
+	require.EqualValues(t, domain.AccountID_Expense_Housing, entries[0].Postings[0].AccountID)
+	require.EqualValues(t, domain.AccountID_Expense_Uncategorized, entries[1].Postings[0].AccountID)
+}
+
+// Books everything from "MERCHANT_A" to Expense:DiningOut.
+type merchantACategorizer struct{}
+
+func (merchantACategorizer) Categorize(t ingest.Transaction, source string) (ingest.Category, bool) {
+	if t.Description != "MERCHANT_A" || source != "dbs" {
+		return ingest.Category{}, false
+	}
+
+	return ingest.Category{AccountID: domain.AccountID_Expense_DiningOut, Name: "Merchant A", Tags: []string{"food"}}, true
+}
+
+// Books everything to Expense:Utilities.
+type utilitiesCategorizer struct{}
+
+func (utilitiesCategorizer) Categorize(ingest.Transaction, string) (ingest.Category, bool) {
+	return ingest.Category{AccountID: domain.AccountID_Expense_Utilities}, true
+}
+
+func TestImportCategorizes(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	slogger := slog.New(slog.NewTextHandler(new(strings.Builder), &slog.HandlerOptions{}))
+	repo := domain.NewInMemoryAccountingRepository()
+
+	_, err := repo.SaveCounterparty(ctx, domain.SaveCounterpartyParams{Name: "PERSON_A", AccountID: domain.AccountID_Expense_Housing})
+	require.NoError(t, err)
+
+	date := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+	statement := ingest.Statement{Transactions: []ingest.Transaction{
+		{Date: date, Description: "MERCHANT_A", Amount: money.MustParse("-12", money.SGD), Counterparty: "PERSON_A"},
+		{Date: date, Description: "MERCHANT_B", Amount: money.MustParse("-30", money.SGD), Counterparty: "PERSON_A"},
+		{Date: date, Description: "MERCHANT_C", Amount: money.MustParse("-80", money.SGD)},
+	}}
+
+	_, err = ingest.Import(ctx, slogger, repo, statement, ingest.Options{
+		Parser:      "dbs",
+		AccountID:   domain.AccountID_Liability_CreditCard,
+		Categorizer: merchantACategorizer{},
+		Fallback:    utilitiesCategorizer{},
+	})
+	require.NoError(t, err)
+
+	entries, err := repo.ListJournalEntries(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+
+	// the categorizer comes before the counterparty's default account
+	require.Equal(t, "Merchant A", entries[0].Name)
+	require.Equal(t, []string{"food"}, entries[0].Tags)
+	require.EqualValues(t, domain.AccountID_Expense_DiningOut, entries[0].Postings[0].AccountID)
+
+	require.Equal(t, "MERCHANT_B", entries[1].Name)
+	require.Empty(t, entries[1].Tags)
+	require.EqualValues(t, domain.AccountID_Expense_Housing, entries[1].Postings[0].AccountID)
+
+	// the fallback only gets what's left uncategorised
+	require.EqualValues(t, domain.AccountID_Expense_Utilities, entries[2].Postings[0].AccountID)
+
+}
+
+func TestImportSkipsCategoriesOnTheWrongSide(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	slogger := slog.New(slog.NewTextHandler(new(strings.Builder), &slog.HandlerOptions{}))
+	repo := domain.NewInMemoryAccountingRepository()
+
+	_, err := repo.SaveCounterparty(ctx, domain.SaveCounterpartyParams{Name: "PERSON_A", AccountID: domain.AccountID_Expense_Housing})
+	require.NoError(t, err)
+
+	date := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+	statement := ingest.Statement{Transactions: []ingest.Transaction{
+		{Date: date, Description: "RENT", Amount: money.MustParse("-2000", money.SGD), Counterparty: "PERSON_A"},
+		{Date: date, Description: "RENT", Amount: money.MustParse("200", money.SGD), Counterparty: "PERSON_A"},
+		{Date: date, Description: "SALARY", Amount: money.MustParse("100", money.SGD)},
+		{Date: date, Description: "BILL REFUND", Amount: money.MustParse("20", money.SGD), Type: ingest.TransactionType_Refund},
+	}}
+
+	// income can't be booked to an expense account, so neither the counterparty's account nor either categorizer's is used for it
+	summary, err := ingest.Import(ctx, slogger, repo, statement, ingest.Options{Parser: "ocbc", Categorizer: utilitiesCategorizer{}, Fallback: utilitiesCategorizer{}})
+	require.NoError(t, err)
+	require.Equal(t, 4, summary.Imported)
+
+	entries, err := repo.ListJournalEntries(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+	require.EqualValues(t, domain.AccountID_Expense_Utilities, entries[0].Postings[0].AccountID)
+	require.EqualValues(t, domain.AccountID_Income_Uncategorized, entries[1].Postings[0].AccountID)
+	require.EqualValues(t, domain.AccountID_Income_Uncategorized, entries[2].Postings[0].AccountID)
+	require.EqualValues(t, domain.AccountID_Expense_Utilities, entries[3].Postings[0].AccountID)
+}
+
+func TestFilterByDate(t *testing.T) {
+	t.Parallel()
+
+	dateRange, err := period.NewRange("2025-12", "", "")
+	require.NoError(t, err)
+
+	txs := []ingest.Transaction{
+		{Date: time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC), PostingDate: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)},
+	}
+
+	require.Len(t, ingest.FilterByDate(txs, dateRange, ingest.DateField_Transaction), 1)
+	require.Empty(t, ingest.FilterByDate(txs, dateRange, ingest.DateField_Posting))
+}
+
+func TestParseDateField(t *testing.T) {
+	t.Parallel()
+
+	field, err := ingest.ParseDateField("value")
+	require.NoError(t, err)
+	require.Equal(t, ingest.DateField_Posting, field)
+
+	_, err = ingest.ParseDateField("settled")
+	require.Error(t, err)
+}
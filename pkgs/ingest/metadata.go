@@ -0,0 +1,58 @@
+package ingest
+
+import (
+	"personal-finance/pkgs/money"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Reduces an account or card number to its last 4 characters, e.g. "****1234" for "360 Account 687-123401-234".
+// Only the last word is considered, so names in front of the number (e.g. account holders) are dropped too.
+// Returns "" if there's nothing that looks like a number.
+func MaskAccountRef(s string) string {
+	words := strings.Fields(s)
+	if len(words) == 0 {
+		return ""
+	}
+
+	ref := []rune(strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, words[len(words)-1]))
+	if len(ref) < 4 {
+		return ""
+	}
+
+	return "****" + string(ref[len(ref)-4:])
+}
+
+// Reads an amount from a statement's preamble, e.g. "18,477.16" or "SGD 12,000.00".
+// Returns nil if the amount is missing or unreadable - metadata is informational, so it never fails an import.
+func MetadataAmount(s string, currency money.Currency) *money.Amount {
+	s = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(s), string(currency)))
+	if s == "" {
+		return nil
+	}
+
+	amount, err := money.Parse(s, currency)
+	if err != nil {
+		return nil
+	}
+
+	return &amount
+}
+
+// Reads a date from a statement's preamble in the first of layouts that fits.
+// Returns the zero time if the date is missing or unreadable.
+func MetadataDate(s string, layouts ...string) time.Time {
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, strings.TrimSpace(s)); err == nil {
+			return t
+		}
+	}
+
+	return time.Time{}
+}
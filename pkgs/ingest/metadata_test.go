@@ -0,0 +1,47 @@
+package ingest_test
+
+import (
+	"personal-finance/pkgs/ingest"
+	"personal-finance/pkgs/money"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaskAccountRef(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"360 Account 687-123401-234":                         "****1234",
+		"DBS Altitude Visa Card 4119-1100-0000-5678":         "****5678",
+		"ACCOUNT_HOLDER_A + ACCOUNT_HOLDER_B ACCOUNT_ID_001": "****D001",
+		"123": "",
+		"":    "",
+	}
+
+	for in, want := range tests {
+		t.Run(in, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, want, ingest.MaskAccountRef(in))
+		})
+	}
+}
+
+func TestMetadataAmount(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, money.MustParse("12000", money.SGD), *ingest.MetadataAmount("SGD 12,000.00", money.SGD))
+	require.Equal(t, money.MustParse("18477.16", money.SGD), *ingest.MetadataAmount("18,477.16", money.SGD))
+	require.Nil(t, ingest.MetadataAmount("SGD undefined", money.SGD))
+	require.Nil(t, ingest.MetadataAmount("", money.SGD))
+}
+
+func TestMetadataDate(t *testing.T) {
+	t.Parallel()
+
+	want := time.Date(2025, 10, 22, 0, 0, 0, 0, time.UTC)
+	require.Equal(t, want, ingest.MetadataDate("22 Oct 25", "02 Jan 2006", "2 Jan 06"))
+	require.True(t, ingest.MetadataDate("DATE_001", "02 Jan 2006").IsZero())
+}
@@ -0,0 +1,102 @@
+package ingest
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Reads one bank's statement csv. Banks add support for their statements by registering a parser (see Register).
+type StatementParser interface {
+	Name() string        // short, unique name used on the command line, e.g. "ocbc"
+	Description() string // e.g. "OCBC bank account statement"
+
+	// Ledger account the statements are booked to unless the import names another (see Options.AccountID),
+	// e.g. AccountID_Liability_CreditCard for a credit card statement.
+	AccountID() int64
+
+	// Reports whether table (see ReadTable) looks like one of this parser's statements.
+	Detect(table [][]string) bool
+
+	Parse(table [][]string) (Statement, error)
+}
+
+var (
+	ErrUnknownFormat   = errors.New("unknown statement format")
+	ErrAmbiguousFormat = errors.New("ambiguous statement format")
+)
+
+var (
+	parsersMu sync.RWMutex
+	parsers   = map[string]StatementParser{}
+)
+
+// Makes a parser available by name. Meant to be called from the init function of the bank's package.
+// Panics if a parser of the same name is already registered.
+func Register(parser StatementParser) {
+	parsersMu.Lock()
+	defer parsersMu.Unlock()
+
+	name := parser.Name()
+	if _, ok := parsers[name]; ok {
+		panic(fmt.Sprintf("ingest: Register called twice for parser '%s'", name))
+	}
+
+	parsers[name] = parser
+}
+
+// Every registered parser, sorted by name.
+func Parsers() []StatementParser {
+	parsersMu.RLock()
+	defer parsersMu.RUnlock()
+
+	all := make([]StatementParser, 0, len(parsers))
+	for _, parser := range parsers {
+		all = append(all, parser)
+	}
+	slices.SortFunc(all, func(a, b StatementParser) int { return strings.Compare(a.Name(), b.Name()) })
+
+	return all
+}
+
+func LookupParser(name string) (StatementParser, error) {
+	parsersMu.RLock()
+	parser, ok := parsers[name]
+	parsersMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w: no parser named '%s' - expected one of: %s", ErrUnknownFormat, name, strings.Join(parserNames(Parsers()), ", "))
+	}
+
+	return parser, nil
+}
+
+// Picks the one registered parser that recognises table.
+func DetectParser(table [][]string) (StatementParser, error) {
+	matches := []StatementParser{}
+	for _, parser := range Parsers() {
+		if parser.Detect(table) {
+			matches = append(matches, parser)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("%w: file doesn't look like any of: %s", ErrUnknownFormat, strings.Join(parserNames(Parsers()), ", "))
+	case 1:
+		return matches[0], nil
+	default:
+		return nil, fmt.Errorf("%w: file looks like more than one of: %s", ErrAmbiguousFormat, strings.Join(parserNames(matches), ", "))
+	}
+}
+
+func parserNames(parsers []StatementParser) []string {
+	names := make([]string, len(parsers))
+	for idx, parser := range parsers {
+		names[idx] = parser.Name()
+	}
+
+	return names
+}
@@ -0,0 +1,73 @@
+package ingest_test
+
+import (
+	domain "personal-finance/pkgs/domains"
+	"personal-finance/pkgs/ingest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Recognises tables whose first cell is its name or alias.
+type fakeParser struct{ name, alias string }
+
+func (p fakeParser) Name() string        { return p.name }
+func (p fakeParser) Description() string { return p.name + " statement" }
+func (p fakeParser) AccountID() int64    { return domain.AccountID_Asset_BankAccount }
+
+func (p fakeParser) Detect(table [][]string) bool {
+	return len(table) > 0 && len(table[0]) > 0 && (table[0][0] == p.name || table[0][0] == p.alias)
+}
+
+func (p fakeParser) Parse(table [][]string) (ingest.Statement, error) {
+	return ingest.Statement{}, nil
+}
+
+func init() {
+	ingest.Register(fakeParser{name: "fake-a", alias: "fake"})
+	ingest.Register(fakeParser{name: "fake-b", alias: "fake"})
+}
+
+func TestLookupParser(t *testing.T) {
+	t.Parallel()
+
+	parser, err := ingest.LookupParser("fake-a")
+	require.NoError(t, err)
+	require.Equal(t, "fake-a", parser.Name())
+
+	_, err = ingest.LookupParser("no-such-bank")
+	require.ErrorIs(t, err, ingest.ErrUnknownFormat)
+}
+
+func TestRegisterTwicePanics(t *testing.T) {
+	t.Parallel()
+
+	require.Panics(t, func() { ingest.Register(fakeParser{name: "fake-a"}) })
+}
+
+func TestDetectParser(t *testing.T) {
+	t.Parallel()
+
+	parser, err := ingest.DetectParser([][]string{{"fake-b"}})
+	require.NoError(t, err)
+	require.Equal(t, "fake-b", parser.Name())
+
+	_, err = ingest.DetectParser([][]string{{"something else"}})
+	require.ErrorIs(t, err, ingest.ErrUnknownFormat)
+
+	_, err = ingest.DetectParser([][]string{{"fake"}})
+	require.ErrorIs(t, err, ingest.ErrAmbiguousFormat)
+	require.ErrorContains(t, err, "fake-a, fake-b")
+}
+
+func TestParsersSortedByName(t *testing.T) {
+	t.Parallel()
+
+	names := []string{}
+	for _, parser := range ingest.Parsers() {
+		names = append(names, parser.Name())
+	}
+
+	require.IsNonDecreasing(t, names)
+	require.Subset(t, names, []string{"fake-a", "fake-b"})
+}
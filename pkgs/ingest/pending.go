@@ -0,0 +1,93 @@
+package ingest
+
+import (
+	"context"
+	"fmt"
+	domain "personal-finance/pkgs/domains"
+	"personal-finance/pkgs/money"
+)
+
+// A pending journal entry that hasn't been replaced by its settled version yet.
+type pendingEntry struct {
+	entry  domain.JournalEntry
+	amount money.Amount // change to the statement account, in the same sense as Transaction.Amount
+}
+
+// Pending entries still waiting to settle, so Import can replace them when their settled version arrives.
+type pendingEntries []pendingEntry
+
+// Imported pending entries on accountID that haven't been reversed.
+func listPendingEntries(ctx context.Context, repo domain.AccountingRepository, accountID int64) (pendingEntries, error) {
+	entries, err := repo.ListJournalEntries(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error listing journal entries: %+v", err)
+	}
+
+	reversals := domain.Reversals(entries)
+	pending := pendingEntries{}
+	for _, entry := range entries {
+		if _, reversed := reversals[entry.ID]; reversed || !entry.Pending || entry.Reverses != 0 {
+			continue
+		}
+
+		amount, onAccount, err := accountChange(entry, accountID)
+		if err != nil {
+			return nil, fmt.Errorf("error summing postings of journal entry %d: %w", entry.ID, err)
+		}
+
+		if onAccount {
+			pending = append(pending, pendingEntry{entry: entry, amount: amount})
+		}
+	}
+
+	return pending, nil
+}
+
+// Removes and returns the pending entry with the given fingerprint identity - i.e. the same transaction as it was when pending.
+func (p *pendingEntries) takeByIdentity(identity string) (domain.JournalEntry, bool) {
+	return p.take(func(pending pendingEntry) bool {
+		return pending.entry.Fingerprint == identity
+	})
+}
+
+// Removes and returns a pending entry on the same date for the same amount as t.
+// Banks often reword the description once a transaction settles, so this catches what takeByIdentity can't.
+func (p *pendingEntries) takeByAmount(t Transaction) (domain.JournalEntry, bool) {
+	return p.take(func(pending pendingEntry) bool {
+		return pending.entry.Date.Equal(t.Date) && pending.amount == t.Amount
+	})
+}
+
+func (p *pendingEntries) take(match func(pendingEntry) bool) (domain.JournalEntry, bool) {
+	for idx, pending := range *p {
+		if match(pending) {
+			*p = append((*p)[:idx], (*p)[idx+1:]...)
+			return pending.entry, true
+		}
+	}
+
+	return domain.JournalEntry{}, false
+}
+
+// Net debit (+) or credit (-) of entry's postings to accountID, and whether it has any.
+func accountChange(entry domain.JournalEntry, accountID int64) (money.Amount, bool, error) {
+	var change money.Amount
+	onAccount := false
+	for _, posting := range entry.Postings {
+		if posting.AccountID != accountID {
+			continue
+		}
+
+		onAccount = true
+		net, err := posting.Debit.Sub(posting.Credit)
+		if err != nil {
+			return money.Amount{}, false, err
+		}
+
+		if change, err = change.Add(net); err != nil {
+			return money.Amount{}, false, err
+		}
+	}
+
+	return change, onAccount, nil
+}
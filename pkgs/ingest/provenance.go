@@ -0,0 +1,44 @@
+package ingest
+
+import (
+	"encoding/csv"
+	"regexp"
+	"strings"
+)
+
+// Where a transaction was in its statement file.
+type SourceRow struct {
+	Row   int      // csv record, starting at 1 - the line number unless a cell spans lines
+	Cells []string // as they were in the file - redact them before storing (see RedactRow)
+}
+
+// Runs of digits, optionally broken up by spaces or dashes, e.g. "687-123401-234" or "4119 1100 0000 1234".
+var digitRun = regexp.MustCompile(`\d[\d -]*\d`)
+
+// Joins cells back into a csv record, masking anything with 8 or more digits - account, card and phone numbers -
+// down to its last 4 digits, e.g. "****1234". Dates and amounts are short enough to be left alone.
+func RedactRow(cells []string) string {
+	redacted := make([]string, len(cells))
+	for idx, cell := range cells {
+		redacted[idx] = digitRun.ReplaceAllStringFunc(cell, func(run string) string {
+			digits := strings.Map(func(r rune) rune {
+				if r >= '0' && r <= '9' {
+					return r
+				}
+				return -1
+			}, run)
+			if len(digits) < 8 {
+				return run
+			}
+
+			return "****" + digits[len(digits)-4:]
+		})
+	}
+
+	sb := &strings.Builder{}
+	w := csv.NewWriter(sb)
+	_ = w.Write(redacted) // writes to a strings.Builder can't fail
+	w.Flush()
+
+	return strings.TrimSuffix(sb.String(), "\n")
+}
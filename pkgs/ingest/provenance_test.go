@@ -0,0 +1,45 @@
+package ingest_test
+
+import (
+	"personal-finance/pkgs/ingest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactRow(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		cells []string
+		want  string
+	}{
+		"nothing to redact": {
+			cells: []string{"16/12/2025", "GIRO - SALARY", "6,000.00", ""},
+			want:  `16/12/2025,GIRO - SALARY,"6,000.00",`,
+		},
+		"account number": {
+			cells: []string{"FUND TRANSFER\nto 687-123401-234"},
+			want:  "\"FUND TRANSFER\nto ****1234\"",
+		},
+		"card number": {
+			cells: []string{"22 Oct 25", "REFUND 4119 1100 0000 1234 SG"},
+			want:  "22 Oct 25,REFUND ****1234 SG",
+		},
+		"short reference": {
+			cells: []string{"REF 1234567"},
+			want:  "REF 1234567",
+		},
+		"no cells": {
+			want: "",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.want, ingest.RedactRow(tt.cells))
+		})
+	}
+}
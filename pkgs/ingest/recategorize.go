@@ -0,0 +1,74 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	domain "personal-finance/pkgs/domains"
+	"slices"
+)
+
+var ErrNotCategorizable = errors.New("journal entry doesn't have exactly one income or expense posting")
+
+// Books an entry's income or expense leg (see domain.CategoryPosting) to accountID instead, e.g. once it's been reviewed.
+// The entry is reversed and replaced by a rebooked copy (see domain.NewRebookedJournalEntry), which keeps its fingerprint
+// and provenance - so the transaction still counts as imported, and reverting the import reverts the copy.
+func Recategorize(ctx context.Context, repo domain.AccountingRepository, entryID int64, accountID int64) (rebookedID int64, err error) {
+	err = repo.WithTx(ctx, func(tx domain.AccountingRepository) error {
+		entries, err := tx.ListJournalEntries(ctx)
+		if err != nil {
+			return fmt.Errorf("error listing journal entries: %+v", err)
+		}
+
+		idx := slices.IndexFunc(entries, func(entry domain.JournalEntry) bool { return entry.ID == entryID })
+		if idx < 0 {
+			return fmt.Errorf("%w: %d", domain.ErrJournalEntryNotFound, entryID)
+		}
+		entry := entries[idx]
+
+		accounts, err := tx.ListAccounts(ctx)
+		if err != nil {
+			return fmt.Errorf("error listing accounts: %+v", err)
+		}
+
+		posting, ok := domain.CategoryPosting(entry, accounts)
+		if !ok || entry.Reverses != 0 {
+			return fmt.Errorf("%w: %d", ErrNotCategorizable, entryID)
+		}
+
+		// spending stays spending and income stays income
+		from, to := accountType(accounts, posting.AccountID), accountType(accounts, accountID)
+		if to == "" {
+			return fmt.Errorf("%w: %d", domain.ErrAccountNotFound, accountID)
+		}
+		if from != to {
+			return fmt.Errorf("%w: journal entry %d is booked to an %s account, but account %d is an %s account", ErrWrongAccountType, entryID, from, accountID, to)
+		}
+
+		reversal, postings := domain.NewReversalJournalEntry(entry)
+		if _, err := tx.CreateJournalEntry(ctx, reversal, postings); err != nil {
+			return fmt.Errorf("error reversing journal entry %d: %w", entry.ID, err)
+		}
+
+		rebooked, postings := domain.NewRebookedJournalEntry(entry, posting.AccountID, accountID)
+		if rebookedID, err = tx.CreateJournalEntry(ctx, rebooked, postings); err != nil {
+			return fmt.Errorf("error rebooking journal entry %d: %w", entry.ID, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return rebookedID, nil
+}
+
+func accountType(accounts []domain.LedgerAccount, accountID int64) domain.AccountType {
+	idx := slices.IndexFunc(accounts, func(a domain.LedgerAccount) bool { return a.ID == accountID })
+	if idx < 0 {
+		return ""
+	}
+
+	return accounts[idx].Type
+}
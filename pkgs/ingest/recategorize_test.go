@@ -0,0 +1,70 @@
+package ingest_test
+
+import (
+	"log/slog"
+	domain "personal-finance/pkgs/domains"
+	"personal-finance/pkgs/ingest"
+	"personal-finance/pkgs/money"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecategorize(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	slogger := slog.New(slog.NewTextHandler(new(strings.Builder), &slog.HandlerOptions{}))
+	repo := domain.NewInMemoryAccountingRepository()
+
+	statement := ingest.Statement{Transactions: []ingest.Transaction{
+		{Date: time.Date(2025, 10, 22, 0, 0, 0, 0, time.UTC), Description: "HAWKER_STALL_A", Amount: money.MustParse("-5.50", money.SGD), Tags: []string{"lunch"}},
+	}}
+
+	imported, err := ingest.Import(ctx, slogger, repo, statement, ingest.Options{Parser: "dbs", AccountID: domain.AccountID_Liability_CreditCard})
+	require.NoError(t, err)
+
+	rebookedID, err := ingest.Recategorize(ctx, repo, 1, domain.AccountID_Expense_DiningOut)
+	require.NoError(t, err)
+
+	entries, err := repo.ListJournalEntries(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+
+	original, reversal, rebooked := entries[0], entries[1], entries[2]
+	require.Equal(t, original.ID, reversal.Reverses)
+	require.Equal(t, rebookedID, rebooked.ID)
+	require.Equal(t, original.Name, rebooked.Name)
+	require.Equal(t, original.Fingerprint, rebooked.Fingerprint)
+	require.Equal(t, original.Provenance, rebooked.Provenance)
+	require.Equal(t, []string{"lunch"}, rebooked.Tags)
+	require.EqualValues(t, domain.AccountID_Expense_DiningOut, rebooked.Postings[0].AccountID)
+	require.Equal(t, money.MustParse("5.50", money.SGD), rebooked.Postings[0].Debit)
+	require.EqualValues(t, domain.AccountID_Liability_CreditCard, rebooked.Postings[1].AccountID)
+
+	// the transaction still counts as imported
+	again, err := ingest.Import(ctx, slogger, repo, statement, ingest.Options{Parser: "dbs", AccountID: domain.AccountID_Liability_CreditCard})
+	require.NoError(t, err)
+	require.Equal(t, 1, again.Duplicates)
+
+	// spending can't be booked as income
+	_, err = ingest.Recategorize(ctx, repo, rebooked.ID, domain.AccountID_Income_SalaryWages)
+	require.ErrorIs(t, err, ingest.ErrWrongAccountType)
+	_, err = ingest.Recategorize(ctx, repo, rebooked.ID, 9999)
+	require.ErrorIs(t, err, domain.ErrAccountNotFound)
+
+	// the original has been replaced, and reversals can't be rebooked
+	_, err = ingest.Recategorize(ctx, repo, original.ID, domain.AccountID_Expense_Groceries)
+	require.ErrorIs(t, err, domain.ErrAlreadyReversed)
+	_, err = ingest.Recategorize(ctx, repo, reversal.ID, domain.AccountID_Expense_Groceries)
+	require.ErrorIs(t, err, ingest.ErrNotCategorizable)
+	_, err = ingest.Recategorize(ctx, repo, 99, domain.AccountID_Expense_Groceries)
+	require.ErrorIs(t, err, domain.ErrJournalEntryNotFound)
+
+	// reverting the import reverts the rebooked entry
+	reverted, err := ingest.Revert(ctx, repo, imported.ImportID)
+	require.NoError(t, err)
+	require.Equal(t, ingest.RevertSummary{ImportID: imported.ImportID, Reversed: 1, AlreadyReversed: 1}, reverted)
+}
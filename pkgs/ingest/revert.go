@@ -0,0 +1,147 @@
+package ingest
+
+import (
+	"context"
+	"fmt"
+	domain "personal-finance/pkgs/domains"
+)
+
+type RevertSummary struct {
+	ImportID        int64 `json:"import_id"`
+	Reversed        int   `json:"reversed"`         // entries reversed just now
+	AlreadyReversed int   `json:"already_reversed"` // entries that had been reversed before - skipped
+	Transfers       int   `json:"transfers"`        // transfers undone because one of their sides was reversed (see CollapseTransfers)
+}
+
+// Undoes an import by posting a reversing entry (see domain.NewReversalJournalEntry) for every journal entry it created.
+// Nothing is deleted, so the ledger keeps a record of both the mistake and its correction.
+// Entries that have already been reversed are skipped, so reverting an import twice is harmless.
+// Reversed entries no longer count as imported, so the same transactions can be imported again.
+//
+// Entries that were collapsed into a transfer (see CollapseTransfers) are undone by reversing the transfer instead,
+// and the other side of the transfer is put back as it was before the collapse (unless it's from this import too),
+// so the other account's statement still adds up and the transfer can be matched again if this import is redone.
+// Likewise, pending entries that this import's transactions settled are put back as they were before they settled.
+func Revert(ctx context.Context, repo domain.AccountingRepository, importID int64) (RevertSummary, error) {
+	summary := RevertSummary{ImportID: importID}
+
+	err := repo.WithTx(ctx, func(tx domain.AccountingRepository) error {
+		if _, err := tx.GetImport(ctx, importID); err != nil {
+			return fmt.Errorf("error getting import %d: %w", importID, err)
+		}
+
+		entries, err := tx.ListJournalEntries(ctx)
+		if err != nil {
+			return fmt.Errorf("error listing journal entries: %+v", err)
+		}
+
+		byID := make(map[int64]domain.JournalEntry, len(entries))
+		replacements := map[int64]bool{} // key: journal entry id of a transfer or settled transaction that replaced reversed entries
+		for _, entry := range entries {
+			byID[entry.ID] = entry
+			if entry.ReplacedBy != 0 {
+				replacements[entry.ReplacedBy] = true
+			}
+		}
+
+		// transfers are the only replacements that weren't read from a statement
+		isTransfer := func(entry domain.JournalEntry) bool { return replacements[entry.ID] && entry.Fingerprint == "" }
+
+		reversals := domain.Reversals(entries)
+		undone := map[int64]bool{} // key: journal entry id of a transfer undone just now
+		undo := func(transfer domain.JournalEntry) error {
+			if _, reversed := reversals[transfer.ID]; reversed || undone[transfer.ID] {
+				return nil
+			}
+
+			if err := undoTransfer(ctx, tx, importID, transfer, entries, byID); err != nil {
+				return err
+			}
+			undone[transfer.ID] = true
+			summary.Transfers++
+
+			return nil
+		}
+
+		for _, entry := range entries {
+			// reversals are undone through the entries they reverse or replace
+			if entry.Provenance.ImportID != importID || entry.Reverses != 0 {
+				continue
+			}
+
+			// transfers are undone through their sides, or directly if neither side is from this import
+			if isTransfer(entry) {
+				if err := undo(entry); err != nil {
+					return err
+				}
+				continue
+			}
+
+			reversalID, reversed := reversals[entry.ID]
+			if !reversed {
+				reversal, postings := domain.NewReversalJournalEntry(entry)
+				if _, err := tx.CreateJournalEntry(ctx, reversal, postings); err != nil {
+					return fmt.Errorf("error reversing journal entry %d: %w", entry.ID, err)
+				}
+				summary.Reversed++
+
+				// a settled transaction: put back the pending entry it replaced
+				if err := restoreReplaced(ctx, tx, importID, entry.ID, entries, byID); err != nil {
+					return err
+				}
+				continue
+			}
+
+			transfer, ok := byID[byID[reversalID].ReplacedBy]
+			if _, reversed := reversals[transfer.ID]; !ok || !isTransfer(transfer) || reversed {
+				summary.AlreadyReversed++
+				continue
+			}
+
+			summary.Reversed++
+			if err := undo(transfer); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return RevertSummary{}, err
+	}
+
+	return summary, nil
+}
+
+// Reverses transfer, and puts back the entries it replaced that aren't from import importID (see restoreReplaced).
+func undoTransfer(ctx context.Context, repo domain.AccountingRepository, importID int64, transfer domain.JournalEntry, entries []domain.JournalEntry, byID map[int64]domain.JournalEntry) error {
+	reversal, postings := domain.NewReversalJournalEntry(transfer)
+	if _, err := repo.CreateJournalEntry(ctx, reversal, postings); err != nil {
+		return fmt.Errorf("error reversing transfer %d: %w", transfer.ID, err)
+	}
+
+	return restoreReplaced(ctx, repo, importID, transfer.ID, entries, byID)
+}
+
+// Puts back the entries replaced by the entry with replacementID (see domain.JournalEntry.ReplacedBy) that aren't from import importID -
+// copies of them, since they've been reversed already.
+func restoreReplaced(ctx context.Context, repo domain.AccountingRepository, importID int64, replacementID int64, entries []domain.JournalEntry, byID map[int64]domain.JournalEntry) error {
+	for _, entry := range entries {
+		if entry.ReplacedBy != replacementID {
+			continue
+		}
+
+		replaced := byID[entry.Reverses]
+		if replaced.Provenance.ImportID == importID {
+			continue
+		}
+
+		// rebooking nothing makes a plain copy
+		restored, postings := domain.NewRebookedJournalEntry(replaced, 0, 0)
+		if _, err := repo.CreateJournalEntry(ctx, restored, postings); err != nil {
+			return fmt.Errorf("error restoring journal entry %d replaced by journal entry %d: %w", replaced.ID, replacementID, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,209 @@
+package ingest_test
+
+import (
+	"log/slog"
+	domain "personal-finance/pkgs/domains"
+	"personal-finance/pkgs/ingest"
+	"personal-finance/pkgs/money"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRevert(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	slogger := slog.New(slog.NewTextHandler(new(strings.Builder), &slog.HandlerOptions{}))
+	repo := domain.NewInMemoryAccountingRepository()
+
+	statement := ingest.Statement{Transactions: []ingest.Transaction{
+		{Date: time.Date(2025, 10, 22, 0, 0, 0, 0, time.UTC), Description: "COFFEE", Amount: money.MustParse("-2", money.SGD)},
+		{Date: time.Date(2025, 10, 23, 0, 0, 0, 0, time.UTC), Description: "SALARY", Amount: money.MustParse("100", money.SGD)},
+	}}
+
+	first, err := ingest.Import(ctx, slogger, repo, statement, ingest.Options{Parser: "ocbc"})
+	require.NoError(t, err)
+
+	reverted, err := ingest.Revert(ctx, repo, first.ImportID)
+	require.NoError(t, err)
+	require.Equal(t, ingest.RevertSummary{ImportID: first.ImportID, Reversed: 2}, reverted)
+
+	// the originals stay, cancelled out by their reversals
+	expenses, err := repo.ListTransactions(ctx)
+	require.NoError(t, err)
+	require.Len(t, expenses, 4)
+
+	net := money.Zero(money.SGD)
+	for _, expense := range expenses {
+		change, err := expense.Credit.Sub(expense.Debit)
+		require.NoError(t, err)
+		net, err = net.Add(change)
+		require.NoError(t, err)
+	}
+	require.True(t, net.IsZero())
+
+	reverted, err = ingest.Revert(ctx, repo, first.ImportID)
+	require.NoError(t, err)
+	require.Equal(t, ingest.RevertSummary{ImportID: first.ImportID, AlreadyReversed: 2}, reverted)
+
+	// reversed transactions aren't duplicates any more
+	second, err := ingest.Import(ctx, slogger, repo, statement, ingest.Options{Parser: "ocbc"})
+	require.NoError(t, err)
+	require.Equal(t, 2, second.Imported)
+	require.Zero(t, second.Duplicates)
+
+	_, err = ingest.Revert(ctx, repo, second.ImportID+1)
+	require.ErrorIs(t, err, domain.ErrImportNotFound)
+}
+
+func TestRevertCollapsedTransfer(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	slogger := slog.New(slog.NewTextHandler(new(strings.Builder), &slog.HandlerOptions{}))
+	repo := domain.NewInMemoryAccountingRepository()
+
+	date := time.Date(2025, 10, 22, 0, 0, 0, 0, time.UTC)
+	bank := ingest.Statement{Transactions: []ingest.Transaction{
+		{Date: date, Description: "BILL PAYMENT DBS CARD", Amount: money.MustParse("-500", money.SGD)},
+		{Date: date, Description: "salary", Amount: money.MustParse("80", money.SGD)},
+	}}
+	card := ingest.Statement{Transactions: []ingest.Transaction{
+		{Date: date.AddDate(0, 0, 2), Description: "PAYMENT - OCBC", Amount: money.MustParse("500", money.SGD), Type: ingest.TransactionType_Payment},
+		{Date: date, Description: "shoes", Amount: money.MustParse("-80", money.SGD), Type: ingest.TransactionType_Purchase},
+	}}
+	bankOpts := ingest.Options{Parser: "ocbc", AccountID: domain.AccountID_Asset_BankAccount, Transfers: &ingest.DefaultTransferOptions}
+	cardOpts := ingest.Options{Parser: "dbs", AccountID: domain.AccountID_Liability_CreditCard, Transfers: &ingest.DefaultTransferOptions}
+
+	bankImport, err := ingest.Import(ctx, slogger, repo, bank, bankOpts)
+	require.NoError(t, err)
+	cardImport, err := ingest.Import(ctx, slogger, repo, card, cardOpts)
+	require.NoError(t, err)
+	require.Equal(t, 1, cardImport.Transfers)
+
+	// undoing the card's side of the transfer puts the bank's side back as it was
+	reverted, err := ingest.Revert(ctx, repo, cardImport.ImportID)
+	require.NoError(t, err)
+	require.Equal(t, ingest.RevertSummary{ImportID: cardImport.ImportID, Reversed: 2, Transfers: 1}, reverted)
+	require.Equal(t, "0.00", balance(t, repo, domain.AccountID_Liability_CreditCard))
+	require.Equal(t, "-420.00", balance(t, repo, domain.AccountID_Asset_BankAccount))
+	require.Equal(t, "500.00", balance(t, repo, domain.AccountID_Expense_Uncategorized))
+
+	reverted, err = ingest.Revert(ctx, repo, cardImport.ImportID)
+	require.NoError(t, err)
+	require.Equal(t, ingest.RevertSummary{ImportID: cardImport.ImportID, AlreadyReversed: 2}, reverted)
+
+	// so the card statement can be imported again, and matched to the bank's side again
+	again, err := ingest.Import(ctx, slogger, repo, card, cardOpts)
+	require.NoError(t, err)
+	require.Equal(t, 2, again.Imported)
+	require.Zero(t, again.Duplicates)
+	require.Equal(t, 1, again.Transfers)
+	require.Equal(t, "420.00", balance(t, repo, domain.AccountID_Liability_CreditCard)) // paid 500, spent 80
+	require.Equal(t, "-420.00", balance(t, repo, domain.AccountID_Asset_BankAccount))
+	require.Equal(t, "80.00", balance(t, repo, domain.AccountID_Expense_Uncategorized))
+
+	// and the other way round: undoing the bank's side puts the card's side back
+	reverted, err = ingest.Revert(ctx, repo, bankImport.ImportID)
+	require.NoError(t, err)
+	require.Equal(t, ingest.RevertSummary{ImportID: bankImport.ImportID, Reversed: 2, AlreadyReversed: 1, Transfers: 1}, reverted)
+	require.Equal(t, "0.00", balance(t, repo, domain.AccountID_Asset_BankAccount))
+	require.Equal(t, "420.00", balance(t, repo, domain.AccountID_Liability_CreditCard))
+	require.Equal(t, "-500.00", balance(t, repo, domain.AccountID_Asset_Clearing)) // the card payment, waiting for the bank's side
+
+	again, err = ingest.Import(ctx, slogger, repo, bank, bankOpts)
+	require.NoError(t, err)
+	require.Equal(t, 2, again.Imported)
+	require.Equal(t, 1, again.Transfers)
+	require.Equal(t, "0.00", balance(t, repo, domain.AccountID_Asset_Clearing))
+}
+
+func TestRevertSettled(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	slogger := slog.New(slog.NewTextHandler(new(strings.Builder), &slog.HandlerOptions{}))
+	repo := domain.NewInMemoryAccountingRepository()
+
+	date := time.Date(2025, 10, 22, 0, 0, 0, 0, time.UTC)
+	pending := ingest.Statement{Transactions: []ingest.Transaction{
+		{Date: date, Description: "HOTEL", Amount: money.MustParse("-200", money.SGD), Pending: true},
+	}}
+	settled := ingest.Statement{Transactions: []ingest.Transaction{
+		{Date: date, PostingDate: date.AddDate(0, 0, 2), Description: "HOTEL SINGAPORE", Amount: money.MustParse("-200", money.SGD)},
+		{Date: date, PostingDate: date.AddDate(0, 0, 1), Description: "COFFEE", Amount: money.MustParse("-5", money.SGD)},
+	}}
+	opts := ingest.Options{Parser: "dbs", AccountID: domain.AccountID_Liability_CreditCard}
+
+	_, err := ingest.Import(ctx, slogger, repo, pending, opts)
+	require.NoError(t, err)
+	settledImport, err := ingest.Import(ctx, slogger, repo, settled, opts)
+	require.NoError(t, err)
+	require.Equal(t, 1, settledImport.Settled)
+	require.Equal(t, "205.00", balance(t, repo, domain.AccountID_Expense_Uncategorized))
+
+	// the pending transaction still counts as imported once it's settled, though it was reworded
+	again, err := ingest.Import(ctx, slogger, repo, pending, opts)
+	require.NoError(t, err)
+	require.Equal(t, ingest.Summary{ImportID: again.ImportID, Rows: 1, Duplicates: 1}, again)
+
+	// undoing the settlement puts the pending entry back
+	reverted, err := ingest.Revert(ctx, repo, settledImport.ImportID)
+	require.NoError(t, err)
+	require.Equal(t, ingest.RevertSummary{ImportID: settledImport.ImportID, Reversed: 2}, reverted)
+	require.Equal(t, "200.00", balance(t, repo, domain.AccountID_Expense_Uncategorized))
+
+	entries, err := repo.ListJournalEntries(ctx)
+	require.NoError(t, err)
+	reversals := domain.Reversals(entries)
+	holds := []string{}
+	for _, entry := range entries {
+		if _, reversed := reversals[entry.ID]; !reversed && entry.Reverses == 0 && entry.Pending {
+			holds = append(holds, entry.Name)
+		}
+	}
+	require.Equal(t, []string{"HOTEL"}, holds)
+
+	reverted, err = ingest.Revert(ctx, repo, settledImport.ImportID)
+	require.NoError(t, err)
+	require.Equal(t, ingest.RevertSummary{ImportID: settledImport.ImportID, AlreadyReversed: 2}, reverted)
+	require.Equal(t, "200.00", balance(t, repo, domain.AccountID_Expense_Uncategorized))
+
+	// the pending statement is still imported, and the settled one settles it again
+	again, err = ingest.Import(ctx, slogger, repo, pending, opts)
+	require.NoError(t, err)
+	require.Equal(t, 1, again.Duplicates)
+
+	again, err = ingest.Import(ctx, slogger, repo, settled, opts)
+	require.NoError(t, err)
+	require.Equal(t, 2, again.Imported)
+	require.Equal(t, 1, again.Settled)
+	require.Equal(t, "205.00", balance(t, repo, domain.AccountID_Expense_Uncategorized))
+}
+
+// Debits less credits of the account with accountID.
+func balance(t *testing.T, repo domain.AccountingRepository, accountID int64) string {
+	t.Helper()
+
+	entries, err := repo.ListJournalEntries(t.Context())
+	require.NoError(t, err)
+
+	sum := money.Zero(money.SGD)
+	for _, entry := range entries {
+		for _, posting := range entry.Postings {
+			if posting.AccountID != accountID {
+				continue
+			}
+
+			change, err := posting.Debit.Sub(posting.Credit)
+			require.NoError(t, err)
+			sum, err = sum.Add(change)
+			require.NoError(t, err)
+		}
+	}
+
+	return sum.Number()
+}
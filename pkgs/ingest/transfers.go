@@ -0,0 +1,264 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	domain "personal-finance/pkgs/domains"
+	"personal-finance/pkgs/money"
+	"time"
+)
+
+type TransferOptions struct {
+	WindowDays int          // how many days apart the two sides of a transfer can be dated
+	Tolerance  money.Amount // how much what arrives can differ from what was sent, e.g. to allow for a bank fee
+}
+
+var DefaultTransferOptions = TransferOptions{WindowDays: 3}
+
+// Two imported entries that are the two sides of one transfer between our own accounts,
+// e.g. a card bill paid from the bank account shows up as a withdrawal on the bank statement and a payment on the card's.
+type TransferMatch struct {
+	Out          domain.JournalEntry // money leaving OutAccountID
+	In           domain.JournalEntry // money arriving in InAccountID
+	OutAccountID int64
+	InAccountID  int64
+	Sent         money.Amount // positive
+	Received     money.Amount // positive, within Tolerance of Sent
+}
+
+// One side of a possible transfer.
+type transferSide struct {
+	entry     domain.JournalEntry
+	accountID int64        // the statement's account
+	change    money.Amount // change to accountID, in the same sense as Transaction.Amount
+	clearing  bool         // booked against AccountID_Asset_Clearing, i.e. the bank said it was a payment
+}
+
+// Pairs imported entries that look like the two sides of a transfer: money out of one asset or liability account
+// and into another, dated at most opts.WindowDays apart, for amounts at most opts.Tolerance apart.
+// Only uncategorised entries and entries booked to AccountID_Asset_Clearing are considered, and at least one side of each pair
+// must be booked to AccountID_Asset_Clearing, so a purchase on the card isn't mistaken for a transfer into the bank account.
+// Each entry is paired at most once, with the closest in date and then amount.
+func MatchTransfers(entries []domain.JournalEntry, accounts []domain.LedgerAccount, opts TransferOptions) ([]TransferMatch, error) {
+	accountByID := make(map[int64]domain.LedgerAccount, len(accounts))
+	for _, account := range accounts {
+		accountByID[account.ID] = account
+	}
+
+	reversals := domain.Reversals(entries)
+	outs, ins := []transferSide{}, []transferSide{}
+	for _, entry := range entries {
+		if _, reversed := reversals[entry.ID]; reversed || entry.Reverses != 0 || entry.Pending || entry.Fingerprint == "" {
+			continue
+		}
+
+		side, ok, err := newTransferSide(entry, accountByID)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case !ok:
+		case side.change.IsNegative():
+			outs = append(outs, side)
+		default:
+			ins = append(ins, side)
+		}
+	}
+
+	matches := []TransferMatch{}
+	matched := map[int64]bool{} // key: journal entry id
+	for _, out := range outs {
+		sent, err := out.change.Neg()
+		if err != nil {
+			return nil, err
+		}
+
+		var best *transferSide
+		var bestDays int
+		var bestShortfall money.Amount
+		for idx, in := range ins {
+			if matched[in.entry.ID] || in.accountID == out.accountID || !(in.clearing || out.clearing) {
+				continue
+			}
+
+			days := daysApart(out.entry.Date, in.entry.Date)
+			if days > opts.WindowDays {
+				continue
+			}
+
+			shortfall, err := sent.Sub(in.change)
+			if errors.Is(err, money.ErrCurrencyMismatch) {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("error matching journal entries %d and %d: %w", out.entry.ID, in.entry.ID, err)
+			}
+			if shortfall, err = shortfall.Abs(); err != nil {
+				return nil, err
+			}
+			if cmp, err := shortfall.Cmp(opts.Tolerance); err != nil || cmp > 0 {
+				continue
+			}
+
+			if best != nil {
+				if cmp, _ := shortfall.Cmp(bestShortfall); days > bestDays || (days == bestDays && cmp >= 0) {
+					continue
+				}
+			}
+
+			best, bestDays, bestShortfall = &ins[idx], days, shortfall
+		}
+
+		if best == nil {
+			continue
+		}
+
+		matched[best.entry.ID] = true
+		matches = append(matches, TransferMatch{
+			Out:          out.entry,
+			In:           best.entry,
+			OutAccountID: out.accountID,
+			InAccountID:  best.accountID,
+			Sent:         sent,
+			Received:     best.change,
+		})
+	}
+
+	return matches, nil
+}
+
+// Whether entry could be one side of a transfer: one leg on a statement (asset or liability) account,
+// the other on AccountID_Asset_Clearing or an uncategorised income or expense account.
+func newTransferSide(entry domain.JournalEntry, accountByID map[int64]domain.LedgerAccount) (transferSide, bool, error) {
+	if len(entry.Postings) != 2 {
+		return transferSide{}, false, nil
+	}
+
+	side := transferSide{entry: entry}
+	for _, posting := range entry.Postings {
+		switch posting.AccountID {
+		case domain.AccountID_Asset_Clearing:
+			side.clearing = true
+			continue
+		case domain.AccountID_Expense_Uncategorized, domain.AccountID_Income_Uncategorized:
+			continue
+		}
+
+		account, ok := accountByID[posting.AccountID]
+		if !ok {
+			return transferSide{}, false, fmt.Errorf("%w: %d", domain.ErrAccountNotFound, posting.AccountID)
+		}
+		if domain.ValidateImportAccount(account) != nil || side.accountID != 0 {
+			return transferSide{}, false, nil
+		}
+
+		change, err := posting.Debit.Sub(posting.Credit)
+		if err != nil {
+			return transferSide{}, false, fmt.Errorf("error summing postings of journal entry %d: %w", entry.ID, err)
+		}
+
+		side.accountID, side.change = account.ID, change
+	}
+
+	return side, side.accountID != 0 && !side.change.IsZero(), nil
+}
+
+// Builds the single entry that replaces both sides of match: money moves straight from one account to the other.
+// If less arrived than was sent, the difference is spent (e.g. a fee); if more, it's earned.
+// importID is the import that found the match, if any - the entry is recorded as part of it, so reverting it undoes the transfer.
+func NewTransferJournalEntry(match TransferMatch, importID int64) (domain.CreateJournalEntryParams, []domain.CreatePostingParams, error) {
+	currency := match.Sent.Currency()
+	entry := domain.CreateJournalEntryParams{
+		Name:        "Transfer: " + match.Out.Name,
+		Description: fmt.Sprintf("transfer matched from journal entries %d and %d", match.Out.ID, match.In.ID),
+		Date:        match.Out.Date,
+		Provenance:  domain.Provenance{ImportID: importID},
+	}
+	postings := []domain.CreatePostingParams{
+		{Name: match.In.Name, AccountID: match.InAccountID, Debit: match.Received, Credit: money.Zero(currency)},
+		{Name: match.Out.Name, AccountID: match.OutAccountID, Debit: money.Zero(currency), Credit: match.Sent},
+	}
+
+	difference, err := match.Sent.Sub(match.Received)
+	if err != nil {
+		return domain.CreateJournalEntryParams{}, nil, err
+	}
+
+	switch difference.Sign() {
+	case 1:
+		postings = append(postings, domain.CreatePostingParams{Name: match.Out.Name, AccountID: domain.AccountID_Expense_Uncategorized, Debit: difference, Credit: money.Zero(currency)})
+	case -1:
+		earned, err := difference.Neg()
+		if err != nil {
+			return domain.CreateJournalEntryParams{}, nil, err
+		}
+
+		postings = append(postings, domain.CreatePostingParams{Name: match.In.Name, AccountID: domain.AccountID_Income_Uncategorized, Debit: money.Zero(currency), Credit: earned})
+	}
+
+	return entry, postings, nil
+}
+
+// Finds transfers (see MatchTransfers) and collapses each into a single entry (see NewTransferJournalEntry),
+// reversing the two entries it replaces. Unless dryRun, in which case the matches are only returned.
+// importID is the import the collapse is part of - 0 if it isn't part of one.
+// The reversals keep the fingerprints of the entries they reverse, so their transactions still count as imported,
+// and point at the transfer that replaced them, so reverting either side's import can undo the transfer (see Revert).
+func CollapseTransfers(ctx context.Context, repo domain.AccountingRepository, opts TransferOptions, importID int64, dryRun bool) ([]TransferMatch, error) {
+	var matches []TransferMatch
+	err := repo.WithTx(ctx, func(tx domain.AccountingRepository) error {
+		entries, err := tx.ListJournalEntries(ctx)
+		if err != nil {
+			return fmt.Errorf("error listing journal entries: %+v", err)
+		}
+
+		accounts, err := tx.ListAccounts(ctx)
+		if err != nil {
+			return fmt.Errorf("error listing accounts: %+v", err)
+		}
+
+		if matches, err = MatchTransfers(entries, accounts, opts); err != nil || dryRun {
+			return err
+		}
+
+		for _, match := range matches {
+			transfer, postings, err := NewTransferJournalEntry(match, importID)
+			if err != nil {
+				return err
+			}
+
+			transferID, err := tx.CreateJournalEntry(ctx, transfer, postings)
+			if err != nil {
+				return fmt.Errorf("error recording transfer from journal entries %d and %d: %w", match.Out.ID, match.In.ID, err)
+			}
+
+			for _, entry := range []domain.JournalEntry{match.Out, match.In} {
+				reversal, postings := domain.NewReversalJournalEntry(entry)
+				reversal.Fingerprint, reversal.ContentHash = entry.Fingerprint, entry.ContentHash
+				reversal.ReplacedBy = transferID
+				if _, err := tx.CreateJournalEntry(ctx, reversal, postings); err != nil {
+					return fmt.Errorf("error reversing journal entry %d: %w", entry.ID, err)
+				}
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return matches, nil
+}
+
+// Whole days between a and b, either way round.
+func daysApart(a, b time.Time) int {
+	days := int(b.Sub(a).Hours() / 24)
+	if days < 0 {
+		return -days
+	}
+
+	return days
+}
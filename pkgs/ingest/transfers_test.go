@@ -0,0 +1,121 @@
+package ingest_test
+
+import (
+	"log/slog"
+	domain "personal-finance/pkgs/domains"
+	"personal-finance/pkgs/ingest"
+	"personal-finance/pkgs/money"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportCollapsesTransfers(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	slogger := slog.New(slog.NewTextHandler(new(strings.Builder), &slog.HandlerOptions{}))
+	repo := domain.NewInMemoryAccountingRepository()
+
+	date := time.Date(2025, 10, 22, 0, 0, 0, 0, time.UTC)
+	bank := ingest.Statement{Transactions: []ingest.Transaction{
+		{Date: date, Description: "BILL PAYMENT DBS CARD", Amount: money.MustParse("-500", money.SGD)},
+		{Date: date, Description: "salary", Amount: money.MustParse("80", money.SGD)},
+	}}
+	card := ingest.Statement{Transactions: []ingest.Transaction{
+		{Date: date.AddDate(0, 0, 2), Description: "PAYMENT - OCBC", Amount: money.MustParse("500", money.SGD), Type: ingest.TransactionType_Payment},
+		{Date: date, Description: "shoes", Amount: money.MustParse("-80", money.SGD), Type: ingest.TransactionType_Purchase},
+	}}
+	bankOpts := ingest.Options{Parser: "ocbc", AccountID: domain.AccountID_Asset_BankAccount, Transfers: &ingest.DefaultTransferOptions}
+	cardOpts := ingest.Options{Parser: "dbs", AccountID: domain.AccountID_Liability_CreditCard, Transfers: &ingest.DefaultTransferOptions}
+
+	summary, err := ingest.Import(ctx, slogger, repo, bank, bankOpts)
+	require.NoError(t, err)
+	require.Equal(t, 0, summary.Transfers)
+
+	summary, err = ingest.Import(ctx, slogger, repo, card, cardOpts)
+	require.NoError(t, err)
+	require.Equal(t, 1, summary.Transfers)
+
+	// re-importing either side doesn't bring back what the transfer replaced
+	summary, err = ingest.Import(ctx, slogger, repo, bank, bankOpts)
+	require.NoError(t, err)
+	require.Equal(t, ingest.Summary{ImportID: 3, Rows: 2, Duplicates: 2}, summary)
+
+	entries, err := repo.ListJournalEntries(ctx)
+	require.NoError(t, err)
+
+	reversals := domain.Reversals(entries)
+	transfers := []domain.JournalEntry{}
+	for _, entry := range entries {
+		if _, reversed := reversals[entry.ID]; !reversed && entry.Reverses == 0 && strings.HasPrefix(entry.Name, "Transfer: ") {
+			transfers = append(transfers, entry)
+		}
+	}
+	require.Len(t, transfers, 1)
+	require.Equal(t, date, transfers[0].Date)
+
+	// the card is paid straight from the bank account - no spending, and nothing left in clearing
+	require.Len(t, transfers[0].Postings, 2)
+	require.EqualValues(t, domain.AccountID_Liability_CreditCard, transfers[0].Postings[0].AccountID)
+	require.Equal(t, money.MustParse("500", money.SGD), transfers[0].Postings[0].Debit)
+	require.EqualValues(t, domain.AccountID_Asset_BankAccount, transfers[0].Postings[1].AccountID)
+	require.Equal(t, money.MustParse("500", money.SGD), transfers[0].Postings[1].Credit)
+}
+
+func TestMatchTransfers(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	repo := domain.NewInMemoryAccountingRepository()
+	accounts, err := repo.ListAccounts(ctx)
+	require.NoError(t, err)
+
+	date := time.Date(2025, 10, 22, 0, 0, 0, 0, time.UTC)
+	entry := func(id int64, days int, accountID, otherAccountID int64, amount string) domain.JournalEntry {
+		a := money.MustParse(amount, money.SGD)
+		into, outOf := accountID, otherAccountID
+		if a.IsNegative() {
+			into, outOf = otherAccountID, accountID
+			a, _ = a.Neg()
+		}
+
+		return domain.JournalEntry{
+			ID:          id,
+			Date:        date.AddDate(0, 0, days),
+			Fingerprint: "fingerprint",
+			Postings: []domain.Posting{
+				{AccountID: into, Debit: a, Credit: money.Zero(money.SGD)},
+				{AccountID: outOf, Debit: money.Zero(money.SGD), Credit: a},
+			},
+		}
+	}
+
+	entries := []domain.JournalEntry{
+		entry(1, 0, domain.AccountID_Asset_BankAccount, domain.AccountID_Expense_Uncategorized, "-500"),
+		entry(2, 5, domain.AccountID_Liability_CreditCard, domain.AccountID_Asset_Clearing, "500"),        // too late
+		entry(3, 1, domain.AccountID_Liability_CreditCard, domain.AccountID_Asset_Clearing, "499.50"),     // a fee short
+		entry(4, 0, domain.AccountID_Liability_CreditCard, domain.AccountID_Expense_Uncategorized, "-80"), // a purchase...
+		entry(5, 0, domain.AccountID_Asset_BankAccount, domain.AccountID_Income_Uncategorized, "80"),      // ...isn't a transfer into the bank
+	}
+
+	matches, err := ingest.MatchTransfers(entries, accounts, ingest.DefaultTransferOptions)
+	require.NoError(t, err)
+	require.Empty(t, matches)
+
+	matches, err = ingest.MatchTransfers(entries, accounts, ingest.TransferOptions{WindowDays: 3, Tolerance: money.MustParse("1", money.SGD)})
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	require.EqualValues(t, 1, matches[0].Out.ID)
+	require.EqualValues(t, 3, matches[0].In.ID)
+
+	// the fee is spent
+	_, postings, err := ingest.NewTransferJournalEntry(matches[0], 0)
+	require.NoError(t, err)
+	require.NoError(t, domain.ValidatePostings(postings))
+	require.Len(t, postings, 3)
+	require.EqualValues(t, domain.AccountID_Expense_Uncategorized, postings[2].AccountID)
+	require.Equal(t, money.MustParse("0.50", money.SGD), postings[2].Debit)
+}
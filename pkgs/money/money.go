@@ -0,0 +1,289 @@
+// Package money represents amounts of money exactly, as a whole number of micro-units (1/1,000,000) of a currency.
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+type Currency string
+
+const SGD Currency = "SGD"
+
+// Number of micro-units in one unit of currency (i.e. 6 decimal places).
+const MicrosPerUnit = 1_000_000
+
+const microsDecimalPlaces = 6
+
+var (
+	ErrInvalidAmount    = errors.New("invalid amount")
+	ErrOverflow         = errors.New("amount overflows")
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+)
+
+// The zero value is zero of no particular currency - it can be added to an amount of any currency.
+type Amount struct {
+	micros   int64
+	currency Currency
+}
+
+func New(micros int64, currency Currency) Amount {
+	return Amount{micros: micros, currency: currency}
+}
+
+func Zero(currency Currency) Amount {
+	return Amount{currency: currency}
+}
+
+// Parses amounts as they appear in bank statements, e.g. "6,002.94", "12.4", "-3.5" and "(1,000.00)" (negative).
+// Amounts with more than 6 decimal places are rejected rather than rounded.
+func Parse(s string, currency Currency) (Amount, error) {
+	str := strings.TrimSpace(s)
+
+	negative := false
+	if strings.HasPrefix(str, "(") && strings.HasSuffix(str, ")") {
+		negative = true
+		str = strings.TrimSpace(str[1 : len(str)-1])
+	}
+	if strings.HasPrefix(str, "-") || strings.HasPrefix(str, "+") {
+		if negative {
+			return Amount{}, fmt.Errorf("%w: '%s' has more than one sign", ErrInvalidAmount, s)
+		}
+
+		negative = str[0] == '-'
+		str = str[1:]
+	}
+
+	str = strings.ReplaceAll(str, ",", "")
+	whole, fraction, hasPoint := strings.Cut(str, ".")
+	if whole == "" && fraction == "" {
+		return Amount{}, fmt.Errorf("%w: '%s' has no digits", ErrInvalidAmount, s)
+	}
+	if hasPoint && fraction == "" {
+		return Amount{}, fmt.Errorf("%w: '%s' has no digits after the decimal point", ErrInvalidAmount, s)
+	}
+	if len(fraction) > microsDecimalPlaces {
+		return Amount{}, fmt.Errorf("%w: '%s' has more than %d decimal places", ErrInvalidAmount, s, microsDecimalPlaces)
+	}
+
+	for _, digits := range []string{whole, fraction} {
+		if strings.ContainsFunc(digits, func(r rune) bool { return r < '0' || r > '9' }) {
+			return Amount{}, fmt.Errorf("%w: '%s' is not a number", ErrInvalidAmount, s)
+		}
+	}
+
+	// pad the fraction out to micro-units so "12.4" becomes 12 and 400000 micros
+	digits := whole + fraction + strings.Repeat("0", microsDecimalPlaces-len(fraction))
+	micros, err := strconv.ParseInt(digits, 10, 64)
+	if errors.Is(err, strconv.ErrRange) {
+		return Amount{}, fmt.Errorf("%w: '%s'", ErrOverflow, s)
+	}
+	if err != nil {
+		return Amount{}, fmt.Errorf("%w: '%s': %+v", ErrInvalidAmount, s, err)
+	}
+
+	if negative {
+		micros = -micros
+	}
+
+	return Amount{micros: micros, currency: currency}, nil
+}
+
+// Like Parse, but panics on error. Meant for constants and tests.
+func MustParse(s string, currency Currency) Amount {
+	a, err := Parse(s, currency)
+	if err != nil {
+		panic(err)
+	}
+
+	return a
+}
+
+func (a Amount) Micros() int64 {
+	return a.micros
+}
+
+func (a Amount) Currency() Currency {
+	return a.currency
+}
+
+func (a Amount) IsZero() bool {
+	return a.micros == 0
+}
+
+func (a Amount) IsNegative() bool {
+	return a.micros < 0
+}
+
+// Returns -1, 0 or +1 depending on the sign of the amount.
+func (a Amount) Sign() int {
+	switch {
+	case a.micros < 0:
+		return -1
+	case a.micros > 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func (a Amount) Add(b Amount) (Amount, error) {
+	currency, err := commonCurrency(a, b)
+	if err != nil {
+		return Amount{}, err
+	}
+
+	sum := a.micros + b.micros
+	if (b.micros > 0 && sum < a.micros) || (b.micros < 0 && sum > a.micros) {
+		return Amount{}, fmt.Errorf("%w: %s + %s", ErrOverflow, a, b)
+	}
+
+	return Amount{micros: sum, currency: currency}, nil
+}
+
+func (a Amount) Sub(b Amount) (Amount, error) {
+	negB, err := b.Neg()
+	if err != nil {
+		return Amount{}, err
+	}
+
+	return a.Add(negB)
+}
+
+func (a Amount) Neg() (Amount, error) {
+	if a.micros == math.MinInt64 {
+		return Amount{}, fmt.Errorf("%w: -(%s)", ErrOverflow, a)
+	}
+
+	return Amount{micros: -a.micros, currency: a.currency}, nil
+}
+
+func (a Amount) Abs() (Amount, error) {
+	if a.micros < 0 {
+		return a.Neg()
+	}
+
+	return a, nil
+}
+
+// Returns -1, 0 or +1 if a is less than, equal to or greater than b.
+func (a Amount) Cmp(b Amount) (int, error) {
+	if _, err := commonCurrency(a, b); err != nil {
+		return 0, err
+	}
+
+	switch {
+	case a.micros < b.micros:
+		return -1, nil
+	case a.micros > b.micros:
+		return 1, nil
+	default:
+		return 0, nil
+	}
+}
+
+// Sums amounts of the same currency.
+func Sum(amounts ...Amount) (Amount, error) {
+	total := Amount{}
+	for _, a := range amounts {
+		var err error
+		if total, err = total.Add(a); err != nil {
+			return Amount{}, err
+		}
+	}
+
+	return total, nil
+}
+
+// Formats the amount as a number with thousands separators and at least 2 decimal places, e.g. "-6,002.94".
+func (a Amount) Number() string {
+	return a.format(true)
+}
+
+// Formats the amount as a plain number with at least 2 decimal places, e.g. "-6002.94". Parse reads it back.
+func (a Amount) Decimal() string {
+	return a.format(false)
+}
+
+func (a Amount) format(grouped bool) string {
+	micros := a.micros
+	sign := ""
+	if micros < 0 {
+		sign = "-"
+	}
+
+	// work with the unsigned magnitude so math.MinInt64 formats correctly
+	magnitude := uint64(micros)
+	if micros < 0 {
+		magnitude = uint64(-(micros + 1)) + 1
+	}
+
+	whole := strconv.FormatUint(magnitude/MicrosPerUnit, 10)
+	fraction := fmt.Sprintf("%06d", magnitude%MicrosPerUnit)
+	fraction = strings.TrimRight(fraction, "0")
+	for len(fraction) < 2 {
+		fraction += "0"
+	}
+
+	var sb strings.Builder
+	for idx, r := range whole {
+		if grouped && idx > 0 && (len(whole)-idx)%3 == 0 {
+			sb.WriteRune(',')
+		}
+		sb.WriteRune(r)
+	}
+
+	return sign + sb.String() + "." + fraction
+}
+
+// e.g. "SGD 6,002.94"
+func (a Amount) String() string {
+	if a.currency == "" {
+		return a.Number()
+	}
+
+	return string(a.currency) + " " + a.Number()
+}
+
+type jsonAmount struct {
+	Value    string   `json:"value"`
+	Currency Currency `json:"currency"`
+}
+
+// Amounts are encoded as {"value": "-6002.94", "currency": "SGD"} - strings so no precision is lost.
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonAmount{Value: a.Decimal(), Currency: a.currency})
+}
+
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	var v jsonAmount
+	if err := json.Unmarshal(data, &v); err != nil {
+		return fmt.Errorf("%w: %+v", ErrInvalidAmount, err)
+	}
+
+	parsed, err := Parse(v.Value, v.Currency)
+	if err != nil {
+		return err
+	}
+
+	*a = parsed
+	return nil
+}
+
+// Currency both amounts share. Zero values without a currency take on the other amount's currency.
+func commonCurrency(a, b Amount) (Currency, error) {
+	switch {
+	case a.currency == b.currency:
+		return a.currency, nil
+	case a.currency == "" && a.micros == 0:
+		return b.currency, nil
+	case b.currency == "" && b.micros == 0:
+		return a.currency, nil
+	default:
+		return "", fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, a, b)
+	}
+}
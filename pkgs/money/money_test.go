@@ -0,0 +1,143 @@
+package money_test
+
+import (
+	"encoding/json"
+	"math"
+	"personal-finance/pkgs/money"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]int64{
+		"6,002.94":             6_002_940_000,
+		"12.4":                 12_400_000,
+		"4.8":                  4_800_000,
+		"14.7":                 14_700_000,
+		"100":                  100_000_000,
+		"0.000001":             1,
+		"(1,000.00)":           -1_000_000_000,
+		"-3.5":                 -3_500_000,
+		" +27.8 ":              27_800_000,
+		".5":                   500_000,
+		"18,477.16":            18_477_160_000,
+		"9223372036854.775807": math.MaxInt64,
+	}
+
+	for input, want := range tests {
+		t.Run(input, func(t *testing.T) {
+			t.Parallel()
+
+			have, err := money.Parse(input, money.SGD)
+			require.NoError(t, err)
+			require.Equal(t, want, have.Micros())
+			require.Equal(t, money.SGD, have.Currency())
+		})
+	}
+}
+
+func TestParseError(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]error{
+		"":                     money.ErrInvalidAmount,
+		"abc":                  money.ErrInvalidAmount,
+		"1.":                   money.ErrInvalidAmount,
+		"1.2.3":                money.ErrInvalidAmount,
+		"(-1.00)":              money.ErrInvalidAmount,
+		"0.0000001":            money.ErrInvalidAmount, // more precision than we can store
+		"9223372036854.775808": money.ErrOverflow,
+	}
+
+	for input, want := range tests {
+		t.Run(input, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := money.Parse(input, money.SGD)
+			require.ErrorIs(t, err, want)
+		})
+	}
+}
+
+func TestAmount_Number(t *testing.T) {
+	t.Parallel()
+
+	tests := map[int64]string{
+		0:              "0.00",
+		1:              "0.000001",
+		12_400_000:     "12.40",
+		-1_000_000_000: "-1,000.00",
+		6_002_940_000:  "6,002.94",
+		math.MinInt64:  "-9,223,372,036,854.775808",
+	}
+
+	for input, want := range tests {
+		require.Equal(t, want, money.New(input, money.SGD).Number())
+	}
+
+	require.Equal(t, "SGD -1,000.00", money.New(-1_000_000_000, money.SGD).String())
+}
+
+func TestAmount_Arithmetic(t *testing.T) {
+	t.Parallel()
+
+	a := money.MustParse("4.8", money.SGD)
+	b := money.MustParse("14.7", money.SGD)
+
+	sum, err := a.Add(b)
+	require.NoError(t, err)
+	require.Equal(t, money.MustParse("19.5", money.SGD), sum)
+
+	diff, err := a.Sub(b)
+	require.NoError(t, err)
+	require.Equal(t, money.MustParse("-9.9", money.SGD), diff)
+
+	cmp, err := a.Cmp(b)
+	require.NoError(t, err)
+	require.Equal(t, -1, cmp)
+
+	// zero values take on the other amount's currency
+	sum, err = money.Amount{}.Add(a)
+	require.NoError(t, err)
+	require.Equal(t, a, sum)
+
+	total, err := money.Sum(a, b, money.MustParse("(19.50)", money.SGD))
+	require.NoError(t, err)
+	require.True(t, total.IsZero())
+}
+
+func TestAmount_ArithmeticErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := money.New(math.MaxInt64, money.SGD).Add(money.New(1, money.SGD))
+	require.ErrorIs(t, err, money.ErrOverflow)
+
+	_, err = money.New(math.MinInt64, money.SGD).Sub(money.New(1, money.SGD))
+	require.ErrorIs(t, err, money.ErrOverflow)
+
+	_, err = money.New(math.MinInt64, money.SGD).Neg()
+	require.ErrorIs(t, err, money.ErrOverflow)
+
+	_, err = money.New(1, money.SGD).Add(money.New(1, "USD"))
+	require.ErrorIs(t, err, money.ErrCurrencyMismatch)
+
+	_, err = money.New(1, money.SGD).Cmp(money.New(1, "USD"))
+	require.ErrorIs(t, err, money.ErrCurrencyMismatch)
+}
+
+func TestAmount_JSON(t *testing.T) {
+	t.Parallel()
+
+	a := money.MustParse("(6,002.94)", money.SGD)
+
+	data, err := json.Marshal(a)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"value": "-6002.94", "currency": "SGD"}`, string(data))
+
+	var b money.Amount
+	require.NoError(t, json.Unmarshal(data, &b))
+	require.Equal(t, a, b)
+}
@@ -0,0 +1,153 @@
+package ocbc
+
+import (
+	"regexp"
+	"strings"
+)
+
+// How the money moved, going by the first line of an OCBC description.
+type TransactionKind string
+
+const (
+	TransactionKind_Unknown  TransactionKind = ""
+	TransactionKind_FAST     TransactionKind = "FAST"
+	TransactionKind_GIRO     TransactionKind = "GIRO"
+	TransactionKind_PayNow   TransactionKind = "PayNow"
+	TransactionKind_NETS     TransactionKind = "NETS"
+	TransactionKind_ATM      TransactionKind = "ATM"
+	TransactionKind_Interest TransactionKind = "interest"
+)
+
+type Direction string
+
+const (
+	Direction_Unknown Direction = ""
+	Direction_In      Direction = "in"  // money came from Counterparty
+	Direction_Out     Direction = "out" // money went to Counterparty
+)
+
+// An OCBC description broken into its parts, e.g.
+//
+//	FAST PAYMENT
+//	OTHR-Other                        to PERSON_A PAYNOW_ID_001
+//
+// is a FAST payment out to "PERSON_A PAYNOW_ID_001" with purpose code "OTHR" and reference "Other".
+// Parts the description doesn't have are left blank.
+type Description struct {
+	Kind         TransactionKind `json:"kind"`
+	Direction    Direction       `json:"direction"`
+	Counterparty string          `json:"counterparty"`
+	PurposeCode  string          `json:"purpose_code"` // e.g. "OTHR", "SALARY", "ITX"
+	Reference    string          `json:"reference"`
+}
+
+var (
+	// columns of the second line are padded with runs of spaces
+	columnSeparator = regexp.MustCompile(`\s{2,}`)
+
+	// e.g. "OTHR-Other", "OTHR - lunch", "PAYR REFERENCE_001", "ITX" - a code on its own or followed by a space
+	// has to be one we know (see purposeCodes), or a merchant like "IKEA TAMPINES" would be read as code "IKEA"
+	purposeCode = regexp.MustCompile(`^([A-Z]{2,6})(\s*-\s*|\s+|$)(.*)$`)
+
+	// e.g. "to COMPANY_B via PayNow-UEN"
+	counterparty = regexp.MustCompile(`^(to|from)\s+(.*?)(?:\s*\bvia\s+(.*))?$`)
+)
+
+// Purpose codes FAST and GIRO payments are sent with: ISO 20022 codes, and the few of OCBC's own that show up in descriptions
+var purposeCodes = map[string]bool{
+	"BEXP": true, "BONU": true, "CHAR": true, "COLL": true, "COMM": true, "DIVD": true, "EDUC": true, "GDDS": true, "GOVT": true,
+	"INSU": true, "INTC": true, "IVPT": true, "ITX": true, "LOAN": true, "OTHR": true, "PAYR": true, "PENS": true, "RENT": true,
+	"SALA": true, "SALARY": true, "SCVE": true, "SUPP": true, "TAXS": true, "UBIL": true,
+}
+
+// The purpose code and reference column starts with, e.g. "OTHR" and "lunch" for "OTHR - lunch" - false if it doesn't start with one.
+// Codes we don't know are only taken from the CODE-Text form.
+func parsePurposeCode(column string) (code string, reference string, ok bool) {
+	match := purposeCode.FindStringSubmatch(column)
+	if match == nil || (!purposeCodes[match[1]] && !strings.Contains(match[2], "-")) {
+		return "", "", false
+	}
+
+	return match[1], match[3], true
+}
+
+// Breaks an OCBC description into its parts (see Description).
+// Descriptions that don't follow OCBC's usual layout are still read as far as they go.
+func ParseDescription(s string) Description {
+	first, rest, _ := strings.Cut(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+
+	description := Description{Kind: transactionKind(first)}
+	columns := []string{}
+	for _, column := range columnSeparator.Split(strings.TrimSpace(rest), -1) {
+		if column != "" {
+			columns = append(columns, column)
+		}
+	}
+
+	for idx, column := range columns {
+		if match := counterparty.FindStringSubmatch(column); match != nil {
+			description.Direction = Direction_In
+			if match[1] == "to" {
+				description.Direction = Direction_Out
+			}
+
+			description.Counterparty = match[2]
+			via := match[3]
+			if via == "" && idx+1 < len(columns) {
+				via, _ = strings.CutPrefix(columns[idx+1], "via ")
+			}
+			if strings.HasPrefix(strings.ToUpper(via), "PAYNOW") {
+				description.Kind = TransactionKind_PayNow
+			}
+
+			columns = columns[:idx]
+			break
+		}
+	}
+
+	for idx, column := range columns {
+		switch {
+		case idx == 0:
+			if code, reference, ok := parsePurposeCode(column); ok {
+				description.PurposeCode, description.Reference = code, reference
+			} else {
+				description.Reference = column
+			}
+		case idx == 1 && description.Counterparty == "":
+			description.Counterparty = column
+		case description.Reference == "":
+			description.Reference = column
+		default:
+			description.Reference += " " + column
+		}
+	}
+
+	return description
+}
+
+func transactionKind(firstLine string) TransactionKind {
+	words := strings.Fields(strings.ToUpper(strings.ReplaceAll(firstLine, "/", " ")))
+	for _, word := range words {
+		switch word {
+		case "PAYNOW":
+			return TransactionKind_PayNow
+		case "FAST":
+			return TransactionKind_FAST
+		case "GIRO":
+			return TransactionKind_GIRO
+		case "NETS":
+			return TransactionKind_NETS
+		case "ATM":
+			return TransactionKind_ATM
+		case "INTEREST":
+			return TransactionKind_Interest
+		}
+	}
+
+	// OCBC moves money between banks over FAST unless it says otherwise
+	if strings.Contains(strings.Join(words, " "), "TRANSFER") {
+		return TransactionKind_FAST
+	}
+
+	return TransactionKind_Unknown
+}
@@ -0,0 +1,77 @@
+package ocbc_test
+
+import (
+	"personal-finance/pkgs/ocbc"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDescription(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		description string
+		want        ocbc.Description
+	}{
+		"fast payment": {
+			description: "FAST PAYMENT\nOTHR-Other                        to PERSON_A PAYNOW_ID_001",
+			want:        ocbc.Description{Kind: ocbc.TransactionKind_FAST, Direction: ocbc.Direction_Out, Counterparty: "PERSON_A PAYNOW_ID_001", PurposeCode: "OTHR", Reference: "Other"},
+		},
+		"incoming transfer": {
+			description: "PAYMENT/TRANSFER\nPAYR REFERENCE_001                from COMPANY_A BANK_A",
+			want:        ocbc.Description{Kind: ocbc.TransactionKind_FAST, Direction: ocbc.Direction_In, Counterparty: "COMPANY_A BANK_A", PurposeCode: "PAYR", Reference: "REFERENCE_001"},
+		},
+		"interest": {
+			description: "BONUS INTEREST\nPRODUCT_BONUS_001",
+			want:        ocbc.Description{Kind: ocbc.TransactionKind_Interest, Reference: "PRODUCT_BONUS_001"},
+		},
+		"giro": {
+			description: "IBG GIRO\nITX                               GOV_AGENCY_A        TAX_ID_001",
+			want:        ocbc.Description{Kind: ocbc.TransactionKind_GIRO, Counterparty: "GOV_AGENCY_A", PurposeCode: "ITX", Reference: "TAX_ID_001"},
+		},
+		"paynow in its own column": {
+			description: "FUND TRANSFER\nOTHR - lunch                      to PERSON_B        via PayNow-Mobile",
+			want:        ocbc.Description{Kind: ocbc.TransactionKind_PayNow, Direction: ocbc.Direction_Out, Counterparty: "PERSON_B", PurposeCode: "OTHR", Reference: "lunch"},
+		},
+		"paynow inline": {
+			description: "FAST PAYMENT\nOTHR-TRACKING_ID_001              to COMPANY_B via PayNow-UEN",
+			want:        ocbc.Description{Kind: ocbc.TransactionKind_PayNow, Direction: ocbc.Direction_Out, Counterparty: "COMPANY_B", PurposeCode: "OTHR", Reference: "TRACKING_ID_001"},
+		},
+		"salary": {
+			description: "GIRO - SALARY\nSALARY                            COMPANY_A",
+			want:        ocbc.Description{Kind: ocbc.TransactionKind_GIRO, Counterparty: "COMPANY_A", PurposeCode: "SALARY"},
+		},
+		"nets": {
+			description: "NETS DEBIT\nSUPERMARKET_A",
+			want:        ocbc.Description{Kind: ocbc.TransactionKind_NETS, Reference: "SUPERMARKET_A"},
+		},
+		"nets merchant": {
+			description: "NETS DEBIT\nIKEA TAMPINES",
+			want:        ocbc.Description{Kind: ocbc.TransactionKind_NETS, Reference: "IKEA TAMPINES"},
+		},
+		"giro merchant": {
+			description: "IBG GIRO\nSP GROUP",
+			want:        ocbc.Description{Kind: ocbc.TransactionKind_GIRO, Reference: "SP GROUP"},
+		},
+		"unknown purpose code": {
+			description: "FAST PAYMENT\nGIFT-birthday                     to PERSON_A",
+			want:        ocbc.Description{Kind: ocbc.TransactionKind_FAST, Direction: ocbc.Direction_Out, Counterparty: "PERSON_A", PurposeCode: "GIFT", Reference: "birthday"},
+		},
+		"atm": {
+			description: "ATM WITHDRAWAL\r\nATM_ID_001",
+			want:        ocbc.Description{Kind: ocbc.TransactionKind_ATM, Reference: "ATM_ID_001"},
+		},
+		"single line": {
+			description: "SUPER SIMPLE           SINGAPORE     SG",
+			want:        ocbc.Description{},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tt.want, ocbc.ParseDescription(tt.description))
+		})
+	}
+}
@@ -2,6 +2,10 @@ package ocbc
 
 import (
 	"fmt"
+	domain "personal-finance/pkgs/domains"
+	"personal-finance/pkgs/ingest"
+	"personal-finance/pkgs/money"
+	"strings"
 	"time"
 
 	gocsv "github.com/JoelLau/go-csv"
@@ -12,12 +16,118 @@ type OCBCAccountTransactionItem struct {
 	TransactionDate OCBCAccountTransactionsDateLayout `csv:"Transaction date"` // e.g. "22/12/25"
 	ValueDate       OCBCAccountTransactionsDateLayout `csv:"Value date"`       // e.g. "23/12/25"
 	Description     string                            `csv:"Description"`      // e.g. "SUPER SIMPLE           SINGAPORE     SG"
-	WithdrawalsSGD  string                            `csv:"Withdrawals(SGD)"` // e.g. "6,002.94"
-	DepositsSGD     string                            `csv:"Deposits(SGD)"`    // e.g. ""
+	WithdrawalsSGD  OCBCAccountTransactionsAmount     `csv:"Withdrawals(SGD)"` // e.g. "6,002.94"
+	DepositsSGD     OCBCAccountTransactionsAmount     `csv:"Deposits(SGD)"`    // e.g. ""
+
+	ParsedDescription Description `csv:"-"` // see ParseDescription
 }
 
 const OCBCAccountStatementDateLayout = "2/1/2006"
 
+func init() {
+	ingest.Register(AccountStatementParser{})
+}
+
+// Reads OCBC bank account statements - registered with pkgs/ingest as "ocbc".
+type AccountStatementParser struct{}
+
+var _ ingest.StatementParser = AccountStatementParser{}
+
+func (AccountStatementParser) Name() string {
+	return "ocbc"
+}
+
+func (AccountStatementParser) Description() string {
+	return "OCBC bank account statement"
+}
+
+func (AccountStatementParser) AccountID() int64 {
+	return domain.AccountID_Asset_BankAccount
+}
+
+// First cell of the preamble row naming the account, e.g. "Account details for:,ACCOUNT_HOLDER ACCOUNT_ID"
+const accountStatementPreambleMarker = "Account details for:"
+
+func (AccountStatementParser) Detect(table [][]string) bool {
+	headerRow := ingest.FindHeaderRow(table, ingest.Columns[OCBCAccountTransactionItem]())
+	return headerRow >= 0 && ingest.HasPreamble(table, headerRow, accountStatementPreambleMarker)
+}
+
+func (AccountStatementParser) Parse(table [][]string) (ingest.Statement, error) {
+	txs, err := ParseAccountStatement(table)
+	if err != nil {
+		return ingest.Statement{}, err
+	}
+
+	return ingest.Statement{Metadata: ParseAccountStatementMetadata(table), Transactions: txs}, nil
+}
+
+// Reads the preamble of an OCBC account statement, e.g.
+//
+//	Account details for:,360 Account 687-123401-234
+//	Available Balance,"18,477.16"
+//	Ledger Balance,"18,477.16"
+//
+// OCBC doesn't say when the statement was produced, so AsOf is left unset.
+func ParseAccountStatementMetadata(table [][]string) domain.StatementMetadata {
+	headerRow := ingest.FindHeaderRow(table, ingest.Columns[OCBCAccountTransactionItem]())
+
+	metadata := domain.StatementMetadata{}
+	if account, ok := ingest.PreambleValue(table, headerRow, accountStatementPreambleMarker); ok {
+		metadata.AccountRef = ingest.MaskAccountRef(account)
+	}
+	if balance, ok := ingest.PreambleValue(table, headerRow, "Ledger Balance"); ok {
+		metadata.LedgerBalance = ingest.MetadataAmount(balance, money.SGD)
+	}
+	if balance, ok := ingest.PreambleValue(table, headerRow, "Available Balance"); ok {
+		metadata.AvailableBalance = ingest.MetadataAmount(balance, money.SGD)
+	}
+
+	return metadata
+}
+
+// Parses the table of an OCBC account statement csv (see ingest.ReadTable) into transactions.
+// The rows above the header contain metadata like the bank account number.
+// this is sensitive information that we want nothing to do with.
+func ParseAccountStatement(table [][]string) ([]ingest.Transaction, error) {
+	rows, sources, err := ingest.UnmarshalStatement[OCBCAccountTransactionItem](table)
+	if err != nil {
+		return nil, err
+	}
+
+	txs := make([]ingest.Transaction, len(rows))
+	for idx := range rows {
+		row := &rows[idx]
+		withdrawal := row.WithdrawalsSGD.Amount
+		deposit := row.DepositsSGD.Amount
+
+		if withdrawal.IsZero() == deposit.IsZero() {
+			return nil, fmt.Errorf("transaction (%d, %s) must have either a withdrawal (%s) or a deposit (%s)", idx, row.Description, withdrawal, deposit)
+		}
+
+		amount, err := deposit.Sub(withdrawal)
+		if err != nil {
+			return nil, fmt.Errorf("error computing amount of transaction (%d, %s): %w", idx, row.Description, err)
+		}
+
+		// which way the money went is already in the amount, whether or not the description says
+		row.ParsedDescription = ParseDescription(row.Description)
+		txs[idx] = ingest.Transaction{
+			Date:         row.TransactionDate.Time,
+			PostingDate:  row.ValueDate.Time,
+			Description:  row.Description,
+			Amount:       amount,
+			Source:       sources[idx],
+			PaymentType:  string(row.ParsedDescription.Kind),
+			Counterparty: row.ParsedDescription.Counterparty,
+			PurposeCode:  row.ParsedDescription.PurposeCode,
+			Reference:    row.ParsedDescription.Reference,
+		}
+	}
+
+	return txs, nil
+}
+
 type OCBCAccountTransactionsDateLayout struct{ time.Time }
 
 var _ gocsv.CSVUnmarshaller = &OCBCAccountTransactionsDateLayout{}
@@ -31,3 +141,23 @@ func (d *OCBCAccountTransactionsDateLayout) UnmarshalCSV(data []byte) (err error
 
 	return
 }
+
+// Amount column in SGD - blank cells are zero.
+type OCBCAccountTransactionsAmount struct{ money.Amount }
+
+var _ gocsv.CSVUnmarshaller = &OCBCAccountTransactionsAmount{}
+
+func (a *OCBCAccountTransactionsAmount) UnmarshalCSV(data []byte) (err error) {
+	if strings.TrimSpace(string(data)) == "" {
+		a.Amount = money.Zero(money.SGD)
+		return
+	}
+
+	a.Amount, err = money.Parse(string(data), money.SGD)
+	if err != nil {
+		err = fmt.Errorf("failed to parse ocbc amount: %w", err)
+		return
+	}
+
+	return
+}
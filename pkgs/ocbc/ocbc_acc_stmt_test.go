@@ -2,6 +2,9 @@ package ocbc_test
 
 import (
 	"personal-finance/pkgs/dbs"
+	"personal-finance/pkgs/ingest"
+	"personal-finance/pkgs/money"
+	"personal-finance/pkgs/ocbc"
 	"testing"
 	"time"
 
@@ -30,3 +33,91 @@ func TestDBSCreditCardDate_UnmarshalCSVError(t *testing.T) {
 	err := d.UnmarshalCSV([]byte("13/12/2025")) // wrong format
 	require.Error(t, err)
 }
+
+func TestOCBCAccountTransactionsAmount_UnmarshalCSV(t *testing.T) {
+	t.Parallel()
+
+	a := ocbc.OCBCAccountTransactionsAmount{}
+
+	err := a.UnmarshalCSV([]byte("6,002.94"))
+	require.NoError(t, err)
+	require.Equal(t, money.MustParse("6002.94", money.SGD), a.Amount)
+
+	err = a.UnmarshalCSV([]byte(""))
+	require.NoError(t, err)
+	require.True(t, a.IsZero())
+
+	err = a.UnmarshalCSV([]byte("6,002.94 SGD")) // wrong format
+	require.Error(t, err)
+}
+
+func TestAccountStatementParser(t *testing.T) {
+	t.Parallel()
+
+	table, err := ingest.ReadTable("../../tests/testdata/ocbc.csv")
+	require.NoError(t, err)
+
+	parser, err := ingest.LookupParser("ocbc")
+	require.NoError(t, err)
+	require.True(t, parser.Detect(table))
+
+	statement, err := parser.Parse(table)
+	require.NoError(t, err)
+	require.Len(t, statement.Transactions, 8)
+	require.Equal(t, "FAST", statement.Transactions[0].PaymentType)
+	require.Equal(t, "PERSON_A PAYNOW_ID_001", statement.Transactions[0].Counterparty)
+	require.Equal(t, "OTHR", statement.Transactions[0].PurposeCode)
+	require.Equal(t, "Other", statement.Transactions[0].Reference)
+	require.Equal(t, "PAYR", statement.Transactions[1].PurposeCode)
+	require.Equal(t, "REFERENCE_001", statement.Transactions[1].Reference)
+	require.Equal(t, "PayNow", statement.Transactions[4].PaymentType)
+	require.Equal(t, "PERSON_B", statement.Transactions[4].Counterparty)
+
+	dbsTable, err := ingest.ReadTable("../../tests/testdata/dbs.csv")
+	require.NoError(t, err)
+	require.False(t, parser.Detect(dbsTable))
+}
+
+func TestParseAccountStatementLayoutChanges(t *testing.T) {
+	t.Parallel()
+
+	header := []string{"Transaction date", "Value date", "Description", "Withdrawals(SGD)", "Deposits(SGD)"}
+	row := []string{"16/12/2025", "16/12/2025", "COFFEE", "4.50", ""}
+
+	// an extra preamble line moves the header down
+	txs, err := ocbc.ParseAccountStatement([][]string{
+		{"Account details for:", "ACCOUNT_ID_001"},
+		{"Available Balance", "18,477.16"},
+		{"Ledger Balance", "18,477.16"},
+		{"Something New", "1"},
+		{"", ""},
+		{"Transaction History", ""},
+		header,
+		row,
+	})
+	require.NoError(t, err)
+	require.Len(t, txs, 1)
+	require.Equal(t, money.MustParse("-4.50", money.SGD), txs[0].Amount)
+	require.Equal(t, ingest.SourceRow{Row: 8, Cells: row}, txs[0].Source)
+
+	// a renamed column is reported rather than silently read as blank
+	renamed := []string{"Transaction date", "Value date", "Description", "Withdrawals", "Deposits(SGD)"}
+	_, err = ocbc.ParseAccountStatement([][]string{{"Account details for:", "ACCOUNT_ID_001"}, renamed, row})
+	require.ErrorIs(t, err, ingest.ErrUnexpectedLayout)
+	require.ErrorContains(t, err, "missing columns: Withdrawals(SGD) (unexpected columns: Withdrawals)")
+}
+
+func TestParseAccountStatementMetadata(t *testing.T) {
+	t.Parallel()
+
+	table, err := ingest.ReadTable("../../tests/testdata/ocbc.csv")
+	require.NoError(t, err)
+
+	balance := money.MustParse("18,477.16", money.SGD)
+	metadata := ocbc.ParseAccountStatementMetadata(table)
+	require.Equal(t, "****D001", metadata.AccountRef)
+	require.Equal(t, &balance, metadata.LedgerBalance)
+	require.Equal(t, &balance, metadata.AvailableBalance)
+	require.Nil(t, metadata.CreditLimit)
+	require.True(t, metadata.AsOf.IsZero())
+}
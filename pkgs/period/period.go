@@ -0,0 +1,96 @@
+// Package period filters dates by calendar month ranges, e.g. "2025-10" to "2025-12".
+package period
+
+import (
+	"fmt"
+	"time"
+)
+
+// Layout of months on the command line, e.g. "2025-10".
+const MonthLayout = "2006-01"
+
+// Half-open range of dates [From, To). A zero From or To leaves that end unbounded.
+type Range struct {
+	From time.Time
+	To   time.Time
+}
+
+// Parses a month in MonthLayout to midnight UTC on its first day.
+func ParseMonth(s string) (time.Time, error) {
+	t, err := time.Parse(MonthLayout, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid month '%s' - expected yyyy-mm: %+v", s, err)
+	}
+
+	return t, nil
+}
+
+// Builds a range out of the --month, --from and --to flags (all in MonthLayout, all optional).
+// month is shorthand for from = to = month, so it can't be combined with the other two.
+// Both ends are inclusive of their whole month.
+func NewRange(month, from, to string) (Range, error) {
+	if month != "" {
+		if from != "" || to != "" {
+			return Range{}, fmt.Errorf("month can't be combined with from/to")
+		}
+
+		from, to = month, month
+	}
+
+	r := Range{}
+	if from != "" {
+		start, err := ParseMonth(from)
+		if err != nil {
+			return Range{}, err
+		}
+
+		r.From = start
+	}
+
+	if to != "" {
+		end, err := ParseMonth(to)
+		if err != nil {
+			return Range{}, err
+		}
+
+		r.To = end.AddDate(0, 1, 0)
+	}
+
+	if !r.From.IsZero() && !r.To.IsZero() && !r.From.Before(r.To) {
+		return Range{}, fmt.Errorf("from (%s) must not be after to (%s)", from, to)
+	}
+
+	return r, nil
+}
+
+func (r Range) IsUnbounded() bool {
+	return r.From.IsZero() && r.To.IsZero()
+}
+
+// Reports whether the calendar date of t (in t's own location) falls in the range.
+func (r Range) Contains(t time.Time) bool {
+	date := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+
+	if !r.From.IsZero() && date.Before(r.From) {
+		return false
+	}
+
+	if !r.To.IsZero() && !date.Before(r.To) {
+		return false
+	}
+
+	return true
+}
+
+func (r Range) String() string {
+	switch {
+	case r.IsUnbounded():
+		return "all time"
+	case r.To.IsZero():
+		return fmt.Sprintf("from %s", r.From.Format(MonthLayout))
+	case r.From.IsZero():
+		return fmt.Sprintf("until %s", r.To.AddDate(0, -1, 0).Format(MonthLayout))
+	default:
+		return fmt.Sprintf("%s to %s", r.From.Format(MonthLayout), r.To.AddDate(0, -1, 0).Format(MonthLayout))
+	}
+}
@@ -0,0 +1,63 @@
+package period_test
+
+import (
+	"personal-finance/pkgs/period"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestNewRange_Month(t *testing.T) {
+	t.Parallel()
+
+	r, err := period.NewRange("2025-12", "", "")
+	require.NoError(t, err)
+	require.Equal(t, "2025-12 to 2025-12", r.String())
+
+	require.False(t, r.Contains(date(2025, 11, 30)))
+	require.True(t, r.Contains(date(2025, 12, 1)))
+	require.True(t, r.Contains(date(2025, 12, 31)))
+	require.False(t, r.Contains(date(2026, 1, 1)))
+}
+
+func TestNewRange_FromTo(t *testing.T) {
+	t.Parallel()
+
+	r, err := period.NewRange("", "2025-10", "2025-12")
+	require.NoError(t, err)
+
+	require.False(t, r.Contains(date(2025, 9, 30)))
+	require.True(t, r.Contains(date(2025, 10, 1)))
+	require.True(t, r.Contains(date(2025, 11, 15)))
+	require.True(t, r.Contains(date(2025, 12, 31)))
+	require.False(t, r.Contains(date(2026, 1, 1)))
+
+	// open ended
+	r, err = period.NewRange("", "2025-10", "")
+	require.NoError(t, err)
+	require.True(t, r.Contains(date(2030, 1, 1)))
+	require.False(t, r.Contains(date(2025, 9, 30)))
+
+	r, err = period.NewRange("", "", "")
+	require.NoError(t, err)
+	require.True(t, r.IsUnbounded())
+	require.True(t, r.Contains(date(1970, 1, 1)))
+}
+
+func TestNewRange_Error(t *testing.T) {
+	t.Parallel()
+
+	_, err := period.NewRange("2025-12", "2025-10", "")
+	require.Error(t, err)
+
+	_, err = period.NewRange("", "2025-12", "2025-10")
+	require.Error(t, err)
+
+	_, err = period.NewRange("12/2025", "", "")
+	require.Error(t, err)
+}
@@ -0,0 +1,208 @@
+// Package reconcile checks the ledger against the balances banks report on their statements.
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	domain "personal-finance/pkgs/domains"
+	"personal-finance/pkgs/money"
+	"time"
+)
+
+var (
+	ErrNoStatementBalance = errors.New("statement has no balance to reconcile against")
+	ErrDiscrepancy        = errors.New("ledger balance doesn't match the statement")
+)
+
+// A journal entry that moved the account's balance.
+type Line struct {
+	JournalEntryID int64        `json:"journal_entry_id"`
+	Date           time.Time    `json:"date"`
+	Name           string       `json:"name"`
+	Amount         money.Amount `json:"amount"` // change to the account's balance, in its normal direction
+}
+
+type Check struct {
+	AccountID int64     `json:"account_id"`
+	Account   string    `json:"account"` // full path, e.g. "Asset:BankAccount"
+	ImportID  int64     `json:"import_id"`
+	From      time.Time `json:"from"` // as of date of the account's last reconciliation - zero if it's never been reconciled
+	AsOf      time.Time `json:"as_of"`
+
+	StatementBalance money.Amount `json:"statement_balance"`
+	LedgerBalance    money.Amount `json:"ledger_balance"`
+	Difference       money.Amount `json:"difference"` // statement - ledger
+
+	// Entries between From and AsOf - any discrepancy comes from these,
+	// or from an opening balance that was never entered if the account has never been reconciled.
+	Unreconciled []Line `json:"unreconciled"`
+}
+
+func (c Check) Reconciled() bool {
+	return c.Difference.IsZero()
+}
+
+// The balance a statement reports for account, in the account's normal direction.
+// Bank accounts report their ledger balance; credit cards report what's owed as the credit limit less the available limit.
+func StatementBalance(account domain.LedgerAccount, metadata domain.StatementMetadata) (money.Amount, error) {
+	if account.Type == domain.AccountType_Liability && metadata.CreditLimit != nil && metadata.AvailableBalance != nil {
+		owed, err := metadata.CreditLimit.Sub(*metadata.AvailableBalance)
+		if err != nil {
+			return money.Amount{}, fmt.Errorf("error computing amount owed: %w", err)
+		}
+
+		return owed, nil
+	}
+
+	if metadata.LedgerBalance != nil {
+		return *metadata.LedgerBalance, nil
+	}
+
+	return money.Amount{}, fmt.Errorf("%w: %s account %d", ErrNoStatementBalance, account.Type, account.ID)
+}
+
+// Compares the balance of the import's account in the ledger against the balance on its statement.
+// Balances are compared as of the statement's date, or as of the account's latest entry if the statement has none.
+func CheckImport(ctx context.Context, repo domain.AccountingRepository, importID int64) (Check, error) {
+	imp, err := repo.GetImport(ctx, importID)
+	if err != nil {
+		return Check{}, fmt.Errorf("error getting import %d: %w", importID, err)
+	}
+
+	account, err := repo.GetAccount(ctx, imp.AccountID)
+	if err != nil {
+		return Check{}, fmt.Errorf("error getting account %d: %w", imp.AccountID, err)
+	}
+
+	statementBalance, err := StatementBalance(account, imp.Metadata)
+	if err != nil {
+		return Check{}, err
+	}
+
+	accounts, err := repo.ListAccounts(ctx)
+	if err != nil {
+		return Check{}, fmt.Errorf("error listing accounts: %+v", err)
+	}
+
+	path, err := domain.AccountPath(accounts, account.ID)
+	if err != nil {
+		return Check{}, err
+	}
+
+	entries, err := repo.ListJournalEntries(ctx)
+	if err != nil {
+		return Check{}, fmt.Errorf("error listing journal entries: %+v", err)
+	}
+
+	reconciliations, err := repo.ListReconciliations(ctx)
+	if err != nil {
+		return Check{}, fmt.Errorf("error listing reconciliations: %+v", err)
+	}
+
+	lines, err := accountLines(account, entries)
+	if err != nil {
+		return Check{}, err
+	}
+
+	check := Check{
+		AccountID:        account.ID,
+		Account:          path,
+		ImportID:         imp.ID,
+		AsOf:             imp.Metadata.AsOf,
+		StatementBalance: statementBalance,
+		LedgerBalance:    money.Zero(statementBalance.Currency()),
+		Unreconciled:     []Line{},
+	}
+	if last, ok := domain.LastReconciliation(reconciliations, account.ID); ok {
+		check.From = last.AsOf
+	}
+	if check.AsOf.IsZero() {
+		for _, line := range lines {
+			if line.Date.After(check.AsOf) {
+				check.AsOf = line.Date
+			}
+		}
+	}
+
+	for _, line := range lines {
+		if line.Date.After(check.AsOf) {
+			continue
+		}
+
+		if check.LedgerBalance, err = check.LedgerBalance.Add(line.Amount); err != nil {
+			return Check{}, fmt.Errorf("error computing balance of account %d: %w", account.ID, err)
+		}
+
+		if check.From.IsZero() || line.Date.After(check.From) {
+			check.Unreconciled = append(check.Unreconciled, line)
+		}
+	}
+
+	if check.Difference, err = check.StatementBalance.Sub(check.LedgerBalance); err != nil {
+		return Check{}, fmt.Errorf("error comparing balances of account %d: %w", account.ID, err)
+	}
+
+	return check, nil
+}
+
+// Like CheckImport, but also marks everything up to the statement's date as reconciled if the balances agree.
+// Returns ErrDiscrepancy (along with the check, to explain it) if they don't.
+func ReconcileImport(ctx context.Context, repo domain.AccountingRepository, importID int64, now time.Time) (check Check, err error) {
+	err = repo.WithTx(ctx, func(tx domain.AccountingRepository) error {
+		check, err = CheckImport(ctx, tx, importID)
+		if err != nil {
+			return err
+		}
+
+		if !check.Reconciled() {
+			return fmt.Errorf("%w: %s is %s on the statement but %s in the ledger", ErrDiscrepancy, check.Account, check.StatementBalance, check.LedgerBalance)
+		}
+
+		_, err := tx.CreateReconciliation(ctx, domain.CreateReconciliationParams{
+			AccountID:    check.AccountID,
+			ImportID:     check.ImportID,
+			AsOf:         check.AsOf,
+			Balance:      check.StatementBalance,
+			ReconciledAt: now,
+		})
+		if err != nil {
+			return fmt.Errorf("error recording reconciliation: %w", err)
+		}
+
+		return nil
+	})
+
+	return check, err
+}
+
+// Every entry that posted to account, in ledger order.
+func accountLines(account domain.LedgerAccount, entries []domain.JournalEntry) ([]Line, error) {
+	lines := []Line{}
+	for _, entry := range entries {
+		line := Line{JournalEntryID: entry.ID, Date: entry.Date, Name: entry.Name}
+		touched := false
+
+		for _, posting := range entry.Postings {
+			if posting.AccountID != account.ID {
+				continue
+			}
+
+			change, err := account.NormalBalanceChange(posting)
+			if err != nil {
+				return nil, err
+			}
+
+			if line.Amount, err = line.Amount.Add(change); err != nil {
+				return nil, fmt.Errorf("error summing postings of journal entry %d: %w", entry.ID, err)
+			}
+			touched = true
+		}
+
+		if touched {
+			lines = append(lines, line)
+		}
+	}
+
+	return lines, nil
+}
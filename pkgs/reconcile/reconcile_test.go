@@ -0,0 +1,90 @@
+package reconcile_test
+
+import (
+	domain "personal-finance/pkgs/domains"
+	"personal-finance/pkgs/money"
+	"personal-finance/pkgs/reconcile"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatementBalance(t *testing.T) {
+	t.Parallel()
+
+	ledger := money.MustParse("18,477.16", money.SGD)
+	limit := money.MustParse("12,000", money.SGD)
+	available := money.MustParse("11,748.10", money.SGD)
+	bank := domain.LedgerAccount{ID: domain.AccountID_Asset_BankAccount, Type: domain.AccountType_Asset}
+	card := domain.LedgerAccount{ID: domain.AccountID_Liability_CreditCard, Type: domain.AccountType_Liability}
+
+	balance, err := reconcile.StatementBalance(bank, domain.StatementMetadata{LedgerBalance: &ledger, AvailableBalance: &available})
+	require.NoError(t, err)
+	require.Equal(t, ledger, balance)
+
+	balance, err = reconcile.StatementBalance(card, domain.StatementMetadata{CreditLimit: &limit, AvailableBalance: &available})
+	require.NoError(t, err)
+	require.Equal(t, money.MustParse("251.90", money.SGD), balance)
+
+	_, err = reconcile.StatementBalance(card, domain.StatementMetadata{CreditLimit: &limit})
+	require.ErrorIs(t, err, reconcile.ErrNoStatementBalance)
+}
+
+func TestReconcileImport(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	repo := domain.NewInMemoryAccountingRepository()
+	now := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	spend := func(date time.Time, amount string) {
+		require.NoError(t, repo.CreateExpense(ctx, domain.CreateExpenseParams{
+			Name:         "spend " + amount,
+			TransactedAt: date,
+			Debit:        money.MustParse(amount, money.SGD),
+		}))
+	}
+	importStatement := func(asOf time.Time, balance string) int64 {
+		amount := money.MustParse(balance, money.SGD)
+		id, err := repo.CreateImport(ctx, domain.CreateImportParams{
+			Parser:    "ocbc",
+			AccountID: domain.AccountID_Asset_BankAccount,
+			Metadata:  domain.StatementMetadata{AsOf: asOf, LedgerBalance: &amount},
+		})
+		require.NoError(t, err)
+		return id
+	}
+
+	_, err := repo.CreateJournalEntry(ctx, domain.CreateJournalEntryParams{Name: "opening balance", Date: time.Date(2025, 11, 30, 0, 0, 0, 0, time.UTC)}, []domain.CreatePostingParams{
+		{AccountID: domain.AccountID_Asset_BankAccount, Debit: money.MustParse("100", money.SGD)},
+		{AccountID: domain.AccountID_Equity_OpeningBalanceEquity, Credit: money.MustParse("100", money.SGD)},
+	})
+	require.NoError(t, err)
+	spend(time.Date(2025, 12, 5, 0, 0, 0, 0, time.UTC), "10")
+	spend(time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC), "5") // after the statement
+
+	check, err := reconcile.ReconcileImport(ctx, repo, importStatement(time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC), "90"), now)
+	require.NoError(t, err)
+	require.True(t, check.Reconciled())
+	require.Equal(t, "Asset:BankAccount", check.Account)
+	require.Len(t, check.Unreconciled, 2)
+
+	reconciliations, err := repo.ListReconciliations(ctx)
+	require.NoError(t, err)
+	require.Len(t, reconciliations, 1)
+
+	// the next statement disagrees - only entries since the last reconciliation are suspects
+	spend(time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC), "20")
+	check, err = reconcile.ReconcileImport(ctx, repo, importStatement(time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC), "60"), now)
+	require.ErrorIs(t, err, reconcile.ErrDiscrepancy)
+	require.Equal(t, money.MustParse("65", money.SGD), check.LedgerBalance)
+	require.Equal(t, money.MustParse("-5", money.SGD), check.Difference)
+	require.Len(t, check.Unreconciled, 2)
+	require.Equal(t, "spend 5", check.Unreconciled[0].Name)
+	require.Equal(t, money.MustParse("-5", money.SGD), check.Unreconciled[0].Amount)
+
+	reconciliations, err = repo.ListReconciliations(ctx)
+	require.NoError(t, err)
+	require.Len(t, reconciliations, 1)
+}
@@ -0,0 +1,91 @@
+package report
+
+import (
+	"cmp"
+	"fmt"
+	domain "personal-finance/pkgs/domains"
+	"personal-finance/pkgs/money"
+	"personal-finance/pkgs/period"
+	"slices"
+)
+
+// Money that moved between us and a counterparty in a month.
+type CounterpartyFlow struct {
+	Counterparty string       `json:"counterparty"` // alias, if they have one (see domain.Counterparty)
+	Month        string       `json:"month"`        // in period.MonthLayout
+	In           money.Amount `json:"in"`           // received from them
+	Out          money.Amount `json:"out"`          // paid to them - positive
+	Net          money.Amount `json:"net"`          // in - out
+	Transactions int          `json:"transactions"`
+}
+
+type CounterpartyReport struct {
+	Period string             `json:"period"`
+	Flows  []CounterpartyFlow `json:"flows"` // by month, then counterparty
+}
+
+// Totals the money paid to and received from each counterparty per month, over imported entries dated within dateRange.
+// Counterparties that share an alias are totalled together.
+// Entries that have been reversed (and the reversals) are left out, since they net to nothing.
+func NewCounterpartyReport(entries []domain.JournalEntry, imports []domain.Import, counterparties []domain.Counterparty, dateRange period.Range) (CounterpartyReport, error) {
+	importAccounts := make(map[int64]int64, len(imports)) // key: import id, val: account id
+	for _, imp := range imports {
+		importAccounts[imp.ID] = imp.AccountID
+	}
+
+	type key struct{ counterparty, month string }
+	flows := map[key]CounterpartyFlow{}
+	reversals := domain.Reversals(entries)
+	for _, entry := range entries {
+		if _, reversed := reversals[entry.ID]; reversed || entry.Reverses != 0 || entry.Counterparty == "" || !dateRange.Contains(entry.Date) {
+			continue
+		}
+
+		accountID, ok := importAccounts[entry.Provenance.ImportID]
+		if !ok {
+			continue
+		}
+
+		name := entry.Counterparty
+		if counterparty, ok := domain.FindCounterparty(counterparties, entry.Counterparty); ok {
+			name = counterparty.DisplayName()
+		}
+
+		k := key{counterparty: name, month: entry.Date.Format(period.MonthLayout)}
+		flow := flows[k]
+		flow.Counterparty, flow.Month = k.counterparty, k.month
+		flow.Transactions++
+
+		for _, posting := range entry.Postings {
+			if posting.AccountID != accountID {
+				continue
+			}
+
+			var err error
+			if flow.In, err = flow.In.Add(posting.Debit); err != nil {
+				return CounterpartyReport{}, fmt.Errorf("error totalling %s: %w", name, err)
+			}
+			if flow.Out, err = flow.Out.Add(posting.Credit); err != nil {
+				return CounterpartyReport{}, fmt.Errorf("error totalling %s: %w", name, err)
+			}
+		}
+
+		flows[k] = flow
+	}
+
+	report := CounterpartyReport{Period: dateRange.String(), Flows: make([]CounterpartyFlow, 0, len(flows))}
+	for _, flow := range flows {
+		var err error
+		if flow.Net, err = flow.In.Sub(flow.Out); err != nil {
+			return CounterpartyReport{}, fmt.Errorf("error totalling %s: %w", flow.Counterparty, err)
+		}
+
+		report.Flows = append(report.Flows, flow)
+	}
+
+	slices.SortFunc(report.Flows, func(a, b CounterpartyFlow) int {
+		return cmp.Or(cmp.Compare(a.Month, b.Month), cmp.Compare(a.Counterparty, b.Counterparty))
+	})
+
+	return report, nil
+}
@@ -0,0 +1,60 @@
+package report_test
+
+import (
+	"log/slog"
+	domain "personal-finance/pkgs/domains"
+	"personal-finance/pkgs/ingest"
+	"personal-finance/pkgs/money"
+	"personal-finance/pkgs/period"
+	"personal-finance/pkgs/report"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCounterpartyReport(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	slogger := slog.New(slog.NewTextHandler(new(strings.Builder), &slog.HandlerOptions{}))
+	repo := domain.NewInMemoryAccountingRepository()
+
+	_, err := repo.SaveCounterparty(ctx, domain.SaveCounterpartyParams{Name: "PERSON_A PAYNOW_ID_001", Alias: "Landlord"})
+	require.NoError(t, err)
+	_, err = repo.SaveCounterparty(ctx, domain.SaveCounterpartyParams{Name: "PERSON_A", Alias: "Landlord"})
+	require.NoError(t, err)
+
+	date := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+	statement := ingest.Statement{Transactions: []ingest.Transaction{
+		{Date: date, Description: "rent", Amount: money.MustParse("-2000", money.SGD), Counterparty: "PERSON_A PAYNOW_ID_001"},
+		{Date: date.AddDate(0, 0, 1), Description: "deposit back", Amount: money.MustParse("500", money.SGD), Counterparty: "PERSON_A"},
+		{Date: date, Description: "lunch", Amount: money.MustParse("-30", money.SGD), Counterparty: "PERSON_B"},
+		{Date: date.AddDate(0, 1, 0), Description: "rent", Amount: money.MustParse("-2000", money.SGD), Counterparty: "PERSON_A PAYNOW_ID_001"},
+		{Date: date, Description: "coffee", Amount: money.MustParse("-4.50", money.SGD)},
+	}}
+	_, err = ingest.Import(ctx, slogger, repo, statement, ingest.Options{Parser: "ocbc"})
+	require.NoError(t, err)
+
+	entries, err := repo.ListJournalEntries(ctx)
+	require.NoError(t, err)
+	imports, err := repo.ListImports(ctx)
+	require.NoError(t, err)
+	counterparties, err := repo.ListCounterparties(ctx)
+	require.NoError(t, err)
+	dateRange, err := period.NewRange("2025-12", "", "")
+	require.NoError(t, err)
+
+	flows, err := report.NewCounterpartyReport(entries, imports, counterparties, dateRange)
+	require.NoError(t, err)
+
+	sgd := func(s string) money.Amount { return money.MustParse(s, money.SGD) }
+	require.Equal(t, report.CounterpartyReport{
+		Period: "2025-12 to 2025-12",
+		Flows: []report.CounterpartyFlow{
+			{Counterparty: "Landlord", Month: "2025-12", In: sgd("500"), Out: sgd("2000"), Net: sgd("-1500"), Transactions: 2},
+			{Counterparty: "PERSON_B", Month: "2025-12", In: sgd("0"), Out: sgd("30"), Net: sgd("-30"), Transactions: 1},
+		},
+	}, flows)
+}
@@ -0,0 +1,96 @@
+// Package report summarises the ledger for humans.
+package report
+
+import (
+	"cmp"
+	"fmt"
+	domain "personal-finance/pkgs/domains"
+	"personal-finance/pkgs/money"
+	"personal-finance/pkgs/period"
+	"slices"
+)
+
+// Money that went through an income or expense account.
+type AccountTotal struct {
+	AccountID int64              `json:"account_id"`
+	Account   string             `json:"account"` // full path, e.g. "Expense:DiningOut"
+	Type      domain.AccountType `json:"type"`
+	Total     money.Amount       `json:"total"` // in the account's normal direction - i.e. positive means earned/spent
+}
+
+type IncomeStatement struct {
+	Period   string         `json:"period"`
+	Accounts []AccountTotal `json:"accounts"`
+	Income   money.Amount   `json:"income"`
+	Expenses money.Amount   `json:"expenses"`
+	Net      money.Amount   `json:"net"` // income - expenses, i.e. how much was saved
+}
+
+// Totals the income and expense accounts over journal entries dated within dateRange.
+func NewIncomeStatement(entries []domain.JournalEntry, accounts []domain.LedgerAccount, dateRange period.Range) (IncomeStatement, error) {
+	accountByID := make(map[int64]domain.LedgerAccount, len(accounts))
+	for _, account := range accounts {
+		accountByID[account.ID] = account
+	}
+
+	totals := map[int64]money.Amount{}
+	for _, entry := range entries {
+		if !dateRange.Contains(entry.Date) {
+			continue
+		}
+
+		for _, posting := range entry.Postings {
+			account, ok := accountByID[posting.AccountID]
+			if !ok {
+				return IncomeStatement{}, fmt.Errorf("%w: %d", domain.ErrAccountNotFound, posting.AccountID)
+			}
+			if !account.Type.IsIncomeStatement() {
+				continue
+			}
+
+			change, err := account.NormalBalanceChange(posting)
+			if err != nil {
+				return IncomeStatement{}, err
+			}
+
+			if totals[account.ID], err = totals[account.ID].Add(change); err != nil {
+				return IncomeStatement{}, fmt.Errorf("error totalling account %d: %w", account.ID, err)
+			}
+		}
+	}
+
+	statement := IncomeStatement{Period: dateRange.String(), Accounts: []AccountTotal{}}
+	for accountID, total := range totals {
+		path, err := domain.AccountPath(accounts, accountID)
+		if err != nil {
+			return IncomeStatement{}, err
+		}
+
+		account := accountByID[accountID]
+		statement.Accounts = append(statement.Accounts, AccountTotal{
+			AccountID: accountID,
+			Account:   path,
+			Type:      account.Type,
+			Total:     total,
+		})
+
+		switch account.Type {
+		case domain.AccountType_Income:
+			statement.Income, err = statement.Income.Add(total)
+		case domain.AccountType_Expense:
+			statement.Expenses, err = statement.Expenses.Add(total)
+		}
+		if err != nil {
+			return IncomeStatement{}, fmt.Errorf("error totalling %s: %w", account.Type, err)
+		}
+	}
+
+	slices.SortFunc(statement.Accounts, func(a, b AccountTotal) int { return cmp.Compare(a.AccountID, b.AccountID) })
+
+	var err error
+	if statement.Net, err = statement.Income.Sub(statement.Expenses); err != nil {
+		return IncomeStatement{}, fmt.Errorf("error computing net income: %w", err)
+	}
+
+	return statement, nil
+}
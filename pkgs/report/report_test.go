@@ -0,0 +1,55 @@
+package report_test
+
+import (
+	domain "personal-finance/pkgs/domains"
+	"personal-finance/pkgs/money"
+	"personal-finance/pkgs/period"
+	"personal-finance/pkgs/report"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewIncomeStatement(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	repo := domain.NewInMemoryAccountingRepository()
+
+	require.NoError(t, repo.CreateExpense(ctx, domain.CreateExpenseParams{
+		Name:              "coffee",
+		TransactedAt:      time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC),
+		Debit:             money.MustParse("4.50", money.SGD),
+		CategoryAccountID: domain.AccountID_Expense_DiningOut,
+	}))
+	require.NoError(t, repo.CreateIncome(ctx, domain.CreateIncomeParams{
+		Name:              "salary",
+		TransactedAt:      time.Date(2025, 12, 25, 0, 0, 0, 0, time.UTC),
+		Credit:            money.MustParse("5000", money.SGD),
+		CategoryAccountID: domain.AccountID_Income_SalaryWages,
+	}))
+	require.NoError(t, repo.CreateExpense(ctx, domain.CreateExpenseParams{
+		Name:         "outside of the period",
+		TransactedAt: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		Debit:        money.MustParse("100", money.SGD),
+	}))
+
+	entries, err := repo.ListJournalEntries(ctx)
+	require.NoError(t, err)
+	accounts, err := repo.ListAccounts(ctx)
+	require.NoError(t, err)
+	dateRange, err := period.NewRange("2025-12", "", "")
+	require.NoError(t, err)
+
+	statement, err := report.NewIncomeStatement(entries, accounts, dateRange)
+	require.NoError(t, err)
+
+	require.Equal(t, []report.AccountTotal{
+		{AccountID: domain.AccountID_Income_SalaryWages, Account: "Income:SalaryWages", Type: domain.AccountType_Income, Total: money.MustParse("5000", money.SGD)},
+		{AccountID: domain.AccountID_Expense_DiningOut, Account: "Expense:DiningOut", Type: domain.AccountType_Expense, Total: money.MustParse("4.50", money.SGD)},
+	}, statement.Accounts)
+	require.Equal(t, money.MustParse("5000", money.SGD), statement.Income)
+	require.Equal(t, money.MustParse("4.50", money.SGD), statement.Expenses)
+	require.Equal(t, money.MustParse("4995.50", money.SGD), statement.Net)
+}
@@ -0,0 +1,339 @@
+// Package rules categorises transactions as they're imported, by matching them against rules from a YAML file.
+package rules
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	domain "personal-finance/pkgs/domains"
+	"personal-finance/pkgs/ingest"
+	"personal-finance/pkgs/money"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var ErrInvalidRule = errors.New("invalid rule")
+
+// A rules file, e.g.
+//
+//	rules:
+//	  - name: groceries
+//	    priority: 10
+//	    match:
+//	      description: GROCERY_CHAIN_
+//	      direction: out
+//	      source: dbs
+//	    account: Expense:Groceries
+//	    tags: [food]
+//	    rename: Groceries
+type File struct {
+	Rules []Rule `yaml:"rules"`
+}
+
+type Rule struct {
+	Name     string `yaml:"name,omitempty"`     // shown in dry runs - defaults to the rule's position in the file, e.g. "rule 3"
+	Priority int    `yaml:"priority,omitempty"` // rules are tried highest priority first, then in the order they're written
+	Match    Match  `yaml:"match,omitempty"`    // a transaction has to match everything given - a rule that gives nothing matches every transaction
+
+	Account string   `yaml:"account,omitempty"` // id or path of the account to book to, e.g. Expense:Groceries - blank to leave it to the defaults (see Engine.Match)
+	Tags    []string `yaml:"tags,omitempty"`
+	Rename  string   `yaml:"rename,omitempty"` // cleaned name for the transaction - blank to keep its name
+}
+
+type Match struct {
+	Description  string `yaml:"description,omitempty"`  // regular expression, matched case-insensitively anywhere in the bank's description
+	MinAmount    string `yaml:"min_amount,omitempty"`   // inclusive, in whatever currency the transaction is in - ignoring whether it's in or out
+	MaxAmount    string `yaml:"max_amount,omitempty"`   // inclusive, like MinAmount
+	Direction    string `yaml:"direction,omitempty"`    // "in" for money coming in, "out" for money going out
+	PaymentType  string `yaml:"payment_type,omitempty"` // e.g. "PayNow" - matched case-insensitively
+	Counterparty string `yaml:"counterparty,omitempty"` // regular expression, like Description
+	PurposeCode  string `yaml:"purpose_code,omitempty"` // e.g. "SALARY" - matched case-insensitively
+	Reference    string `yaml:"reference,omitempty"`    // regular expression, like Description
+	Source       string `yaml:"source,omitempty"`       // name of the statement parser, e.g. "dbs"
+}
+
+const (
+	DirectionIn  = "in"
+	DirectionOut = "out"
+)
+
+// Reads a rules file (see File). Unknown keys are rejected, so typos don't quietly stop a rule from matching.
+func ReadFile(path string) (File, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return File{}, fmt.Errorf("error reading rules '%s': %+v", path, err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(b))
+	decoder.KnownFields(true)
+
+	file := File{}
+	if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return File{}, fmt.Errorf("%w: error parsing rules '%s': %+v", ErrInvalidRule, path, err)
+	}
+
+	return file, nil
+}
+
+// Adds r to the end of the rules file at path, creating the file if there isn't one.
+// Comments in the file are kept.
+func AppendRule(path string, r Rule) error {
+	b, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("error reading rules '%s': %+v", path, err)
+	}
+
+	doc := yaml.Node{}
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return fmt.Errorf("%w: error parsing rules '%s': %+v", ErrInvalidRule, path, err)
+	}
+	if len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("%w: rules '%s' should be a mapping with a 'rules' list", ErrInvalidRule, path)
+	}
+
+	// mapping nodes alternate keys and values
+	var list *yaml.Node
+	for idx := 0; idx+1 < len(root.Content); idx += 2 {
+		if root.Content[idx].Value == "rules" {
+			list = root.Content[idx+1]
+		}
+	}
+	if list == nil {
+		list = &yaml.Node{Kind: yaml.SequenceNode}
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: "rules"}, list)
+	}
+	if list.Kind == yaml.ScalarNode && list.Tag == "!!null" {
+		*list = yaml.Node{Kind: yaml.SequenceNode}
+	}
+	if list.Kind != yaml.SequenceNode {
+		return fmt.Errorf("%w: 'rules' in '%s' should be a list", ErrInvalidRule, path)
+	}
+
+	item := &yaml.Node{}
+	if err := item.Encode(r); err != nil {
+		return fmt.Errorf("error encoding rule %s: %+v", r.Name, err)
+	}
+	list.Content = append(list.Content, item)
+
+	out := bytes.Buffer{}
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return fmt.Errorf("error encoding rules '%s': %+v", path, err)
+	}
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("error encoding rules '%s': %+v", path, err)
+	}
+
+	if err := os.WriteFile(path, out.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("error writing rules '%s': %+v", path, err)
+	}
+
+	return nil
+}
+
+// Matches transactions against rules - see NewEngine.
+type Engine struct {
+	rules []rule // in the order they're tried
+}
+
+var _ ingest.Categorizer = Engine{}
+
+type rule struct {
+	Rule
+	category     ingest.Category
+	accountType  domain.AccountType // of category.AccountID - blank if the rule doesn't book to an account
+	description  *regexp.Regexp     // nil to match any
+	counterparty *regexp.Regexp     // nil to match any
+	reference    *regexp.Regexp     // nil to match any
+	min, max     *money.Amount      // nil for no bound
+}
+
+// Which rule a transaction matched, and what it does to it.
+type Hit struct {
+	Rule     Rule
+	Category ingest.Category
+}
+
+// Checks rules and resolves their accounts against accounts.
+func NewEngine(file File, accounts []domain.LedgerAccount) (Engine, error) {
+	engine := Engine{rules: make([]rule, 0, len(file.Rules))}
+	for idx, r := range file.Rules {
+		if r.Name == "" {
+			r.Name = fmt.Sprintf("rule %d", idx+1)
+		}
+
+		compiled, err := compile(r, accounts)
+		if err != nil {
+			return Engine{}, fmt.Errorf("error in %s: %w", r.Name, err)
+		}
+
+		engine.rules = append(engine.rules, compiled)
+	}
+
+	slices.SortStableFunc(engine.rules, func(a, b rule) int { return cmp.Compare(b.Priority, a.Priority) })
+
+	return engine, nil
+}
+
+func compile(r Rule, accounts []domain.LedgerAccount) (rule, error) {
+	compiled := rule{Rule: r, category: ingest.Category{Name: r.Rename, Tags: domain.UniqueTags(r.Tags)}}
+	if r.Account == "" && len(compiled.category.Tags) == 0 && r.Rename == "" {
+		return rule{}, fmt.Errorf("%w: give an account, tags or a rename", ErrInvalidRule)
+	}
+
+	if r.Account != "" {
+		account, err := findAccount(accounts, r.Account)
+		if err != nil {
+			return rule{}, fmt.Errorf("%w: %w", ErrInvalidRule, err)
+		}
+
+		compiled.category.AccountID, compiled.accountType = account.ID, account.Type
+	}
+
+	var err error
+	if compiled.description, err = compilePattern(r.Match.Description); err != nil {
+		return rule{}, fmt.Errorf("%w: description: %+v", ErrInvalidRule, err)
+	}
+	if compiled.counterparty, err = compilePattern(r.Match.Counterparty); err != nil {
+		return rule{}, fmt.Errorf("%w: counterparty: %+v", ErrInvalidRule, err)
+	}
+	if compiled.reference, err = compilePattern(r.Match.Reference); err != nil {
+		return rule{}, fmt.Errorf("%w: reference: %+v", ErrInvalidRule, err)
+	}
+	if compiled.min, err = parseBound(r.Match.MinAmount); err != nil {
+		return rule{}, fmt.Errorf("%w: min_amount: %w", ErrInvalidRule, err)
+	}
+	if compiled.max, err = parseBound(r.Match.MaxAmount); err != nil {
+		return rule{}, fmt.Errorf("%w: max_amount: %w", ErrInvalidRule, err)
+	}
+	if compiled.min != nil && compiled.max != nil && compiled.min.Micros() > compiled.max.Micros() {
+		return rule{}, fmt.Errorf("%w: min_amount %s is more than max_amount %s", ErrInvalidRule, r.Match.MinAmount, r.Match.MaxAmount)
+	}
+
+	switch r.Match.Direction {
+	case "", DirectionIn, DirectionOut:
+	default:
+		return rule{}, fmt.Errorf("%w: direction must be '%s' or '%s', got '%s'", ErrInvalidRule, DirectionIn, DirectionOut, r.Match.Direction)
+	}
+
+	return compiled, nil
+}
+
+// Finds an account by its id (e.g. 4003) or path (e.g. Expense:DiningOut).
+func findAccount(accounts []domain.LedgerAccount, ref string) (domain.LedgerAccount, error) {
+	accountID, err := strconv.ParseInt(strings.TrimSpace(ref), 10, 64)
+	if err != nil {
+		return domain.FindAccountByPath(accounts, ref)
+	}
+
+	idx := slices.IndexFunc(accounts, func(a domain.LedgerAccount) bool { return a.ID == accountID })
+	if idx < 0 {
+		return domain.LedgerAccount{}, fmt.Errorf("%w: %d", domain.ErrAccountNotFound, accountID)
+	}
+
+	return accounts[idx], nil
+}
+
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		return nil, nil
+	}
+
+	return regexp.Compile("(?i)" + pattern)
+}
+
+func parseBound(s string) (*money.Amount, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+
+	amount, err := money.Parse(s, "")
+	if err != nil {
+		return nil, err
+	}
+	if amount.IsNegative() {
+		return nil, fmt.Errorf("%w: '%s' is negative - use direction for money going out", money.ErrInvalidAmount, s)
+	}
+
+	return &amount, nil
+}
+
+// The first rule t matches, from a statement read by the parser named source.
+// Rules that book to an account only match transactions that can be booked there (see ingest.Transaction.CategoryType),
+// e.g. a rule booking rent to an expense account doesn't match the landlord refunding the deposit as income.
+func (e Engine) Match(t ingest.Transaction, source string) (Hit, bool) {
+	for _, r := range e.rules {
+		if r.matches(t, source) {
+			return Hit{Rule: r.Rule, Category: r.category}, true
+		}
+	}
+
+	return Hit{}, false
+}
+
+func (e Engine) Categorize(t ingest.Transaction, source string) (ingest.Category, bool) {
+	hit, ok := e.Match(t, source)
+	return hit.Category, ok
+}
+
+func (r rule) matches(t ingest.Transaction, source string) bool {
+	if r.Match.Source != "" && !strings.EqualFold(r.Match.Source, source) {
+		return false
+	}
+	if r.Match.PaymentType != "" && !strings.EqualFold(r.Match.PaymentType, strings.TrimSpace(t.PaymentType)) {
+		return false
+	}
+	if r.Match.PurposeCode != "" && !strings.EqualFold(r.Match.PurposeCode, strings.TrimSpace(t.PurposeCode)) {
+		return false
+	}
+	if want := t.CategoryType(); r.accountType != "" && want != "" && r.accountType != want {
+		return false
+	}
+	if r.description != nil && !r.description.MatchString(t.Description) {
+		return false
+	}
+	if r.counterparty != nil && !r.counterparty.MatchString(t.Counterparty) {
+		return false
+	}
+	if r.reference != nil && !r.reference.MatchString(t.Reference) {
+		return false
+	}
+
+	switch r.Match.Direction {
+	case DirectionIn:
+		if t.Amount.Sign() <= 0 {
+			return false
+		}
+	case DirectionOut:
+		if !t.Amount.IsNegative() {
+			return false
+		}
+	}
+
+	size := t.Amount.Micros()
+	if size < 0 {
+		size = -size
+	}
+	if r.min != nil && size < r.min.Micros() {
+		return false
+	}
+	if r.max != nil && size > r.max.Micros() {
+		return false
+	}
+
+	return true
+}
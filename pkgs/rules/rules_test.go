@@ -0,0 +1,167 @@
+package rules_test
+
+import (
+	"os"
+	"path/filepath"
+	domain "personal-finance/pkgs/domains"
+	"personal-finance/pkgs/ingest"
+	"personal-finance/pkgs/money"
+	"personal-finance/pkgs/rules"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const rulesYAML = `
+rules:
+  - name: groceries
+    match:
+      description: grocery_chain_
+      direction: out
+    account: Expense:Groceries
+    tags: [food]
+    rename: Groceries
+  - name: big groceries
+    priority: 10
+    match:
+      description: GROCERY_CHAIN_
+      min_amount: "200"
+    account: Expense:Groceries
+    tags: [food, stock-up]
+  - name: rent
+    match:
+      counterparty: ^PERSON_A
+      payment_type: paynow
+      source: ocbc
+      max_amount: 6,000
+    account: "4100"
+  - match:
+      source: dbs
+    tags: [card]
+  - name: salary
+    match:
+      purpose_code: salary
+      reference: ^PAYROLL_
+      direction: in
+    account: Income:SalaryWages
+`
+
+func newEngine(t *testing.T, yaml string) (rules.Engine, error) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(yaml), 0o600))
+
+	file, err := rules.ReadFile(path)
+	if err != nil {
+		return rules.Engine{}, err
+	}
+
+	return rules.NewEngine(file, domain.DefaultChartOfAccounts())
+}
+
+func TestEngineMatch(t *testing.T) {
+	t.Parallel()
+
+	engine, err := newEngine(t, rulesYAML)
+	require.NoError(t, err)
+
+	tests := []struct {
+		t      ingest.Transaction
+		source string
+		rule   string // blank for no match
+	}{
+		{ingest.Transaction{Description: "GROCERY_CHAIN_A      SINGAPORE     SG", Amount: money.MustParse("-45.10", money.SGD)}, "dbs", "groceries"},
+		{ingest.Transaction{Description: "GROCERY_CHAIN_A      SINGAPORE     SG", Amount: money.MustParse("-245.10", money.SGD)}, "dbs", "big groceries"},
+		{ingest.Transaction{Description: "GROCERY_CHAIN_A      SINGAPORE     SG", Amount: money.MustParse("45.10", money.SGD)}, "dbs", "rule 4"},
+		{ingest.Transaction{Counterparty: "PERSON_A PAYNOW_ID_001", PaymentType: "PayNow", Amount: money.MustParse("-6,000", money.SGD)}, "ocbc", "rent"},
+		{ingest.Transaction{Counterparty: "PERSON_A PAYNOW_ID_001", PaymentType: "PayNow", Amount: money.MustParse("-6,000.01", money.SGD)}, "ocbc", ""},
+		{ingest.Transaction{Counterparty: "PERSON_A PAYNOW_ID_001", PaymentType: "FAST", Amount: money.MustParse("-10", money.SGD)}, "ocbc", ""},
+		{ingest.Transaction{Counterparty: "BPERSON_A", PaymentType: "PayNow", Amount: money.MustParse("-10", money.SGD)}, "ocbc", ""},
+		// the rent rule books to an expense account, so it doesn't match income, only a refund
+		{ingest.Transaction{Counterparty: "PERSON_A PAYNOW_ID_001", PaymentType: "PayNow", Amount: money.MustParse("500", money.SGD)}, "ocbc", ""},
+		{ingest.Transaction{Counterparty: "PERSON_A PAYNOW_ID_001", PaymentType: "PayNow", Amount: money.MustParse("500", money.SGD), Type: ingest.TransactionType_Refund}, "ocbc", "rent"},
+		{ingest.Transaction{PurposeCode: "SALARY", Reference: "PAYROLL_2025_10", Amount: money.MustParse("4,811.73", money.SGD)}, "ocbc", "salary"},
+		{ingest.Transaction{PurposeCode: "OTHR", Reference: "PAYROLL_2025_10", Amount: money.MustParse("4,811.73", money.SGD)}, "ocbc", ""},
+		{ingest.Transaction{PurposeCode: "SALARY", Reference: "BONUS_2025", Amount: money.MustParse("4,811.73", money.SGD)}, "ocbc", ""},
+	}
+
+	for _, tt := range tests {
+		hit, ok := engine.Match(tt.t, tt.source)
+		require.Equal(t, tt.rule != "", ok, tt)
+		require.Equal(t, tt.rule, hit.Rule.Name, tt)
+	}
+
+	category, ok := engine.Categorize(tests[1].t, "dbs")
+	require.True(t, ok)
+	require.Equal(t, ingest.Category{AccountID: domain.AccountID_Expense_Groceries, Tags: []string{"food", "stock-up"}}, category)
+
+	category, ok = engine.Categorize(tests[0].t, "dbs")
+	require.True(t, ok)
+	require.Equal(t, ingest.Category{AccountID: domain.AccountID_Expense_Groceries, Name: "Groceries", Tags: []string{"food"}}, category)
+
+	category, ok = engine.Categorize(tests[3].t, "ocbc")
+	require.True(t, ok)
+	require.EqualValues(t, domain.AccountID_Expense_Housing, category.AccountID)
+}
+
+func TestNewEngineInvalid(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"unknown key":       "rules:\n  - match: {descripton: A}\n    tags: [a]\n",
+		"no effect":         "rules:\n  - match: {description: A}\n",
+		"unknown account":   "rules:\n  - account: Expense:Nope\n",
+		"bad regex":         "rules:\n  - match: {description: '('}\n    tags: [a]\n",
+		"bad reference":     "rules:\n  - match: {reference: '('}\n    tags: [a]\n",
+		"bad amount":        "rules:\n  - match: {min_amount: ten}\n    tags: [a]\n",
+		"negative amount":   "rules:\n  - match: {max_amount: '-10'}\n    tags: [a]\n",
+		"min more than max": "rules:\n  - match: {min_amount: '10', max_amount: '5'}\n    tags: [a]\n",
+		"bad direction":     "rules:\n  - match: {direction: sideways}\n    tags: [a]\n",
+	}
+
+	for name, yaml := range tests {
+		_, err := newEngine(t, yaml)
+		require.ErrorIs(t, err, rules.ErrInvalidRule, name)
+	}
+
+	engine, err := newEngine(t, "")
+	require.NoError(t, err)
+
+	_, ok := engine.Match(ingest.Transaction{Description: "anything"}, "dbs")
+	require.False(t, ok)
+}
+
+func TestAppendRule(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	rule := rules.Rule{Name: "rides", Match: rules.Match{Description: "RIDE_HAILING_A", Direction: rules.DirectionOut}, Account: "Expense:Transportation"}
+
+	// a new file
+	require.NoError(t, rules.AppendRule(path, rule))
+
+	file, err := rules.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, rules.File{Rules: []rules.Rule{rule}}, file)
+
+	// an existing one, keeping its comments
+	require.NoError(t, os.WriteFile(path, []byte("# my rules\nrules:\n  - match: {description: GROCERY_CHAIN_}\n    account: Expense:Groceries # supermarkets\n"), 0o600))
+	require.NoError(t, rules.AppendRule(path, rule))
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Contains(t, string(b), "# my rules")
+	require.Contains(t, string(b), "# supermarkets")
+
+	file, err = rules.ReadFile(path)
+	require.NoError(t, err)
+	require.Len(t, file.Rules, 2)
+	require.Equal(t, rule, file.Rules[1])
+
+	_, err = rules.NewEngine(file, domain.DefaultChartOfAccounts())
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(path, []byte("- not a mapping\n"), 0o600))
+	require.ErrorIs(t, rules.AppendRule(path, rule), rules.ErrInvalidRule)
+}
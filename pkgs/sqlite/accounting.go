@@ -0,0 +1,530 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	domain "personal-finance/pkgs/domains"
+	"personal-finance/pkgs/money"
+	"time"
+)
+
+// Dates are stored as text so they sort (and read) naturally in the sqlite shell.
+const dateLayout = time.RFC3339Nano
+
+type AccountingRepository struct {
+	db *sql.DB
+
+	// set on repositories handed to WithTx callbacks
+	tx    *sql.Tx
+	depth int // WithTx nesting level - used to name savepoints
+}
+
+var _ domain.AccountingRepository = &AccountingRepository{}
+
+// Wraps an already migrated database (see Open) and seeds the default chart of accounts if it's missing.
+func NewAccountingRepository(ctx context.Context, db *sql.DB) (*AccountingRepository, error) {
+	repo := &AccountingRepository{db: db}
+
+	err := repo.inTx(ctx, func(tx *sql.Tx) error {
+		for _, account := range domain.DefaultChartOfAccounts() {
+			_, err := tx.ExecContext(ctx,
+				`INSERT OR IGNORE INTO accounts (id, name, description, type, parent_id) VALUES (?, ?, ?, ?, ?)`,
+				account.ID, account.Name, account.Description, account.Type, nullableID(account.ParentID),
+			)
+			if err != nil {
+				return fmt.Errorf("error seeding account %d: %+v", account.ID, err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error seeding chart of accounts: %w", err)
+	}
+
+	return repo, nil
+}
+
+func (repo *AccountingRepository) WithTx(ctx context.Context, fn func(tx domain.AccountingRepository) error) error {
+	return repo.inTx(ctx, func(tx *sql.Tx) error {
+		return fn(&AccountingRepository{db: repo.db, tx: tx, depth: repo.depth + 1})
+	})
+}
+
+// Database handle to run queries on - the transaction if we're in one.
+func (repo *AccountingRepository) q() querier {
+	if repo.tx != nil {
+		return repo.tx
+	}
+
+	return repo.db
+}
+
+// Runs fn in a database transaction - committing if it returns nil and rolling back otherwise.
+// If the repository is already in a transaction, fn runs in a savepoint of it instead.
+func (repo *AccountingRepository) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	if repo.tx != nil {
+		return repo.inSavepoint(ctx, fn)
+	}
+
+	tx, err := repo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %+v", err)
+	}
+
+	if err := fn(tx); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return errors.Join(err, fmt.Errorf("error rolling back transaction: %+v", rbErr))
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction: %+v", err)
+	}
+
+	return nil
+}
+
+func (repo *AccountingRepository) inSavepoint(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	savepoint := fmt.Sprintf("sp_%d", repo.depth)
+	if _, err := repo.tx.ExecContext(ctx, "SAVEPOINT "+savepoint); err != nil {
+		return fmt.Errorf("error creating savepoint: %+v", err)
+	}
+
+	if err := fn(repo.tx); err != nil {
+		if _, rbErr := repo.tx.ExecContext(ctx, "ROLLBACK TO "+savepoint); rbErr != nil {
+			return errors.Join(err, fmt.Errorf("error rolling back to savepoint: %+v", rbErr))
+		}
+		if _, rlErr := repo.tx.ExecContext(ctx, "RELEASE "+savepoint); rlErr != nil {
+			return errors.Join(err, fmt.Errorf("error releasing savepoint: %+v", rlErr))
+		}
+		return err
+	}
+
+	if _, err := repo.tx.ExecContext(ctx, "RELEASE "+savepoint); err != nil {
+		return fmt.Errorf("error releasing savepoint: %+v", err)
+	}
+
+	return nil
+}
+
+func (repo *AccountingRepository) CreateJournalEntry(ctx context.Context, entry domain.CreateJournalEntryParams, postings []domain.CreatePostingParams) (journalEntryID int64, err error) {
+	if err := domain.ValidatePostings(postings); err != nil {
+		return 0, fmt.Errorf("error validating journal entry '%s': %w", entry.Name, err)
+	}
+
+	err = repo.inTx(ctx, func(tx *sql.Tx) error {
+		for _, posting := range postings {
+			if err := accountExists(ctx, tx, posting.AccountID); err != nil {
+				return fmt.Errorf("error validating journal entry '%s': %w", entry.Name, err)
+			}
+		}
+
+		if entry.Provenance.ImportID != 0 {
+			if err := importExists(ctx, tx, entry.Provenance.ImportID); err != nil {
+				return fmt.Errorf("error validating journal entry '%s': %w", entry.Name, err)
+			}
+		}
+
+		if entry.Reverses != 0 {
+			if err := validateReversal(ctx, tx, entry.Reverses); err != nil {
+				return fmt.Errorf("error validating journal entry '%s': %w", entry.Name, err)
+			}
+		}
+
+		if entry.ReplacedBy != 0 {
+			if err := journalEntryExists(ctx, tx, entry.ReplacedBy); err != nil {
+				return fmt.Errorf("error validating journal entry '%s': %w", entry.Name, err)
+			}
+		}
+
+		res, err := tx.ExecContext(ctx,
+			`INSERT INTO journal_entries (name, description, date, fingerprint, content_hash, import_id, source_row, raw_row, reverses_id, replaced_by_id, pending, counterparty) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			entry.Name, entry.Description, entry.Date.UTC().Format(dateLayout), entry.Fingerprint, entry.ContentHash,
+			nullableID(entry.Provenance.ImportID), entry.Provenance.Row, entry.Provenance.RawRow, nullableID(entry.Reverses), nullableID(entry.ReplacedBy), entry.Pending, entry.Counterparty,
+		)
+		if err != nil {
+			return fmt.Errorf("error inserting journal entry: %+v", err)
+		}
+
+		journalEntryID, err = res.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("error reading journal entry id: %+v", err)
+		}
+
+		for _, posting := range postings {
+			_, err := tx.ExecContext(ctx,
+				`INSERT INTO postings (journal_entry_id, account_id, name, description, debit_micros, credit_micros, currency) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+				journalEntryID, posting.AccountID, posting.Name, posting.Description, posting.Debit.Micros(), posting.Credit.Micros(), postingCurrency(posting),
+			)
+			if err != nil {
+				return fmt.Errorf("error inserting posting for journal entry %d: %+v", journalEntryID, err)
+			}
+		}
+
+		for _, tag := range domain.UniqueTags(entry.Tags) {
+			if _, err := tx.ExecContext(ctx, `INSERT INTO journal_entry_tags (journal_entry_id, tag) VALUES (?, ?)`, journalEntryID, tag); err != nil {
+				return fmt.Errorf("error inserting tag '%s' for journal entry %d: %+v", tag, journalEntryID, err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return journalEntryID, nil
+}
+
+func (repo *AccountingRepository) CreateExpense(ctx context.Context, param domain.CreateExpenseParams) error {
+	entry, postings := domain.NewExpenseJournalEntry(param)
+	if _, err := repo.CreateJournalEntry(ctx, entry, postings); err != nil {
+		return fmt.Errorf("error creating journal entry for expense creation: %w", err)
+	}
+
+	return nil
+}
+
+func (repo *AccountingRepository) CreateIncome(ctx context.Context, param domain.CreateIncomeParams) error {
+	entry, postings := domain.NewIncomeJournalEntry(param)
+	if _, err := repo.CreateJournalEntry(ctx, entry, postings); err != nil {
+		return fmt.Errorf("error creating journal entry for income creation: %w", err)
+	}
+
+	return nil
+}
+
+func (repo *AccountingRepository) ListJournalEntries(ctx context.Context) ([]domain.JournalEntry, error) {
+	rows, err := repo.q().QueryContext(ctx,
+		`SELECT je.id, je.name, je.description, je.date, je.fingerprint, je.content_hash,
+			je.import_id, je.source_row, je.raw_row, COALESCE(i.parser, ''), COALESCE(i.file_sha256, ''), je.reverses_id, je.replaced_by_id, je.pending, je.counterparty
+		FROM journal_entries je
+		LEFT JOIN imports i ON i.id = je.import_id
+		ORDER BY je.id`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error querying journal entries: %+v", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	entries := []domain.JournalEntry{}
+	index := map[int64]int{} // key: journal entry id, val: index in entries
+	for rows.Next() {
+		var entry domain.JournalEntry
+		var date string
+		var importID, reverses, replacedBy sql.NullInt64
+		err := rows.Scan(&entry.ID, &entry.Name, &entry.Description, &date, &entry.Fingerprint, &entry.ContentHash,
+			&importID, &entry.Provenance.Row, &entry.Provenance.RawRow, &entry.Provenance.Parser, &entry.Provenance.FileSHA256, &reverses, &replacedBy, &entry.Pending, &entry.Counterparty)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning journal entry: %+v", err)
+		}
+
+		entry.Provenance.ImportID = importID.Int64
+		entry.Reverses = reverses.Int64
+		entry.ReplacedBy = replacedBy.Int64
+
+		entry.Date, err = time.Parse(dateLayout, date)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing date of journal entry %d: %+v", entry.ID, err)
+		}
+
+		entry.Postings = []domain.Posting{}
+		index[entry.ID] = len(entries)
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating journal entries: %+v", err)
+	}
+
+	postingRows, err := repo.q().QueryContext(ctx,
+		`SELECT id, journal_entry_id, account_id, name, description, debit_micros, credit_micros, currency FROM postings ORDER BY id`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error querying postings: %+v", err)
+	}
+	defer func() { _ = postingRows.Close() }()
+
+	for postingRows.Next() {
+		var posting domain.Posting
+		var debitMicros, creditMicros int64
+		var currency money.Currency
+		err := postingRows.Scan(&posting.ID, &posting.JournalEntryID, &posting.AccountID, &posting.Name, &posting.Description, &debitMicros, &creditMicros, &currency)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning posting: %+v", err)
+		}
+
+		posting.Debit = money.New(debitMicros, currency)
+		posting.Credit = money.New(creditMicros, currency)
+
+		idx, ok := index[posting.JournalEntryID]
+		if !ok {
+			return nil, fmt.Errorf("no journal entry with id %d", posting.JournalEntryID)
+		}
+
+		entries[idx].Postings = append(entries[idx].Postings, posting)
+	}
+	if err := postingRows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating postings: %+v", err)
+	}
+
+	tagRows, err := repo.q().QueryContext(ctx, `SELECT journal_entry_id, tag FROM journal_entry_tags ORDER BY rowid`)
+	if err != nil {
+		return nil, fmt.Errorf("error querying tags: %+v", err)
+	}
+	defer func() { _ = tagRows.Close() }()
+
+	for tagRows.Next() {
+		var journalEntryID int64
+		var tag string
+		if err := tagRows.Scan(&journalEntryID, &tag); err != nil {
+			return nil, fmt.Errorf("error scanning tag: %+v", err)
+		}
+
+		idx, ok := index[journalEntryID]
+		if !ok {
+			return nil, fmt.Errorf("no journal entry with id %d", journalEntryID)
+		}
+
+		entries[idx].Tags = append(entries[idx].Tags, tag)
+	}
+	if err := tagRows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tags: %+v", err)
+	}
+
+	return entries, nil
+}
+
+func (repo *AccountingRepository) ListTransactions(ctx context.Context) ([]domain.Expense, error) {
+	entries, err := repo.ListJournalEntries(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error listing journal entries: %+v", err)
+	}
+
+	accounts, err := repo.ListAccounts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error listing accounts: %+v", err)
+	}
+
+	return domain.TransactionsFromJournalEntries(entries, accounts)
+}
+
+func (repo *AccountingRepository) CreateAccount(ctx context.Context, param domain.CreateAccountParams) (accountID int64, err error) {
+	err = repo.inTx(ctx, func(tx *sql.Tx) error {
+		accounts, err := listAccounts(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		account, err := domain.PrepareCreateAccount(accounts, param)
+		if err != nil {
+			return fmt.Errorf("error creating account: %w", err)
+		}
+
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO accounts (id, name, description, type, parent_id) VALUES (?, ?, ?, ?, ?)`,
+			account.ID, account.Name, account.Description, account.Type, nullableID(account.ParentID),
+		)
+		if err != nil {
+			return fmt.Errorf("error inserting account: %+v", err)
+		}
+
+		accountID = account.ID
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return accountID, nil
+}
+
+func (repo *AccountingRepository) GetAccount(ctx context.Context, accountID int64) (domain.LedgerAccount, error) {
+	row := repo.q().QueryRowContext(ctx, `SELECT id, name, description, type, parent_id FROM accounts WHERE id = ?`, accountID)
+
+	account, err := scanAccount(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.LedgerAccount{}, fmt.Errorf("%w: %d", domain.ErrAccountNotFound, accountID)
+	}
+	if err != nil {
+		return domain.LedgerAccount{}, fmt.Errorf("error scanning account %d: %+v", accountID, err)
+	}
+
+	return account, nil
+}
+
+func (repo *AccountingRepository) ListAccounts(ctx context.Context) ([]domain.LedgerAccount, error) {
+	return listAccounts(ctx, repo.q())
+}
+
+func (repo *AccountingRepository) UpdateAccount(ctx context.Context, param domain.UpdateAccountParams) error {
+	return repo.inTx(ctx, func(tx *sql.Tx) error {
+		accounts, err := listAccounts(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		account, err := domain.PrepareUpdateAccount(accounts, param)
+		if err != nil {
+			return fmt.Errorf("error updating account: %w", err)
+		}
+
+		_, err = tx.ExecContext(ctx,
+			`UPDATE accounts SET name = ?, description = ?, parent_id = ? WHERE id = ?`,
+			account.Name, account.Description, nullableID(account.ParentID), account.ID,
+		)
+		if err != nil {
+			return fmt.Errorf("error updating account %d: %+v", account.ID, err)
+		}
+
+		return nil
+	})
+}
+
+func (repo *AccountingRepository) DeleteAccount(ctx context.Context, accountID int64) error {
+	return repo.inTx(ctx, func(tx *sql.Tx) error {
+		if err := accountExists(ctx, tx, accountID); err != nil {
+			return err
+		}
+
+		var childID int64
+		err := tx.QueryRowContext(ctx, `SELECT id FROM accounts WHERE parent_id = ? LIMIT 1`, accountID).Scan(&childID)
+		if err == nil {
+			return fmt.Errorf("%w: account %d has sub-account %d", domain.ErrAccountInUse, accountID, childID)
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("error querying sub-accounts of %d: %+v", accountID, err)
+		}
+
+		var postings int
+		if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM postings WHERE account_id = ?`, accountID).Scan(&postings); err != nil {
+			return fmt.Errorf("error counting postings of account %d: %+v", accountID, err)
+		}
+		if postings > 0 {
+			return fmt.Errorf("%w: account %d has postings", domain.ErrAccountInUse, accountID)
+		}
+
+		var counterparty string
+		err = tx.QueryRowContext(ctx, `SELECT name FROM counterparties WHERE account_id = ? LIMIT 1`, accountID).Scan(&counterparty)
+		if err == nil {
+			return fmt.Errorf("%w: account %d is counterparty %s's default account", domain.ErrAccountInUse, accountID, counterparty)
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("error querying counterparties of account %d: %+v", accountID, err)
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM accounts WHERE id = ?`, accountID); err != nil {
+			return fmt.Errorf("error deleting account %d: %+v", accountID, err)
+		}
+
+		return nil
+	})
+}
+
+// Satisfied by both *sql.DB and *sql.Tx.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func accountExists(ctx context.Context, q querier, accountID int64) error {
+	var id int64
+	err := q.QueryRowContext(ctx, `SELECT id FROM accounts WHERE id = ?`, accountID).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: %d", domain.ErrAccountNotFound, accountID)
+	}
+	if err != nil {
+		return fmt.Errorf("error querying account %d: %+v", accountID, err)
+	}
+
+	return nil
+}
+
+func journalEntryExists(ctx context.Context, q querier, id int64) error {
+	var found int64
+	err := q.QueryRowContext(ctx, `SELECT id FROM journal_entries WHERE id = ?`, id).Scan(&found)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: %d", domain.ErrJournalEntryNotFound, id)
+	}
+	if err != nil {
+		return fmt.Errorf("error querying journal entry %d: %+v", id, err)
+	}
+
+	return nil
+}
+
+// Checks that the journal entry with id exists and nothing has reversed it yet.
+func validateReversal(ctx context.Context, q querier, id int64) error {
+	var reversedBy sql.NullInt64
+	err := q.QueryRowContext(ctx, `SELECT (SELECT r.id FROM journal_entries r WHERE r.reverses_id = je.id) FROM journal_entries je WHERE je.id = ?`, id).Scan(&reversedBy)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: %d", domain.ErrJournalEntryNotFound, id)
+	}
+	if err != nil {
+		return fmt.Errorf("error querying journal entry %d: %+v", id, err)
+	}
+	if reversedBy.Valid {
+		return fmt.Errorf("%w: %d by %d", domain.ErrAlreadyReversed, id, reversedBy.Int64)
+	}
+
+	return nil
+}
+
+func listAccounts(ctx context.Context, q querier) ([]domain.LedgerAccount, error) {
+	rows, err := q.QueryContext(ctx, `SELECT id, name, description, type, parent_id FROM accounts ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("error querying accounts: %+v", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	accounts := []domain.LedgerAccount{}
+	for rows.Next() {
+		account, err := scanAccount(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning account: %+v", err)
+		}
+
+		accounts = append(accounts, account)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating accounts: %+v", err)
+	}
+
+	return accounts, nil
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanAccount(s scanner) (domain.LedgerAccount, error) {
+	var account domain.LedgerAccount
+	var parentID sql.NullInt64
+	if err := s.Scan(&account.ID, &account.Name, &account.Description, &account.Type, &parentID); err != nil {
+		return domain.LedgerAccount{}, err
+	}
+
+	account.ParentID = parentID.Int64
+	return account, nil
+}
+
+// Postings are validated to be single currency, but either side may be a currency-less zero.
+func postingCurrency(posting domain.CreatePostingParams) money.Currency {
+	if posting.Debit.Currency() != "" {
+		return posting.Debit.Currency()
+	}
+	if posting.Credit.Currency() != "" {
+		return posting.Credit.Currency()
+	}
+
+	return money.SGD
+}
+
+// Top-level accounts have no parent - store NULL rather than 0 so the foreign key holds.
+func nullableID(id int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: id, Valid: id != 0}
+}
@@ -0,0 +1,55 @@
+package sqlite_test
+
+import (
+	"path/filepath"
+	domain "personal-finance/pkgs/domains"
+	"personal-finance/pkgs/domains/domaintest"
+	"personal-finance/pkgs/money"
+	"personal-finance/pkgs/sqlite"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountingRepository(t *testing.T) {
+	t.Parallel()
+
+	domaintest.RunAccountingRepositoryTests(t, func(t *testing.T) domain.AccountingRepository {
+		db, err := sqlite.Open(t.Context(), ":memory:")
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = db.Close() })
+
+		repo, err := sqlite.NewAccountingRepository(t.Context(), db)
+		require.NoError(t, err)
+
+		return repo
+	})
+}
+
+func TestAccountingRepository_Persists(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	path := filepath.Join(t.TempDir(), "ledger.db")
+
+	db, err := sqlite.Open(ctx, path)
+	require.NoError(t, err)
+
+	repo, err := sqlite.NewAccountingRepository(ctx, db)
+	require.NoError(t, err)
+	require.NoError(t, repo.CreateExpense(ctx, domain.CreateExpenseParams{Name: "lunch", Debit: money.New(5_000_000, money.SGD)}))
+	require.NoError(t, db.Close())
+
+	db, err = sqlite.Open(ctx, path)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	// re-seeding an existing database is a no-op
+	repo, err = sqlite.NewAccountingRepository(ctx, db)
+	require.NoError(t, err)
+
+	entries, err := repo.ListJournalEntries(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, "lunch", entries[0].Name)
+}
@@ -0,0 +1,59 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	domain "personal-finance/pkgs/domains"
+	"strings"
+)
+
+func (repo *AccountingRepository) SaveCounterparty(ctx context.Context, param domain.SaveCounterpartyParams) (counterpartyID int64, err error) {
+	param.Name = strings.TrimSpace(param.Name)
+	if err := domain.ValidateCounterparty(param); err != nil {
+		return 0, err
+	}
+
+	if param.AccountID != 0 {
+		if err := accountExists(ctx, repo.q(), param.AccountID); err != nil {
+			return 0, err
+		}
+	}
+
+	err = repo.q().QueryRowContext(ctx,
+		`INSERT INTO counterparties (name, alias, account_id) VALUES (?, ?, ?)
+		ON CONFLICT (name) DO UPDATE SET alias = excluded.alias, account_id = excluded.account_id
+		RETURNING id`,
+		param.Name, param.Alias, nullableID(param.AccountID),
+	).Scan(&counterpartyID)
+	if err != nil {
+		return 0, fmt.Errorf("error saving counterparty '%s': %+v", param.Name, err)
+	}
+
+	return counterpartyID, nil
+}
+
+func (repo *AccountingRepository) ListCounterparties(ctx context.Context) ([]domain.Counterparty, error) {
+	rows, err := repo.q().QueryContext(ctx, `SELECT id, name, alias, account_id FROM counterparties ORDER BY name COLLATE NOCASE`)
+	if err != nil {
+		return nil, fmt.Errorf("error querying counterparties: %+v", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	counterparties := []domain.Counterparty{}
+	for rows.Next() {
+		var c domain.Counterparty
+		var accountID sql.NullInt64
+		if err := rows.Scan(&c.ID, &c.Name, &c.Alias, &accountID); err != nil {
+			return nil, fmt.Errorf("error scanning counterparty: %+v", err)
+		}
+
+		c.AccountID = accountID.Int64
+		counterparties = append(counterparties, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating counterparties: %+v", err)
+	}
+
+	return counterparties, nil
+}
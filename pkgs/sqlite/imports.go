@@ -0,0 +1,153 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	domain "personal-finance/pkgs/domains"
+	"personal-finance/pkgs/money"
+	"time"
+)
+
+func (repo *AccountingRepository) CreateImport(ctx context.Context, param domain.CreateImportParams) (importID int64, err error) {
+	if err := domain.ValidateImport(param); err != nil {
+		return 0, err
+	}
+
+	currency, err := param.Metadata.Currency()
+	if err != nil {
+		return 0, err
+	}
+
+	account, err := repo.GetAccount(ctx, param.AccountID)
+	if err != nil {
+		return 0, err
+	}
+	if err := domain.ValidateImportAccount(account); err != nil {
+		return 0, err
+	}
+
+	res, err := repo.q().ExecContext(ctx,
+		`INSERT INTO imports (parser, account_id, file_sha256, imported_at, account_ref, as_of, currency, ledger_balance_micros, available_balance_micros, credit_limit_micros)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		param.Parser, param.AccountID, param.FileSHA256, param.ImportedAt.UTC().Format(dateLayout), param.Metadata.AccountRef, nullableTime(param.Metadata.AsOf), currency,
+		nullableMicros(param.Metadata.LedgerBalance), nullableMicros(param.Metadata.AvailableBalance), nullableMicros(param.Metadata.CreditLimit),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("error inserting import: %+v", err)
+	}
+
+	importID, err = res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("error reading import id: %+v", err)
+	}
+
+	return importID, nil
+}
+
+const importColumns = `id, parser, account_id, file_sha256, imported_at, account_ref, as_of, currency, ledger_balance_micros, available_balance_micros, credit_limit_micros`
+
+func (repo *AccountingRepository) GetImport(ctx context.Context, importID int64) (domain.Import, error) {
+	row := repo.q().QueryRowContext(ctx, `SELECT `+importColumns+` FROM imports WHERE id = ?`, importID)
+
+	imp, err := scanImport(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.Import{}, fmt.Errorf("%w: %d", domain.ErrImportNotFound, importID)
+	}
+	if err != nil {
+		return domain.Import{}, fmt.Errorf("error scanning import %d: %+v", importID, err)
+	}
+
+	return imp, nil
+}
+
+func (repo *AccountingRepository) ListImports(ctx context.Context) ([]domain.Import, error) {
+	rows, err := repo.q().QueryContext(ctx, `SELECT `+importColumns+` FROM imports ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("error querying imports: %+v", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	imports := []domain.Import{}
+	for rows.Next() {
+		imp, err := scanImport(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning import: %+v", err)
+		}
+
+		imports = append(imports, imp)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating imports: %+v", err)
+	}
+
+	return imports, nil
+}
+
+func importExists(ctx context.Context, q querier, importID int64) error {
+	var id int64
+	err := q.QueryRowContext(ctx, `SELECT id FROM imports WHERE id = ?`, importID).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: %d", domain.ErrImportNotFound, importID)
+	}
+	if err != nil {
+		return fmt.Errorf("error querying import %d: %+v", importID, err)
+	}
+
+	return nil
+}
+
+func scanImport(s scanner) (domain.Import, error) {
+	var imp domain.Import
+	var importedAt string
+	var asOf sql.NullString
+	var currency money.Currency
+	var ledgerBalance, availableBalance, creditLimit sql.NullInt64
+	err := s.Scan(&imp.ID, &imp.Parser, &imp.AccountID, &imp.FileSHA256, &importedAt, &imp.Metadata.AccountRef, &asOf, &currency, &ledgerBalance, &availableBalance, &creditLimit)
+	if err != nil {
+		return domain.Import{}, err
+	}
+
+	if imp.ImportedAt, err = time.Parse(dateLayout, importedAt); err != nil {
+		return domain.Import{}, fmt.Errorf("error parsing imported_at of import %d: %+v", imp.ID, err)
+	}
+	if asOf.Valid {
+		if imp.Metadata.AsOf, err = time.Parse(dateLayout, asOf.String); err != nil {
+			return domain.Import{}, fmt.Errorf("error parsing as_of of import %d: %+v", imp.ID, err)
+		}
+	}
+
+	imp.Metadata.LedgerBalance = amountFromMicros(ledgerBalance, currency)
+	imp.Metadata.AvailableBalance = amountFromMicros(availableBalance, currency)
+	imp.Metadata.CreditLimit = amountFromMicros(creditLimit, currency)
+
+	return imp, nil
+}
+
+// Unknown dates are stored as NULL.
+func nullableTime(t time.Time) sql.NullString {
+	if t.IsZero() {
+		return sql.NullString{}
+	}
+
+	return sql.NullString{String: t.UTC().Format(dateLayout), Valid: true}
+}
+
+// Unknown amounts are stored as NULL.
+func nullableMicros(amount *money.Amount) sql.NullInt64 {
+	if amount == nil {
+		return sql.NullInt64{}
+	}
+
+	return sql.NullInt64{Int64: amount.Micros(), Valid: true}
+}
+
+func amountFromMicros(micros sql.NullInt64, currency money.Currency) *money.Amount {
+	if !micros.Valid {
+		return nil
+	}
+
+	amount := money.New(micros.Int64, currency)
+	return &amount
+}
@@ -0,0 +1,84 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	domain "personal-finance/pkgs/domains"
+	"personal-finance/pkgs/money"
+	"time"
+)
+
+func (repo *AccountingRepository) CreateReconciliation(ctx context.Context, param domain.CreateReconciliationParams) (reconciliationID int64, err error) {
+	if err := domain.ValidateReconciliation(param); err != nil {
+		return 0, err
+	}
+
+	if err := accountExists(ctx, repo.q(), param.AccountID); err != nil {
+		return 0, err
+	}
+
+	if param.ImportID != 0 {
+		if err := importExists(ctx, repo.q(), param.ImportID); err != nil {
+			return 0, err
+		}
+	}
+
+	currency := param.Balance.Currency()
+	if currency == "" {
+		currency = money.SGD
+	}
+
+	res, err := repo.q().ExecContext(ctx,
+		`INSERT INTO reconciliations (account_id, import_id, as_of, balance_micros, currency, reconciled_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		param.AccountID, nullableID(param.ImportID), param.AsOf.UTC().Format(dateLayout), param.Balance.Micros(), currency, param.ReconciledAt.UTC().Format(dateLayout),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("error inserting reconciliation: %+v", err)
+	}
+
+	reconciliationID, err = res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("error reading reconciliation id: %+v", err)
+	}
+
+	return reconciliationID, nil
+}
+
+func (repo *AccountingRepository) ListReconciliations(ctx context.Context) ([]domain.Reconciliation, error) {
+	rows, err := repo.q().QueryContext(ctx,
+		`SELECT id, account_id, import_id, as_of, balance_micros, currency, reconciled_at FROM reconciliations ORDER BY id`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error querying reconciliations: %+v", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	reconciliations := []domain.Reconciliation{}
+	for rows.Next() {
+		var r domain.Reconciliation
+		var importID sql.NullInt64
+		var asOf, reconciledAt string
+		var balanceMicros int64
+		var currency money.Currency
+		if err := rows.Scan(&r.ID, &r.AccountID, &importID, &asOf, &balanceMicros, &currency, &reconciledAt); err != nil {
+			return nil, fmt.Errorf("error scanning reconciliation: %+v", err)
+		}
+
+		r.ImportID = importID.Int64
+		r.Balance = money.New(balanceMicros, currency)
+		if r.AsOf, err = time.Parse(dateLayout, asOf); err != nil {
+			return nil, fmt.Errorf("error parsing as_of of reconciliation %d: %+v", r.ID, err)
+		}
+		if r.ReconciledAt, err = time.Parse(dateLayout, reconciledAt); err != nil {
+			return nil, fmt.Errorf("error parsing reconciled_at of reconciliation %d: %+v", r.ID, err)
+		}
+
+		reconciliations = append(reconciliations, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating reconciliations: %+v", err)
+	}
+
+	return reconciliations, nil
+}
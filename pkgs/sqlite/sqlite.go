@@ -0,0 +1,179 @@
+// Package sqlite persists the accounting ledger in an embedded SQLite database file.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+
+	_ "modernc.org/sqlite"
+)
+
+// Opens (creating if needed) the SQLite database at path and brings its schema up to date.
+// Use ":memory:" for a throwaway database.
+func Open(ctx context.Context, path string) (*sql.DB, error) {
+	dsn := fmt.Sprintf("file:%s?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)", url.PathEscape(path))
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("error opening sqlite database at '%s': %+v", path, err)
+	}
+
+	// sqlite only allows a single writer, and every connection to ":memory:" is a different database
+	db.SetMaxOpenConns(1)
+
+	if err := Migrate(ctx, db); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// Schema changes, applied in order. The database's `user_version` records how many have been applied.
+// NOTE: only ever append to this list - never edit a migration that has shipped.
+var migrations = []string{
+	`
+	CREATE TABLE accounts (
+		id          INTEGER PRIMARY KEY,
+		name        TEXT    NOT NULL,
+		description TEXT    NOT NULL DEFAULT '',
+		type        TEXT    NOT NULL CHECK (type IN ('asset', 'liability', 'income', 'expense', 'equity')),
+		parent_id   INTEGER REFERENCES accounts (id)
+	);
+
+	CREATE TABLE journal_entries (
+		id          INTEGER PRIMARY KEY AUTOINCREMENT,
+		name        TEXT    NOT NULL,
+		description TEXT    NOT NULL DEFAULT '',
+		date        TEXT    NOT NULL
+	);
+
+	CREATE TABLE postings (
+		id               INTEGER PRIMARY KEY AUTOINCREMENT,
+		journal_entry_id INTEGER NOT NULL REFERENCES journal_entries (id),
+		account_id       INTEGER NOT NULL REFERENCES accounts (id),
+		name             TEXT    NOT NULL DEFAULT '',
+		description      TEXT    NOT NULL DEFAULT '',
+		debit_micro_sgd  INTEGER NOT NULL DEFAULT 0 CHECK (debit_micro_sgd >= 0),
+		credit_micro_sgd INTEGER NOT NULL DEFAULT 0 CHECK (credit_micro_sgd >= 0)
+	);
+
+	CREATE INDEX postings_journal_entry_id ON postings (journal_entry_id);
+	CREATE INDEX postings_account_id ON postings (account_id);
+	`,
+	`
+	ALTER TABLE postings RENAME COLUMN debit_micro_sgd TO debit_micros;
+	ALTER TABLE postings RENAME COLUMN credit_micro_sgd TO credit_micros;
+	ALTER TABLE postings ADD COLUMN currency TEXT NOT NULL DEFAULT 'SGD';
+	`,
+	`
+	CREATE TABLE imports (
+		id                       INTEGER PRIMARY KEY AUTOINCREMENT,
+		parser                   TEXT    NOT NULL,
+		imported_at              TEXT    NOT NULL,
+		account_ref              TEXT    NOT NULL DEFAULT '',
+		as_of                    TEXT,
+		currency                 TEXT    NOT NULL DEFAULT '',
+		ledger_balance_micros    INTEGER,
+		available_balance_micros INTEGER,
+		credit_limit_micros      INTEGER
+	);
+	`,
+	`
+	-- every import so far was booked to Asset:BankAccount
+	ALTER TABLE imports ADD COLUMN account_id INTEGER NOT NULL DEFAULT 0;
+	UPDATE imports SET account_id = 1000;
+
+	CREATE TABLE reconciliations (
+		id               INTEGER PRIMARY KEY AUTOINCREMENT,
+		account_id       INTEGER NOT NULL REFERENCES accounts (id),
+		import_id        INTEGER REFERENCES imports (id),
+		as_of            TEXT    NOT NULL,
+		balance_micros   INTEGER NOT NULL,
+		currency         TEXT    NOT NULL,
+		reconciled_at    TEXT    NOT NULL
+	);
+
+	CREATE INDEX reconciliations_account_id ON reconciliations (account_id);
+	`,
+	`
+	ALTER TABLE journal_entries ADD COLUMN fingerprint TEXT NOT NULL DEFAULT '';
+	ALTER TABLE journal_entries ADD COLUMN content_hash TEXT NOT NULL DEFAULT '';
+
+	CREATE INDEX journal_entries_fingerprint ON journal_entries (fingerprint);
+	`,
+	`
+	ALTER TABLE imports ADD COLUMN file_sha256 TEXT NOT NULL DEFAULT '';
+
+	-- entries imported before this was recorded have no import
+	ALTER TABLE journal_entries ADD COLUMN import_id INTEGER REFERENCES imports (id);
+	ALTER TABLE journal_entries ADD COLUMN source_row INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE journal_entries ADD COLUMN raw_row TEXT NOT NULL DEFAULT '';
+
+	CREATE INDEX journal_entries_import_id ON journal_entries (import_id);
+	`,
+	`
+	ALTER TABLE journal_entries ADD COLUMN reverses_id INTEGER REFERENCES journal_entries (id);
+
+	-- an entry can only be reversed once
+	CREATE UNIQUE INDEX journal_entries_reverses_id ON journal_entries (reverses_id);
+	`,
+	`
+	ALTER TABLE journal_entries ADD COLUMN pending INTEGER NOT NULL DEFAULT 0;
+	`,
+	`
+	ALTER TABLE journal_entries ADD COLUMN counterparty TEXT NOT NULL DEFAULT '';
+
+	CREATE TABLE counterparties (
+		id         INTEGER PRIMARY KEY AUTOINCREMENT,
+		name       TEXT    NOT NULL UNIQUE COLLATE NOCASE,
+		alias      TEXT    NOT NULL DEFAULT '',
+		account_id INTEGER REFERENCES accounts (id)
+	);
+	`,
+	`
+	CREATE TABLE journal_entry_tags (
+		journal_entry_id INTEGER NOT NULL REFERENCES journal_entries (id),
+		tag              TEXT    NOT NULL,
+		PRIMARY KEY (journal_entry_id, tag)
+	);
+	`,
+	`
+	ALTER TABLE journal_entries ADD COLUMN replaced_by_id INTEGER REFERENCES journal_entries (id);
+	`,
+}
+
+func Migrate(ctx context.Context, db *sql.DB) error {
+	var version int
+	if err := db.QueryRowContext(ctx, "PRAGMA user_version").Scan(&version); err != nil {
+		return fmt.Errorf("error reading schema version: %+v", err)
+	}
+
+	if version > len(migrations) {
+		return fmt.Errorf("database schema version %d is newer than this program supports (%d)", version, len(migrations))
+	}
+
+	for ; version < len(migrations); version++ {
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("error starting migration %d: %+v", version+1, err)
+		}
+
+		if _, err := tx.ExecContext(ctx, migrations[version]); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("error applying migration %d: %+v", version+1, err)
+		}
+
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("PRAGMA user_version = %d", version+1)); err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("error recording migration %d: %+v", version+1, err)
+		}
+
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("error committing migration %d: %+v", version+1, err)
+		}
+	}
+
+	return nil
+}